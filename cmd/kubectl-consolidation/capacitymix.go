@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/catalog"
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/output"
+	"github.com/ssoriche/kubectl-consolidation/internal/scheduling"
+)
+
+func newCapacityMixCmd(opts *options) *cobra.Command {
+	var catalogFile string
+
+	cmd := &cobra.Command{
+		Use:   "capacity-mix",
+		Short: "Show each NodePool's spot, on-demand and reserved nodes",
+		Long: `Shows, per NodePool, how many spot, on-demand and reserved nodes there are
+and how much CPU and memory their pods request.
+
+PROTECTED-ON-DEMAND counts on-demand nodes with the on-demand-protection
+blocker, which Karpenter has reported it will not consolidate.
+
+SPOT-TOLERANT-ON-DEMAND counts the other on-demand nodes whose pods would all
+still match their nodeSelector and required node affinity on a spot node, so
+they could run on spot if the NodePool allowed it. Taints on spot nodes are not
+considered.
+
+SPOT-TO-SPOT shows whether Karpenter can replace the pool's spot nodes with
+cheaper spot nodes, which needs the pool to allow at least 15 instance types.
+The count comes from an instance-type requirement on the pool or, with
+--catalog, from the catalog's spot instance types the pool allows. Karpenter
+also needs its SpotToSpotConsolidation feature gate enabled, which cannot be
+seen from the cluster.`,
+		Example: `  # Show the capacity-type mix of every NodePool
+  kubectl consolidation capacity-mix
+
+  # Count the spot instance types each pool allows from a catalog
+  kubectl consolidation capacity-mix --catalog instance-types.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCapacityMix(cmd.Context(), catalogFile, *opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&catalogFile, "catalog", "", "Instance-type catalog file for counting the spot instance types each NodePool allows")
+
+	return cmd
+}
+
+func runCapacityMix(ctx context.Context, catalogFile string, opts options) error {
+	var cat *catalog.Catalog
+	if catalogFile != "" {
+		var err error
+		if cat, err = catalog.Load(catalogFile); err != nil {
+			return fmt.Errorf("failed to load catalog: %w", err)
+		}
+	}
+
+	collector, capabilities, err := newCollector(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := collector.CollectSnapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect cluster state: %w", err)
+	}
+
+	cluster := scheduling.NewClusterFromSnapshot(snapshot)
+	if cat != nil {
+		cluster.SetCatalog(cat, snapshot.NodePools)
+	}
+	states := make(map[string]*consolidation.BlockerState, len(snapshot.Nodes))
+	for _, node := range snapshot.Nodes {
+		states[node.Name] = collector.BlockerState(snapshot, node.Name)
+	}
+	printer := output.NewPrinter(capabilities, opts.output, opts.noHeaders)
+	return printer.PrintCapacityMix(cluster.CapacityMix(snapshot.NodePools, states))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/output"
+)
+
+func newCostCmd(opts *options) *cobra.Command {
+	var selector string
+
+	cmd := &cobra.Command{
+		Use:   "cost [NODE...]",
+		Short: "Attribute the cost of blocked nodes to blockers, namespaces and workloads",
+		Long: `Sums the hourly cost of nodes that cannot be consolidated by blocker type,
+by the namespace of the blocking pods and by their owning workload, for
+chargeback of capacity held by do-not-disrupt annotations, exhausted
+PodDisruptionBudgets and other blockers.
+
+A node's cost is split evenly across its blockers, and each blocker's part is
+split evenly across the pods responsible for it. Blockers no pod is responsible
+for, such as high-utilization or a node annotation, are reported separately.
+
+Requires --pricing-file or --opencost-url.`,
+		Example: `  # Cost of every blocked node
+  kubectl consolidation cost --pricing-file prices.yaml
+
+  # Cost of every blocked node using OpenCost's node costs
+  kubectl consolidation cost --opencost-url http://localhost:9003
+
+  # Cost of blocked spot nodes as JSON
+  kubectl consolidation cost --pricing-file prices.yaml -l karpenter.sh/capacity-type=spot -o json`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCost(cmd.Context(), args, selector, *opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Label selector for nodes")
+
+	return cmd
+}
+
+func runCost(ctx context.Context, args []string, selector string, opts options) error {
+	if opts.pricingFile == "" && opts.openCostURL == "" {
+		return fmt.Errorf("cost requires --pricing-file or --opencost-url")
+	}
+
+	collector, capabilities, err := newCollector(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err := usePricer(ctx, collector, opts); err != nil {
+		return err
+	}
+
+	snapshot, err := collector.CollectSnapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect cluster state: %w", err)
+	}
+
+	selected, err := snapshot.SelectNodes(args, selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+
+	printer := output.NewPrinter(capabilities, opts.output, opts.noHeaders)
+	return printer.PrintBlockerCost(collector.BlockerCost(snapshot, selected))
+}
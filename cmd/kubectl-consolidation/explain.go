@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/output"
+)
+
+func newExplainCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain NODE",
+		Short: "Walk every Karpenter consolidation check for a node",
+		Long: `Walks every check Karpenter performs before consolidating a node and
+prints pass/fail/unknown for each, with the evidence behind the result.
+
+Checks cover NodeClaim initialization, NodePool consolidation policy,
+consolidateAfter, disruption budgets, node annotations, pod-level blockers,
+PodDisruptionBudgets, utilization and consolidation events.`,
+		Example: `  # Explain why a node is or is not being consolidated
+  kubectl consolidation explain node-1
+
+  # Machine-readable checklist
+  kubectl consolidation explain node-1 -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExplain(cmd.Context(), args[0], *opts)
+		},
+	}
+}
+
+func runExplain(ctx context.Context, nodeName string, opts options) error {
+	collector, capabilities, err := newCollector(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	expl, err := collector.CollectExplanation(ctx, nodeName)
+	if err != nil {
+		return fmt.Errorf("failed to explain node %s: %w", nodeName, err)
+	}
+
+	printer := output.NewPrinter(capabilities, opts.output, opts.noHeaders)
+	return printer.PrintExplanation(expl)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/output"
+)
+
+func newFragmentationCmd(opts *options) *cobra.Command {
+	var selector string
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "fragmentation",
+		Short: "Estimate the minimum node count and how fragmented the cluster is",
+		Long: `Estimates, per NodePool and instance shape, the fewest nodes needed to hold
+all current pod requests and compares that with the actual node count.
+
+Two estimates are shown: a lower bound from the total requests of each resource
+divided by one node's capacity, and a first-fit-decreasing packing that is
+usually achievable. The fragmentation ratio is the share of nodes the packing
+would not need. Only requests are considered, not affinity, topology spread or
+other scheduling constraints, so the estimates are optimistic.
+
+The nodes with the most unrequested capacity are listed as the biggest
+contributors.`,
+		Example: `  # Show the fragmentation report
+  kubectl consolidation fragmentation
+
+  # Limit to one NodePool and list the 20 emptiest nodes
+  kubectl consolidation fragmentation -l karpenter.sh/nodepool=default --top 20`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFragmentation(cmd.Context(), selector, top, *opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Label selector for nodes")
+	cmd.Flags().IntVar(&top, "top", 10, "Number of most fragmented nodes to list")
+
+	return cmd
+}
+
+func runFragmentation(ctx context.Context, selector string, top int, opts options) error {
+	if top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	collector, capabilities, err := newCollector(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	report, err := collector.CollectFragmentation(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("failed to collect cluster state: %w", err)
+	}
+	if len(report.Contributors) > top {
+		report.Contributors = report.Contributors[:top]
+	}
+
+	printer := output.NewPrinter(capabilities, opts.output, opts.noHeaders)
+	return printer.PrintFragmentation(report)
+}
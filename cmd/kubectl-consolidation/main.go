@@ -3,14 +3,20 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
 	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
 	"github.com/ssoriche/kubectl-consolidation/internal/kube"
 	"github.com/ssoriche/kubectl-consolidation/internal/output"
+	"github.com/ssoriche/kubectl-consolidation/internal/pricing"
 )
 
 var version = "dev"
@@ -33,7 +39,11 @@ nodepool/provisioner, capacity type, CPU/memory utilization, and reasons
 why nodes cannot be consolidated.
 
 Automatically detects Karpenter API version (v1alpha5, v1beta1, v1) and
-adapts output accordingly. Supports mixed-version clusters during migrations.`,
+adapts output accordingly. Supports mixed-version clusters during migrations.
+
+With --nodepool-file, evaluates the selected nodes as if the NodePool manifests
+in the file were live and shows a before/after diff of blockers, eligibility,
+requirement drift, expiry and limits per node.`,
 		Example: `  # Show all nodes with consolidation information
   kubectl consolidation
 
@@ -44,9 +54,46 @@ adapts output accordingly. Supports mixed-version clusters during migrations.`,
   kubectl consolidation -l karpenter.sh/capacity-type=spot
 
   # Show detailed pod blockers for a node
-  kubectl consolidation --pods node-1`,
+  kubectl consolidation --pods node-1
+
+  # Raise the CPU threshold and use per-NodePool rules from a config file
+  kubectl consolidation --threshold cpu=90 --config thresholds.yaml
+
+  # Show each node's hourly cost and the share of it no pod requests
+  kubectl consolidation --pricing-file prices.yaml
+
+  # Read node costs from OpenCost instead of a price list
+  kubectl consolidation --opencost-url http://localhost:9003
+
+  # Show GPU and pod-slot utilization columns
+  kubectl consolidation --resources cpu,memory,nvidia.com/gpu,pods
+
+  # Review a NodePool change against the live cluster before merging it
+  kubectl consolidation --nodepool-file nodepool.yaml
+
+  # Explain every consolidation check for a node
+  kubectl consolidation explain node-1
+
+  # Check whether each node's pods would fit on the other nodes
+  kubectl consolidation simulate
+
+  # Generate a PDB-aware drain script for manual consolidation
+  kubectl consolidation plan -o script
+
+  # Estimate the minimum node count and fragmentation
+  kubectl consolidation fragmentation
+
+  # Attribute the cost of blocked nodes to namespaces and workloads
+  kubectl consolidation cost --pricing-file prices.yaml
+
+  # Show each NodePool's spot, on-demand and reserved nodes
+  kubectl consolidation capacity-mix
+
+  # Write an HTML consolidation report
+  kubectl consolidation report -o html > report.html`,
 		Version:      version,
 		SilenceUsage: true,
+		Args:         cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return run(cmd.Context(), args, opts)
 		},
@@ -54,17 +101,45 @@ adapts output accordingly. Supports mixed-version clusters during migrations.`,
 
 	cmd.Flags().BoolVar(&opts.pods, "pods", false, "Show detailed pod-level blockers (requires node names)")
 	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "Label selector for nodes")
-	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output format (json, yaml)")
-	cmd.Flags().BoolVar(&opts.noHeaders, "no-headers", false, "Don't print headers")
+	cmd.Flags().StringVar(&opts.nodePoolFile, "nodepool-file", "", "Compare each node's blockers and eligibility under its live NodePool and the NodePool manifests in this file")
+	cmd.Flags().StringVar(&opts.sortBy, "sort-by", "", "Sort nodes by cpu, memory, age, pool, blocker-count, cost, empty-duration or name, and --pods by node, namespace, age, reason or name; or by a JSONPath such as .zone")
+	cmd.Flags().StringVar(&opts.sortOrder, "sort-order", "ascending", "Sort order for --sort-by: ascending or descending")
+	cmd.Flags().StringVar(&opts.listSeparator, "list-separator", output.DefaultListSeparator, "Separator joining blockers and other lists in csv and tsv output")
+	cmd.Flags().StringSliceVar(&opts.resources, "resources", nil, "Resources to show utilization columns for (e.g. cpu,memory,nvidia.com/gpu,ephemeral-storage,pods)")
+	cmd.PersistentFlags().StringVarP(&opts.output, "output", "o", "", "Output format (json, yaml, wide, csv, tsv, custom-columns=, custom-columns-file=, jsonpath=, jsonpath-file=, go-template=, go-template-file=; subcommands support json and yaml, plan also script, report also markdown and html)")
+	cmd.PersistentFlags().BoolVar(&opts.noHeaders, "no-headers", false, "Don't print headers")
+	cmd.PersistentFlags().StringToIntVar(&opts.thresholds, "threshold", nil, "High-utilization threshold percent per resource, e.g. cpu=90,memory=85,default=80")
+	cmd.PersistentFlags().StringVar(&opts.configFile, "config", "", "Config file with cluster-wide and per-NodePool utilization thresholds")
+	cmd.PersistentFlags().StringVar(&opts.pricingFile, "pricing-file", "", "Price list of hourly node prices by instance type, capacity type and zone")
+	cmd.PersistentFlags().StringVar(&opts.openCostURL, "opencost-url", "", "OpenCost or Kubecost API to read hourly node costs from (e.g. http://localhost:9003)")
+
+	cmd.AddCommand(newExplainCmd(&opts))
+	cmd.AddCommand(newSimulateCmd(&opts))
+	cmd.AddCommand(newPlanCmd(&opts))
+	cmd.AddCommand(newFragmentationCmd(&opts))
+	cmd.AddCommand(newCostCmd(&opts))
+	cmd.AddCommand(newCapacityMixCmd(&opts))
+	cmd.AddCommand(newReportCmd(&opts))
 
 	return cmd
 }
 
 type options struct {
-	pods      bool
-	selector  string
-	output    string
-	noHeaders bool
+	pods       bool
+	selector   string
+	output     string
+	noHeaders  bool
+	resources  []string
+	thresholds map[string]int
+	configFile string
+	// nodePoolFile holds proposed NodePool manifests to compare against the live ones
+	nodePoolFile string
+	pricingFile  string
+	openCostURL  string
+	sortBy       string
+	sortOrder    string
+	// listSeparator joins lists in csv and tsv output
+	listSeparator string
 }
 
 func run(ctx context.Context, args []string, opts options) error {
@@ -72,29 +147,35 @@ func run(ctx context.Context, args []string, opts options) error {
 	if opts.pods && len(args) == 0 {
 		return fmt.Errorf("--pods flag requires at least one node name")
 	}
-
-	// Create Kubernetes client
-	client, err := kube.NewClient()
+	if opts.pods && opts.nodePoolFile != "" {
+		return fmt.Errorf("--pods and --nodepool-file cannot be combined")
+	}
+	descending, err := parseSort(opts)
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		return err
 	}
 
-	// Create discovery client for CRD detection
-	discoveryClient, err := kube.NewDiscoveryClient()
-	if err != nil {
-		return fmt.Errorf("failed to create discovery client: %w", err)
+	var proposed []*karpenter.NodePool
+	if opts.nodePoolFile != "" {
+		pools, err := karpenter.LoadNodePools(opts.nodePoolFile)
+		if err != nil {
+			return fmt.Errorf("failed to load NodePools: %w", err)
+		}
+		proposed = pools
 	}
 
-	// Detect Karpenter capabilities
-	capabilities, err := karpenter.DetectCapabilities(ctx, discoveryClient)
+	collector, capabilities, err := newCollector(ctx, opts)
 	if err != nil {
-		// Non-fatal: continue with empty capabilities
-		capabilities = &karpenter.ClusterCapabilities{}
+		return err
 	}
 
-	// Create collector and printer
-	collector := consolidation.NewCollector(client, capabilities)
+	// Create printer
 	printer := output.NewPrinter(capabilities, opts.output, opts.noHeaders)
+	printer.SetResources(parseResources(opts.resources))
+	printer.SetListSeparator(opts.listSeparator)
+	if err := printer.SetSort(opts.sortBy, descending); err != nil {
+		return err
+	}
 
 	// Handle --pods mode
 	if opts.pods {
@@ -105,7 +186,14 @@ func run(ctx context.Context, args []string, opts options) error {
 		return printer.PrintPodBlockers(blockers)
 	}
 
+	if proposed != nil {
+		return runNodePoolDiff(ctx, collector, printer, args, opts.selector, proposed)
+	}
+
 	// Default: show node table
+	if err := usePricer(ctx, collector, opts); err != nil {
+		return err
+	}
 	nodes, err := collector.Collect(ctx, args, opts.selector)
 	if err != nil {
 		return fmt.Errorf("failed to collect node information: %w", err)
@@ -113,3 +201,173 @@ func run(ctx context.Context, args []string, opts options) error {
 
 	return printer.PrintNodes(nodes)
 }
+
+// runNodePoolDiff evaluates the selected nodes of the proposed pools under
+// their live NodePool and the proposed spec
+func runNodePoolDiff(ctx context.Context, collector *consolidation.Collector, printer *output.Printer, args []string, selector string, proposed []*karpenter.NodePool) error {
+	snapshot, err := collector.CollectSnapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect cluster state: %w", err)
+	}
+	candidates, err := snapshot.SelectNodes(args, selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+
+	now := time.Now()
+	diffs := collector.DiffNodePools(snapshot, proposed, candidates, now)
+	if len(diffs) == 0 {
+		names := make([]string, len(proposed))
+		for i, pool := range proposed {
+			names[i] = pool.Name
+		}
+		return fmt.Errorf("no selected nodes belong to %s", strings.Join(names, ", "))
+	}
+	return printer.PrintNodePoolDiff(diffs, now)
+}
+
+// newCollector creates the Kubernetes clients, detects Karpenter capabilities
+// and returns a Collector configured from opts
+func newCollector(ctx context.Context, opts options) (*consolidation.Collector, *karpenter.ClusterCapabilities, error) {
+	thresholds, err := loadThresholds(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create Kubernetes client
+	client, err := kube.NewClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	// Create discovery client for CRD detection
+	discoveryClient, err := kube.NewDiscoveryClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	// Detect Karpenter capabilities
+	capabilities, err := karpenter.DetectCapabilities(ctx, discoveryClient)
+	if err != nil {
+		// Non-fatal: continue with empty capabilities
+		capabilities = &karpenter.ClusterCapabilities{}
+	}
+
+	// Dynamic client for Karpenter custom resources
+	dynamicClient, err := kube.NewDynamicClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	collector := consolidation.NewCollector(client, dynamicClient, capabilities)
+	collector.SetThresholds(thresholds)
+	collector.SetWarningOutput(os.Stderr)
+
+	return collector, capabilities, nil
+}
+
+// openCostTimeout bounds the request for node costs
+const openCostTimeout = 30 * time.Second
+
+// usePricer prices the collector's nodes from --pricing-file or --opencost-url.
+// Only commands that report cost call it, so an unreachable OpenCost does not
+// break the others.
+func usePricer(ctx context.Context, collector *consolidation.Collector, opts options) error {
+	pricer, err := loadPricer(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if pricer != nil {
+		collector.SetPricer(pricer)
+	}
+	return nil
+}
+
+// loadPricer reads node prices from --pricing-file or --opencost-url. It
+// returns nil when neither is set.
+func loadPricer(ctx context.Context, opts options) (consolidation.NodePricer, error) {
+	switch {
+	case opts.pricingFile != "" && opts.openCostURL != "":
+		return nil, fmt.Errorf("--pricing-file and --opencost-url cannot be combined")
+	case opts.pricingFile != "":
+		prices, err := pricing.Load(opts.pricingFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load pricing: %w", err)
+		}
+		return prices, nil
+	case opts.openCostURL != "":
+		costs, err := pricing.FetchOpenCost(ctx, &http.Client{Timeout: openCostTimeout}, opts.openCostURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OpenCost node costs: %w", err)
+		}
+		return costs, nil
+	default:
+		return nil, nil
+	}
+}
+
+// loadThresholds merges --config and --threshold into a ThresholdConfig
+func loadThresholds(opts options) (*consolidation.ThresholdConfig, error) {
+	cfg := &consolidation.ThresholdConfig{}
+	if opts.configFile != "" {
+		loaded, err := consolidation.LoadThresholdConfig(opts.configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg = loaded
+	}
+
+	if err := consolidation.ValidateThresholds(opts.thresholds); err != nil {
+		return nil, fmt.Errorf("invalid --threshold: %w", err)
+	}
+	cfg.Flags = opts.thresholds
+	cfg.SetWarningOutput(os.Stderr)
+
+	return cfg, nil
+}
+
+// resourceAliases maps short names accepted by --resources to resource names
+var resourceAliases = map[string]corev1.ResourceName{
+	"mem": corev1.ResourceMemory,
+	"gpu": "nvidia.com/gpu",
+}
+
+func parseResources(names []string) []corev1.ResourceName {
+	resources := make([]corev1.ResourceName, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if alias, ok := resourceAliases[name]; ok {
+			resources = append(resources, alias)
+			continue
+		}
+		resources = append(resources, corev1.ResourceName(name))
+	}
+	return resources
+}
+
+// parseSort checks --sort-by and --sort-order and reports whether the order is
+// descending
+func parseSort(opts options) (bool, error) {
+	var descending bool
+	switch opts.sortOrder {
+	case "ascending", "asc":
+	case "descending", "desc":
+		descending = true
+	default:
+		return false, fmt.Errorf("--sort-order must be ascending or descending, got %q", opts.sortOrder)
+	}
+	if opts.sortBy == "" || output.IsJSONPathSort(opts.sortBy) {
+		return descending, nil
+	}
+	fields := consolidation.NodeSortFields()
+	if opts.pods {
+		fields = consolidation.PodBlockerSortFields()
+	}
+	if !slices.Contains(fields, opts.sortBy) {
+		return false, fmt.Errorf("unknown --sort-by field %q, want one of %s or a JSONPath", opts.sortBy, strings.Join(fields, ", "))
+	}
+	return descending, nil
+}
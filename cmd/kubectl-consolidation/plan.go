@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/output"
+	"github.com/ssoriche/kubectl-consolidation/internal/scheduling"
+)
+
+func newPlanCmd(opts *options) *cobra.Command {
+	var selector string
+
+	cmd := &cobra.Command{
+		Use:   "plan [NODE...]",
+		Short: "Generate a PDB-aware drain sequence for manual consolidation",
+		Long: `Orders nodes into waves of drains that respect every PodDisruptionBudget's
+current disruptionsAllowed, for consolidating by hand during a maintenance
+window.
+
+Every node in the plan is cordoned first so displaced pods only land on nodes
+that stay, and the nodes are checked to fit all of their pods there. Nodes whose
+pods share a PDB are put in different waves, and each wave waits for the
+previous wave's PDBs to recover. Nodes that would evict more pods covered by a
+PDB than it currently allows are left out.
+
+Without node names, every Karpenter-managed node with no consolidation
+blockers is a candidate. Named nodes with blockers are reported as skipped.
+
+Use -o script for a runnable shell script of kubectl commands.`,
+		Example: `  # Plan draining every unblocked node
+  kubectl consolidation plan
+
+  # Plan specific nodes and write a script
+  kubectl consolidation plan node-1 node-2 node-3 -o script > drain.sh`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlan(cmd.Context(), args, selector, *opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Label selector for candidate nodes")
+
+	return cmd
+}
+
+func runPlan(ctx context.Context, args []string, selector string, opts options) error {
+	collector, capabilities, err := newCollector(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := collector.CollectSnapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect cluster state: %w", err)
+	}
+
+	selected, err := snapshot.SelectNodes(args, selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+
+	// Only unblocked nodes are candidates; blocked named nodes are reported as skipped
+	var candidates []string
+	var blocked []scheduling.SkippedNode
+	for _, name := range selected {
+		state := collector.BlockerState(snapshot, name)
+		if state == nil {
+			continue
+		}
+		if blockers := state.Blockers(); len(blockers) > 0 {
+			if len(args) > 0 {
+				blocked = append(blocked, scheduling.SkippedNode{
+					Node:   name,
+					Reason: "blocked by " + consolidation.FormatBlockers(blockers),
+				})
+			}
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+
+	plan := scheduling.NewClusterFromSnapshot(snapshot).PlanDrain(candidates, snapshot.PDBs)
+	plan.Skipped = append(blocked, plan.Skipped...)
+	printer := output.NewPrinter(capabilities, opts.output, opts.noHeaders)
+	return printer.PrintDrainPlan(plan)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/output"
+)
+
+func newReportCmd(opts *options) *cobra.Command {
+	var (
+		selector     string
+		top          int
+		snapshotFile string
+		saveSnapshot string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "report [NODE...]",
+		Short: "Write a Markdown or HTML consolidation report",
+		Long: `Writes a standalone consolidation report with cluster totals, a breakdown
+per NodePool, blocker counts, the workloads whose pods block the most nodes,
+CPU and memory utilization histograms and the full node table.
+
+The report is Markdown by default; -o html writes a self-contained HTML page,
+and -o json or -o yaml the same data for further processing.
+
+--save-snapshot also writes the cluster state the report was built from, and
+--snapshot builds the report from such a file instead of the cluster, so a
+report can be regenerated or reformatted later. Actual usage from
+metrics-server is not part of the report.`,
+		Example: `  # Markdown report of every Karpenter node
+  kubectl consolidation report > report.md
+
+  # HTML report of one NodePool, with costs
+  kubectl consolidation report -l karpenter.sh/nodepool=default --pricing-file prices.yaml -o html > report.html
+
+  # Keep the cluster state, then render it again as HTML
+  kubectl consolidation report --save-snapshot state.json > report.md
+  kubectl consolidation report --snapshot state.json -o html > report.html`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReport(cmd.Context(), args, selector, top, snapshotFile, saveSnapshot, *opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Label selector for nodes")
+	cmd.Flags().IntVar(&top, "top", 10, "Number of blocking workloads to list")
+	cmd.Flags().StringVar(&snapshotFile, "snapshot", "", "Build the report from a snapshot saved with --save-snapshot instead of the cluster")
+	cmd.Flags().StringVar(&saveSnapshot, "save-snapshot", "", "Also save the cluster state to this file")
+
+	return cmd
+}
+
+func runReport(ctx context.Context, args []string, selector string, top int, snapshotFile, saveSnapshot string, opts options) error {
+	if top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+	if snapshotFile != "" && saveSnapshot != "" {
+		return fmt.Errorf("--snapshot and --save-snapshot cannot be combined")
+	}
+
+	var collector *consolidation.Collector
+	var snapshot *consolidation.ClusterSnapshot
+	if snapshotFile != "" {
+		var err error
+		if snapshot, err = consolidation.LoadSnapshot(snapshotFile); err != nil {
+			return fmt.Errorf("failed to load snapshot: %w", err)
+		}
+		if collector, err = newSnapshotCollector(snapshot, opts); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if collector, _, err = newCollector(ctx, opts); err != nil {
+			return err
+		}
+		if snapshot, err = collector.CollectSnapshot(ctx); err != nil {
+			return fmt.Errorf("failed to collect cluster state: %w", err)
+		}
+		if saveSnapshot != "" {
+			if err := snapshot.Save(saveSnapshot); err != nil {
+				return fmt.Errorf("failed to save snapshot: %w", err)
+			}
+		}
+	}
+
+	if err := usePricer(ctx, collector, opts); err != nil {
+		return err
+	}
+
+	selected, err := snapshot.SelectNodes(args, selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+	report, err := collector.Report(snapshot, selected)
+	if err != nil {
+		return fmt.Errorf("failed to build report: %w", err)
+	}
+	if len(report.Workloads) > top {
+		report.Workloads = report.Workloads[:top]
+	}
+
+	printer := output.NewPrinter(snapshot.Capabilities, opts.output, opts.noHeaders)
+	return printer.PrintReport(report)
+}
+
+// newSnapshotCollector creates a collector for a saved snapshot, with the
+// thresholds from the flags but no cluster connection
+func newSnapshotCollector(snapshot *consolidation.ClusterSnapshot, opts options) (*consolidation.Collector, error) {
+	thresholds, err := loadThresholds(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	collector := consolidation.NewCollector(nil, nil, snapshot.Capabilities)
+	collector.SetThresholds(thresholds)
+	return collector, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/catalog"
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/output"
+	"github.com/ssoriche/kubectl-consolidation/internal/scheduling"
+)
+
+func newSimulateCmd(opts *options) *cobra.Command {
+	var selector string
+	var multi bool
+	var catalogFile string
+	var whatIf []string
+
+	cmd := &cobra.Command{
+		Use:   "simulate [NODE...]",
+		Short: "Simulate whether each node's pods fit on the remaining nodes",
+		Long: `Removes each candidate node from a copy of the cluster and tries to place
+its movable pods on the remaining nodes, the question Karpenter answers before
+consolidating a node.
+
+Placement respects resource requests, taints and tolerations, nodeSelector and
+required node affinity, pod affinity and anti-affinity, topology spread
+constraints, host ports, PersistentVolume node affinity and CSI volume attach
+limits. DaemonSet and static pods are not moved.
+
+Each node is reported as deletable when every pod fits elsewhere, with the
+reason each unplaced pod did not fit otherwise. Whether the pods left over fit
+on a cheaper replacement depends on instance types and prices, so without
+--catalog such a node is reported as replacement-unknown.
+
+With --catalog, a node is replaceable when the pods left over fit on a single
+instance type from the catalog file that is cheaper than the node's current
+instance type, within the requirements of the node's NodePool, and
+not-consolidatable when none does. The cheapest instance type that fits is
+reported either way, which explains would-increase-cost consolidation events.
+
+With --what-if-remove, reports which nodes would become consolidatable if a
+blocker were removed, and the CPU and memory that consolidating them would
+free. Blockers are the same as in the node table, and nodes that would need a
+replacement only count with --catalog. The value is <blocker>[=<scope>], where scope limits the removal to a pod
+(namespace/name), a namespace (namespace/*) or pods matching a label selector.
+For pdb-violation, namespace/name may also name the PDB to ignore. The flag
+can be repeated to remove several blockers at once.
+
+Without node names or a selector, every Karpenter-managed node is simulated.
+
+With --multi-node, searches for the largest set of candidates that can be
+removed together while every displaced pod still fits on the surviving
+nodes, and reports the set, the pods that would move and the resulting
+utilization of every surviving node.`,
+		Example: `  # Simulate removing every Karpenter-managed node, one at a time
+  kubectl consolidation simulate
+
+  # Simulate specific nodes
+  kubectl consolidation simulate node-1 node-2
+
+  # Simulate spot nodes and show where each pod would go
+  kubectl consolidation simulate -l karpenter.sh/capacity-type=spot -o yaml
+
+  # Estimate how many nodes the cluster could shed at once
+  kubectl consolidation simulate --multi-node
+
+  # Look for cheaper replacement instance types
+  kubectl consolidation simulate --catalog instance-types.yaml
+
+  # What if the payments team dropped its do-not-disrupt annotations?
+  kubectl consolidation simulate --what-if-remove 'do-not-disrupt=payments/*'
+
+  # What if one PDB were ignored?
+  kubectl consolidation simulate --what-if-remove pdb-violation=payments/ledger-pdb`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSimulate(cmd.Context(), args, selector, multi, catalogFile, whatIf, *opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Label selector for candidate nodes")
+	cmd.Flags().BoolVar(&multi, "multi-node", false, "Search for the largest set of candidates that can be removed together")
+	cmd.Flags().StringVar(&catalogFile, "catalog", "", "Instance-type catalog file for simulating replacement with a cheaper instance type")
+	cmd.Flags().StringArrayVar(&whatIf, "what-if-remove", nil, "Report what removing a blocker would unblock, as <blocker>[=<namespace/name|namespace/*|selector>] (repeatable)")
+
+	return cmd
+}
+
+func runSimulate(ctx context.Context, args []string, selector string, multi bool, catalogFile string, whatIf []string, opts options) error {
+	if multi && len(whatIf) > 0 {
+		return fmt.Errorf("--multi-node and --what-if-remove cannot be combined")
+	}
+	removals := make([]consolidation.BlockerRemoval, 0, len(whatIf))
+	for _, value := range whatIf {
+		removal, err := consolidation.ParseBlockerRemoval(value)
+		if err != nil {
+			return fmt.Errorf("invalid --what-if-remove: %w", err)
+		}
+		removals = append(removals, removal)
+	}
+
+	var cat *catalog.Catalog
+	if catalogFile != "" {
+		var err error
+		if cat, err = catalog.Load(catalogFile); err != nil {
+			return fmt.Errorf("failed to load catalog: %w", err)
+		}
+	}
+
+	collector, capabilities, err := newCollector(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := collector.CollectSnapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect cluster state: %w", err)
+	}
+
+	candidates, err := snapshot.SelectNodes(args, selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+
+	cluster := scheduling.NewClusterFromSnapshot(snapshot)
+	if cat != nil {
+		cluster.SetCatalog(cat, snapshot.NodePools)
+	}
+	printer := output.NewPrinter(capabilities, opts.output, opts.noHeaders)
+
+	if len(removals) > 0 {
+		states := make(map[string]*consolidation.BlockerState, len(candidates))
+		for _, name := range candidates {
+			states[name] = collector.BlockerState(snapshot, name)
+		}
+		return printer.PrintWhatIf(removals, cluster.WhatIf(candidates, states, removals))
+	}
+	if multi {
+		return printer.PrintMultiNodeSearch(cluster.SearchRemovable(candidates))
+	}
+	return printer.PrintSimulation(cluster.Simulate(candidates))
+}
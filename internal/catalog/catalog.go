@@ -0,0 +1,177 @@
+// Package catalog reads an offline instance-type catalog used to simulate
+// Karpenter's replace consolidation.
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourceGPU is the resource name catalog GPU counts are reported as
+const ResourceGPU corev1.ResourceName = "nvidia.com/gpu"
+
+// InstanceType describes the capacity and prices of one instance type
+type InstanceType struct {
+	Name         string
+	Architecture string
+	// Capacity is what the instance type offers, before the kubelet holds back
+	// kube-reserved, system-reserved and eviction thresholds
+	Capacity corev1.ResourceList
+	// Labels are extra node labels the instance type carries, e.g.
+	// karpenter.k8s.aws/instance-family, for matching NodePool requirements
+	Labels map[string]string
+	// Prices is the hourly price by capacity type (on-demand, spot, ...)
+	Prices map[string]float64
+}
+
+// NodeLabels returns the well-known labels a node of this type would carry
+func (it *InstanceType) NodeLabels() map[string]string {
+	labels := make(map[string]string, len(it.Labels)+2)
+	for key, value := range it.Labels {
+		labels[key] = value
+	}
+	labels[corev1.LabelInstanceTypeStable] = it.Name
+	if it.Architecture != "" {
+		labels[corev1.LabelArchStable] = it.Architecture
+	}
+	return labels
+}
+
+// Offering is an instance type available at a price for one capacity type
+type Offering struct {
+	InstanceType *InstanceType
+	CapacityType string
+	Price        float64
+}
+
+// Catalog is a set of instance types keyed by name
+type Catalog struct {
+	instanceTypes map[string]*InstanceType
+}
+
+// Get returns the named instance type
+func (c *Catalog) Get(name string) (*InstanceType, bool) {
+	it, ok := c.instanceTypes[name]
+	return it, ok
+}
+
+// Price returns the hourly price of an instance type for a capacity type
+func (c *Catalog) Price(instanceType, capacityType string) (float64, bool) {
+	it, ok := c.instanceTypes[instanceType]
+	if !ok {
+		return 0, false
+	}
+	price, ok := it.Prices[capacityType]
+	return price, ok
+}
+
+// Offerings returns every instance type and capacity type pair, cheapest first
+func (c *Catalog) Offerings() []Offering {
+	var offerings []Offering
+	for _, it := range c.instanceTypes {
+		for capacityType, price := range it.Prices {
+			offerings = append(offerings, Offering{InstanceType: it, CapacityType: capacityType, Price: price})
+		}
+	}
+	sort.Slice(offerings, func(i, j int) bool {
+		if offerings[i].Price != offerings[j].Price {
+			return offerings[i].Price < offerings[j].Price
+		}
+		if offerings[i].InstanceType.Name != offerings[j].InstanceType.Name {
+			return offerings[i].InstanceType.Name < offerings[j].InstanceType.Name
+		}
+		return offerings[i].CapacityType < offerings[j].CapacityType
+	})
+	return offerings
+}
+
+// catalogFile is the on-disk format read by Load:
+//
+//	instanceTypes:
+//	  m5.large:
+//	    cpu: "2"
+//	    memory: 8Gi
+//	    pods: 29
+//	    architecture: amd64
+//	    labels:
+//	      karpenter.k8s.aws/instance-family: m5
+//	    prices:
+//	      on-demand: 0.096
+//	      spot: 0.035
+//	  g5.xlarge:
+//	    cpu: "4"
+//	    memory: 16Gi
+//	    gpu: 1
+//	    prices:
+//	      on-demand: 1.006
+type catalogFile struct {
+	InstanceTypes map[string]struct {
+		CPU          string             `yaml:"cpu"`
+		Memory       string             `yaml:"memory"`
+		GPU          int64              `yaml:"gpu"`
+		Pods         int64              `yaml:"pods"`
+		Architecture string             `yaml:"architecture"`
+		Labels       map[string]string  `yaml:"labels"`
+		Prices       map[string]float64 `yaml:"prices"`
+	} `yaml:"instanceTypes"`
+}
+
+// Load reads a catalog from a YAML or JSON file
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cat, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cat, nil
+}
+
+// Parse decodes a catalog from YAML or JSON
+func Parse(data []byte) (*Catalog, error) {
+	var file catalogFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog: %w", err)
+	}
+
+	cat := &Catalog{instanceTypes: make(map[string]*InstanceType, len(file.InstanceTypes))}
+	for name, raw := range file.InstanceTypes {
+		it := &InstanceType{
+			Name:         name,
+			Architecture: raw.Architecture,
+			Capacity:     corev1.ResourceList{},
+			Labels:       raw.Labels,
+			Prices:       raw.Prices,
+		}
+		for resourceName, value := range map[corev1.ResourceName]string{corev1.ResourceCPU: raw.CPU, corev1.ResourceMemory: raw.Memory} {
+			if value == "" {
+				return nil, fmt.Errorf("instance type %s: %s is required", name, resourceName)
+			}
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("instance type %s: invalid %s %q: %w", name, resourceName, value, err)
+			}
+			it.Capacity[resourceName] = quantity
+		}
+		if raw.GPU > 0 {
+			it.Capacity[ResourceGPU] = *resource.NewQuantity(raw.GPU, resource.DecimalSI)
+		}
+		if raw.Pods > 0 {
+			it.Capacity[corev1.ResourcePods] = *resource.NewQuantity(raw.Pods, resource.DecimalSI)
+		}
+		for capacityType, price := range raw.Prices {
+			if price < 0 {
+				return nil, fmt.Errorf("instance type %s: negative %s price", name, capacityType)
+			}
+		}
+		cat.instanceTypes[name] = it
+	}
+	return cat, nil
+}
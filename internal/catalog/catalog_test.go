@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const testCatalog = `
+instanceTypes:
+  m5.large:
+    cpu: 2
+    memory: 8Gi
+    pods: 29
+    architecture: amd64
+    labels:
+      karpenter.k8s.aws/instance-family: m5
+    prices:
+      on-demand: 0.096
+      spot: 0.035
+  m5.xlarge:
+    cpu: "4"
+    memory: 16Gi
+    architecture: amd64
+    prices:
+      on-demand: 0.192
+  g5.xlarge:
+    cpu: "4"
+    memory: 16Gi
+    gpu: 1
+    prices:
+      on-demand: 1.006
+`
+
+func TestParse(t *testing.T) {
+	cat, err := Parse([]byte(testCatalog))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	it, ok := cat.Get("m5.large")
+	if !ok {
+		t.Fatal("m5.large missing")
+	}
+	if cpu := it.Capacity[corev1.ResourceCPU]; cpu.String() != "2" {
+		t.Errorf("cpu = %s, want 2", cpu.String())
+	}
+	if pods := it.Capacity[corev1.ResourcePods]; pods.Value() != 29 {
+		t.Errorf("pods = %d, want 29", pods.Value())
+	}
+	labels := it.NodeLabels()
+	if labels[corev1.LabelInstanceTypeStable] != "m5.large" || labels[corev1.LabelArchStable] != "amd64" ||
+		labels["karpenter.k8s.aws/instance-family"] != "m5" {
+		t.Errorf("NodeLabels() = %v", labels)
+	}
+
+	gpu, _ := cat.Get("g5.xlarge")
+	if q := gpu.Capacity[ResourceGPU]; q.Value() != 1 {
+		t.Errorf("gpu = %d, want 1", q.Value())
+	}
+
+	if price, ok := cat.Price("m5.large", "spot"); !ok || price != 0.035 {
+		t.Errorf("Price(m5.large, spot) = %v, %v", price, ok)
+	}
+	if _, ok := cat.Price("m5.xlarge", "spot"); ok {
+		t.Error("Price(m5.xlarge, spot) should be unknown")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "missing memory",
+			data:    "instanceTypes:\n  a:\n    cpu: 2\n",
+			wantErr: "memory is required",
+		},
+		{
+			name:    "invalid cpu",
+			data:    "instanceTypes:\n  a:\n    cpu: two\n    memory: 1Gi\n",
+			wantErr: "invalid cpu",
+		},
+		{
+			name:    "negative price",
+			data:    "instanceTypes:\n  a:\n    cpu: 2\n    memory: 1Gi\n    prices:\n      spot: -1\n",
+			wantErr: "negative spot price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOfferingsCheapestFirst(t *testing.T) {
+	cat, err := Parse([]byte(testCatalog))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var got []string
+	for _, o := range cat.Offerings() {
+		got = append(got, o.InstanceType.Name+"/"+o.CapacityType)
+	}
+	want := "m5.large/spot m5.large/on-demand m5.xlarge/on-demand g5.xlarge/on-demand"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Offerings() = %v, want %s", got, want)
+	}
+}
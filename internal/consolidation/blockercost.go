@@ -0,0 +1,203 @@
+package consolidation
+
+import (
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// CostShare is the hourly cost of blocked nodes attributed to one blocker type,
+// namespace or workload
+type CostShare struct {
+	Name       string
+	Nodes      int // Blocked nodes contributing to the share
+	HourlyCost float64
+}
+
+// BlockerCostReport attributes the cost of blocked nodes to what blocks them
+type BlockerCostReport struct {
+	BlockedNodes int // Blocked nodes with a price
+	Unpriced     int // Blocked nodes without a price, left out of every total
+	Reserved     int // Blocked reserved nodes, left out of every total as prepaid
+
+	HourlyCost float64 // Total cost of the priced blocked nodes
+	// Unattributed is the cost of blockers no pod is responsible for, such as
+	// high-utilization or a node annotation; it has no namespace or workload
+	Unattributed float64
+	ByBlocker    []CostShare
+	ByNamespace  []CostShare
+	ByWorkload   []CostShare // Named namespace/Kind/name
+}
+
+// PricedState is a node's blocker state and hourly price
+type PricedState struct {
+	State      *BlockerState
+	HourlyCost float64
+	HasCost    bool
+}
+
+// AttributeBlockerCost splits each blocked node's hourly cost evenly across its
+// blockers, then splits each blocker's part evenly across the pods responsible
+// for it and sums the parts by namespace and owning workload
+func AttributeBlockerCost(nodes []PricedState) BlockerCostReport {
+	var report BlockerCostReport
+	byBlocker := newCostShares()
+	byNamespace := newCostShares()
+	byWorkload := newCostShares()
+
+	for _, n := range nodes {
+		blockers := n.State.Blockers()
+		if len(blockers) == 0 {
+			continue
+		}
+		if karpenter.IsReserved(karpenter.GetCapacityType(n.State.Node)) {
+			report.Reserved++
+			continue
+		}
+		if !n.HasCost {
+			report.Unpriced++
+			continue
+		}
+		report.BlockedNodes++
+		report.HourlyCost += n.HourlyCost
+
+		node := n.State.Node.Name
+		perBlocker := n.HourlyCost / float64(len(blockers))
+		for _, blocker := range blockers {
+			byBlocker.add(string(blocker), node, perBlocker)
+			pods := responsiblePods(n.State, blocker)
+			if len(pods) == 0 {
+				report.Unattributed += perBlocker
+				continue
+			}
+			perPod := perBlocker / float64(len(pods))
+			for _, pod := range pods {
+				byNamespace.add(pod.Namespace, node, perPod)
+				byWorkload.add(pod.Namespace+"/"+WorkloadName(pod), node, perPod)
+			}
+		}
+	}
+
+	report.ByBlocker = byBlocker.sorted()
+	report.ByNamespace = byNamespace.sorted()
+	report.ByWorkload = byWorkload.sorted()
+	return report
+}
+
+// responsiblePods returns the movable pods a blocker comes from: pods with the
+// blocking annotation, pods covered by an exhausted PDB, or pods named by the
+// events reporting it
+func responsiblePods(s *BlockerState, blocker BlockerType) []*corev1.Pod {
+	var pods []*corev1.Pod
+	seen := make(map[*corev1.Pod]bool)
+	add := func(pod *corev1.Pod) {
+		if !seen[pod] {
+			seen[pod] = true
+			pods = append(pods, pod)
+		}
+	}
+
+	if blocker == BlockerPDBViolation {
+		for _, block := range FindPDBBlocks(s.Pods, s.PDBs) {
+			add(block.Pod)
+		}
+	}
+
+	podsByName := make(map[string]*corev1.Pod, len(s.Pods))
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		if !IsMovable(pod) {
+			continue
+		}
+		podsByName[pod.Namespace+"/"+pod.Name] = pod
+		if b, found := DetectPodBlocker(pod); found && b == blocker {
+			add(pod)
+		}
+	}
+	for _, event := range s.Events {
+		if !isConsolidationEvent(event) || NormalizeEventMessage(event.Message) != blocker {
+			continue
+		}
+		if pod := podsByName[extractPodFromMessage(event.Message)]; pod != nil {
+			add(pod)
+		}
+	}
+	return pods
+}
+
+// WorkloadName names the controller that owns a pod as Kind/name, resolving
+// Deployment-owned ReplicaSets to their Deployment. Pods without a controller
+// are named Pod/name.
+func WorkloadName(pod *corev1.Pod) string {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		if ref.Kind == "ReplicaSet" {
+			if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+				return "Deployment/" + strings.TrimSuffix(ref.Name, "-"+hash)
+			}
+		}
+		return ref.Kind + "/" + ref.Name
+	}
+	return "Pod/" + pod.Name
+}
+
+// costShares sums cost by name, counting the distinct nodes behind each sum
+type costShares map[string]*costShare
+
+type costShare struct {
+	cost  float64
+	nodes map[string]bool
+}
+
+func newCostShares() costShares {
+	return make(costShares)
+}
+
+func (c costShares) add(name, node string, cost float64) {
+	share, ok := c[name]
+	if !ok {
+		share = &costShare{nodes: make(map[string]bool)}
+		c[name] = share
+	}
+	share.cost += cost
+	share.nodes[node] = true
+}
+
+// sorted returns the shares most expensive first
+func (c costShares) sorted() []CostShare {
+	shares := make([]CostShare, 0, len(c))
+	for name, share := range c {
+		shares = append(shares, CostShare{Name: name, Nodes: len(share.nodes), HourlyCost: share.cost})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].HourlyCost != shares[j].HourlyCost {
+			return shares[i].HourlyCost > shares[j].HourlyCost
+		}
+		return shares[i].Name < shares[j].Name
+	})
+	return shares
+}
+
+// BlockerCost prices the named nodes and attributes the cost of the blocked
+// ones. Without a pricer every blocked node is unpriced.
+func (c *Collector) BlockerCost(snap *ClusterSnapshot, names []string) BlockerCostReport {
+	nodes := make([]PricedState, 0, len(names))
+	for _, name := range names {
+		state := c.BlockerState(snap, name)
+		if state == nil {
+			continue
+		}
+		n := PricedState{State: state}
+		if c.pricer != nil {
+			n.HourlyCost, n.HasCost = c.pricer.NodePrice(state.Node)
+		}
+		nodes = append(nodes, n)
+	}
+	return AttributeBlockerCost(nodes)
+}
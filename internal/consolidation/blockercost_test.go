@@ -0,0 +1,153 @@
+package consolidation
+
+import (
+	"math"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestAttributeBlockerCost(t *testing.T) {
+	doNotDisrupt := map[string]string{karpenter.AnnotationDoNotDisrupt: "true"}
+	controller := true
+	owned := func(pod corev1.Pod, kind, name string) corev1.Pod {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+		return pod
+	}
+	pod := func(namespace, name string, annotations map[string]string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      map[string]string{"app": name},
+			Annotations: annotations,
+		}}
+	}
+	node := func(name string, annotations map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+
+	api := owned(pod("payments", "api-7d9f-x2", doNotDisrupt), "ReplicaSet", "api-7d9f")
+	api.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = "7d9f"
+	ledger := owned(pod("payments", "ledger-0", nil), "StatefulSet", "ledger")
+	pdbs := []policyv1.PodDisruptionBudget{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "ledger"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ledger-0"}}},
+	}}
+
+	reserved := node("node-5", doNotDisrupt)
+	reserved.Labels = map[string]string{karpenter.LabelCapacityType: karpenter.CapacityTypeReserved}
+
+	nodes := []PricedState{
+		{
+			// do-not-disrupt on two pods and an exhausted PDB: 0.10 each
+			State:      NewBlockerState(node("node-1", nil), []corev1.Pod{api, pod("web", "b", doNotDisrupt), ledger}, nil, pdbs, Thresholds{}),
+			HourlyCost: 0.2,
+			HasCost:    true,
+		},
+		{
+			// Only the node is annotated, so no pod is responsible
+			State:      NewBlockerState(node("node-2", doNotDisrupt), nil, nil, nil, Thresholds{}),
+			HourlyCost: 0.3,
+			HasCost:    true,
+		},
+		{
+			State: NewBlockerState(node("node-3", doNotDisrupt), nil, nil, nil, Thresholds{}),
+		},
+		{
+			State:      NewBlockerState(reserved, nil, nil, nil, Thresholds{}),
+			HourlyCost: 0.5,
+			HasCost:    true,
+		},
+		{
+			State:      NewBlockerState(node("node-4", nil), []corev1.Pod{pod("web", "c", nil)}, nil, nil, Thresholds{}),
+			HourlyCost: 1,
+			HasCost:    true,
+		},
+	}
+
+	report := AttributeBlockerCost(nodes)
+
+	if report.BlockedNodes != 2 || report.Unpriced != 1 || report.Reserved != 1 {
+		t.Errorf("BlockedNodes = %d, Unpriced = %d, Reserved = %d; want 2, 1, 1", report.BlockedNodes, report.Unpriced, report.Reserved)
+	}
+	if !closeTo(report.HourlyCost, 0.5) || !closeTo(report.Unattributed, 0.3) {
+		t.Errorf("HourlyCost = %v, Unattributed = %v; want 0.5, 0.3", report.HourlyCost, report.Unattributed)
+	}
+
+	checkShares(t, "ByBlocker", report.ByBlocker, []CostShare{
+		{Name: "do-not-disrupt", Nodes: 2, HourlyCost: 0.4},
+		{Name: "pdb-violation", Nodes: 1, HourlyCost: 0.1},
+	})
+	checkShares(t, "ByNamespace", report.ByNamespace, []CostShare{
+		{Name: "payments", Nodes: 1, HourlyCost: 0.15},
+		{Name: "web", Nodes: 1, HourlyCost: 0.05},
+	})
+	checkShares(t, "ByWorkload", report.ByWorkload, []CostShare{
+		{Name: "payments/StatefulSet/ledger", Nodes: 1, HourlyCost: 0.1},
+		{Name: "payments/Deployment/api", Nodes: 1, HourlyCost: 0.05},
+		{Name: "web/Pod/b", Nodes: 1, HourlyCost: 0.05},
+	})
+}
+
+func checkShares(t *testing.T, name string, got, want []CostShare) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s = %+v, want %+v", name, got, want)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Nodes != want[i].Nodes || !closeTo(got[i].HourlyCost, want[i].HourlyCost) {
+			t.Errorf("%s[%d] = %+v, want %+v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestWorkloadName(t *testing.T) {
+	controller := true
+	tests := []struct {
+		name   string
+		owners []metav1.OwnerReference
+		labels map[string]string
+		want   string
+	}{
+		{name: "bare pod", want: "Pod/web-1"},
+		{
+			name:   "deployment",
+			owners: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5c8f", Controller: &controller}},
+			labels: map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "5c8f"},
+			want:   "Deployment/web",
+		},
+		{
+			name:   "standalone replicaset",
+			owners: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web", Controller: &controller}},
+			want:   "ReplicaSet/web",
+		},
+		{
+			name:   "job",
+			owners: []metav1.OwnerReference{{Kind: "Job", Name: "backup-123", Controller: &controller}},
+			want:   "Job/backup-123",
+		},
+		{
+			name:   "non-controller owner ignored",
+			owners: []metav1.OwnerReference{{Kind: "ConfigMap", Name: "cm"}},
+			want:   "Pod/web-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", OwnerReferences: tt.owners, Labels: tt.labels}}
+			if got := WorkloadName(pod); got != tt.want {
+				t.Errorf("WorkloadName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package consolidation
 import (
 	"regexp"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -25,7 +26,7 @@ const (
 	BlockerLocalStorage       BlockerType = "local-storage"
 )
 
-// HighUtilizationThreshold is the percentage above which utilization is considered high
+// HighUtilizationThreshold is the default percentage above which utilization is considered high
 const HighUtilizationThreshold = 80
 
 // PodBlocker represents a pod that is blocking consolidation
@@ -34,6 +35,7 @@ type PodBlocker struct {
 	Namespace string
 	PodName   string
 	Age       string
+	CreatedAt time.Time
 	Reason    BlockerType
 }
 
@@ -56,6 +58,22 @@ func DetectPodBlocker(pod *corev1.Pod) (BlockerType, bool) {
 	return "", false
 }
 
+// DetectNodeBlocker checks if the node itself has an annotation that blocks consolidation
+func DetectNodeBlocker(node *corev1.Node) (BlockerType, bool) {
+	if node == nil || node.Annotations == nil {
+		return "", false
+	}
+
+	if node.Annotations[karpenter.AnnotationDoNotDisrupt] == "true" {
+		return BlockerDoNotDisrupt, true
+	}
+	if node.Annotations[karpenter.AnnotationDoNotConsolidate] == "true" {
+		return BlockerDoNotConsolidate, true
+	}
+
+	return "", false
+}
+
 // blockerPatterns maps regex patterns to blocker types, compiled once at init
 var blockerPatterns = []struct {
 	pattern *regexp.Regexp
@@ -89,16 +107,21 @@ func NormalizeEventMessage(message string) BlockerType {
 }
 
 // DetectBlockers analyzes pods, events, and utilization to find consolidation blockers
-func DetectBlockers(pods []corev1.Pod, events []corev1.Event, cpuUtil, memUtil int, existingPodNames map[string]bool) []BlockerType {
+// utilization holds per-resource percentages as returned by CalculateResourceUtilization,
+// compared against the per-resource thresholds.
+func DetectBlockers(pods []corev1.Pod, events []corev1.Event, utilization map[corev1.ResourceName]int, thresholds Thresholds, existingPodNames map[string]bool) []BlockerType {
 	blockerSet := make(map[BlockerType]bool)
 
-	// Check high utilization
-	if cpuUtil >= HighUtilizationThreshold || memUtil >= HighUtilizationThreshold {
+	// Check high utilization of any resource
+	if IsHighUtilization(utilization, thresholds) {
 		blockerSet[BlockerHighUtilization] = true
 	}
 
-	// Check pod annotations
+	// Check pod annotations; Karpenter ignores DaemonSet and static pods
 	for i := range pods {
+		if !IsMovable(&pods[i]) {
+			continue
+		}
 		if blocker, found := DetectPodBlocker(&pods[i]); found {
 			blockerSet[blocker] = true
 		}
@@ -154,6 +177,19 @@ func extractPodFromMessage(message string) string {
 	return ""
 }
 
+// pdbNameRegex matches the PDB Karpenter names in eviction events, e.g.
+// pdb "payments/ledger" prevents pod evictions
+var pdbNameRegex = regexp.MustCompile(`(?i)pdb "?([^"\s]+/[^"\s]+)"? prevents`)
+
+// extractPDBFromMessage returns the namespace/name of the PDB an event names
+func extractPDBFromMessage(message string) string {
+	matches := pdbNameRegex.FindStringSubmatch(message)
+	if len(matches) >= 2 {
+		return matches[1]
+	}
+	return ""
+}
+
 // FormatBlockers converts a slice of blockers to a display string
 func FormatBlockers(blockers []BlockerType) string {
 	if len(blockers) == 0 {
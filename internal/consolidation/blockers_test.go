@@ -177,8 +177,7 @@ func TestDetectBlockers(t *testing.T) {
 		name         string
 		pods         []corev1.Pod
 		events       []corev1.Event
-		cpuUtil      int
-		memUtil      int
+		utilization  map[corev1.ResourceName]int
 		podNames     map[string]bool
 		wantBlockers []BlockerType
 	}{
@@ -186,8 +185,7 @@ func TestDetectBlockers(t *testing.T) {
 			name:         "no blockers",
 			pods:         nil,
 			events:       nil,
-			cpuUtil:      50,
-			memUtil:      50,
+			utilization:  map[corev1.ResourceName]int{corev1.ResourceCPU: 50, corev1.ResourceMemory: 50},
 			podNames:     nil,
 			wantBlockers: nil,
 		},
@@ -195,8 +193,7 @@ func TestDetectBlockers(t *testing.T) {
 			name:         "high cpu utilization",
 			pods:         nil,
 			events:       nil,
-			cpuUtil:      85,
-			memUtil:      50,
+			utilization:  map[corev1.ResourceName]int{corev1.ResourceCPU: 85, corev1.ResourceMemory: 50},
 			podNames:     nil,
 			wantBlockers: []BlockerType{BlockerHighUtilization},
 		},
@@ -204,8 +201,15 @@ func TestDetectBlockers(t *testing.T) {
 			name:         "high memory utilization",
 			pods:         nil,
 			events:       nil,
-			cpuUtil:      50,
-			memUtil:      90,
+			utilization:  map[corev1.ResourceName]int{corev1.ResourceCPU: 50, corev1.ResourceMemory: 90},
+			podNames:     nil,
+			wantBlockers: []BlockerType{BlockerHighUtilization},
+		},
+		{
+			name:         "high gpu utilization",
+			pods:         nil,
+			events:       nil,
+			utilization:  map[corev1.ResourceName]int{corev1.ResourceCPU: 20, corev1.ResourceMemory: 30, "nvidia.com/gpu": 100},
 			podNames:     nil,
 			wantBlockers: []BlockerType{BlockerHighUtilization},
 		},
@@ -223,8 +227,7 @@ func TestDetectBlockers(t *testing.T) {
 				},
 			},
 			events:       nil,
-			cpuUtil:      50,
-			memUtil:      50,
+			utilization:  map[corev1.ResourceName]int{corev1.ResourceCPU: 50, corev1.ResourceMemory: 50},
 			podNames:     map[string]bool{"default/blocking-pod": true},
 			wantBlockers: []BlockerType{BlockerDoNotEvict},
 		},
@@ -232,7 +235,7 @@ func TestDetectBlockers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := DetectBlockers(tt.pods, tt.events, tt.cpuUtil, tt.memUtil, tt.podNames)
+			got := DetectBlockers(tt.pods, tt.events, tt.utilization, DefaultThresholds(), tt.podNames)
 
 			if len(got) != len(tt.wantBlockers) {
 				t.Errorf("DetectBlockers() returned %d blockers, want %d", len(got), len(tt.wantBlockers))
@@ -0,0 +1,74 @@
+package consolidation
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodClass describes how Karpenter treats a pod when consolidating its node
+type PodClass string
+
+const (
+	// PodClassDaemon pods are owned by a DaemonSet and run on every node anyway
+	PodClassDaemon PodClass = "daemon"
+	// PodClassStatic pods are mirror pods for kubelet static manifests
+	PodClassStatic PodClass = "static"
+	// PodClassMovable pods must be rescheduled elsewhere before the node can go
+	PodClassMovable PodClass = "movable"
+)
+
+// ClassifyPod returns whether a pod is a DaemonSet pod, a static (mirror) pod,
+// or movable workload
+func ClassifyPod(pod *corev1.Pod) PodClass {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return PodClassStatic
+	}
+	for _, ref := range pod.OwnerReferences {
+		switch ref.Kind {
+		case "DaemonSet":
+			return PodClassDaemon
+		case "Node":
+			return PodClassStatic
+		}
+	}
+	return PodClassMovable
+}
+
+// isTerminal reports whether the pod has finished and no longer holds its node
+func isTerminal(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// IsMovable reports whether the pod is non-terminal movable workload
+func IsMovable(pod *corev1.Pod) bool {
+	return !isTerminal(pod) && ClassifyPod(pod) == PodClassMovable
+}
+
+// SplitPods partitions non-terminal pods into movable workload and fixed overhead
+// (DaemonSet and static pods). Terminal pods are dropped.
+func SplitPods(pods []corev1.Pod) (movable, overhead []corev1.Pod) {
+	for i := range pods {
+		pod := &pods[i]
+		if isTerminal(pod) {
+			continue
+		}
+		if ClassifyPod(pod) == PodClassMovable {
+			movable = append(movable, *pod)
+		} else {
+			overhead = append(overhead, *pod)
+		}
+	}
+	return movable, overhead
+}
+
+// DaemonSetOverhead sums the effective requests of the node's running DaemonSet pods
+func DaemonSetOverhead(pods []corev1.Pod) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for i := range pods {
+		pod := &pods[i]
+		if isTerminal(pod) || ClassifyPod(pod) != PodClassDaemon {
+			continue
+		}
+		addResourceList(total, PodRequests(pod))
+	}
+	return total
+}
@@ -0,0 +1,107 @@
+package consolidation
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestClassifyPod(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      corev1.Pod
+		expected PodClass
+	}{
+		{
+			name:     "plain pod",
+			pod:      corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+			expected: PodClassMovable,
+		},
+		{
+			name: "replicaset pod",
+			pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "app",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "app-abc"}},
+			}},
+			expected: PodClassMovable,
+		},
+		{
+			name: "daemonset pod",
+			pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "node-exporter-x",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "node-exporter"}},
+			}},
+			expected: PodClassDaemon,
+		},
+		{
+			name: "mirror pod",
+			pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:        "kube-proxy-node-1",
+				Annotations: map[string]string{corev1.MirrorPodAnnotationKey: "abc"},
+			}},
+			expected: PodClassStatic,
+		},
+		{
+			name: "node-owned pod",
+			pod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "etcd-node-1",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Node", Name: "node-1"}},
+			}},
+			expected: PodClassStatic,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyPod(&tt.pod); got != tt.expected {
+				t.Errorf("ClassifyPod() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitPodsAndOverhead(t *testing.T) {
+	daemon := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "agent",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}},
+			Annotations:     map[string]string{karpenter.AnnotationDoNotDisrupt: "true"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("200m"),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	app := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	done := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "job"},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+
+	movable, overhead := SplitPods([]corev1.Pod{daemon, app, done})
+	if len(movable) != 1 || movable[0].Name != "app" {
+		t.Errorf("movable = %v, want [app]", movable)
+	}
+	if len(overhead) != 1 || overhead[0].Name != "agent" {
+		t.Errorf("overhead = %v, want [agent]", overhead)
+	}
+
+	cpu := DaemonSetOverhead([]corev1.Pod{daemon, app})[corev1.ResourceCPU]
+	if cpu.MilliValue() != 200 {
+		t.Errorf("DaemonSetOverhead cpu = %s, want 200m", cpu.String())
+	}
+
+	// A do-not-disrupt DaemonSet pod does not block consolidation
+	if blockers := FindBlockingPods([]corev1.Pod{daemon}, "node-1"); len(blockers) != 0 {
+		t.Errorf("FindBlockingPods() = %v, want none for DaemonSet pod", blockers)
+	}
+}
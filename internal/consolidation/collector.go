@@ -2,9 +2,14 @@ package consolidation
 
 import (
 	"context"
+	"io"
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
@@ -16,25 +21,91 @@ type NodeInfo struct {
 	PoolName          string
 	PoolVersion       karpenter.APIVersion
 	CapacityType      string
-	CPUUtilization    int
-	MemoryUtilization int
-	Blockers          []BlockerType
+	ReservationID     string                      // Capacity reservation of a reserved node, when labelled
+	NodeClaimName     string                      // Empty when the node has no NodeClaim or it could not be read
+	Pods              int                         // Every pod on the node, including DaemonSet and static pods
+	BlockingPods      int                         // Movable pods that block consolidation themselves
+	CPUUtilization    int                         // Movable workload only
+	MemoryUtilization int                         // Movable workload only
+	Utilization       map[corev1.ResourceName]int // Movable workload, every allocatable resource
+	// Fixed overhead (DaemonSet and static pods) is reported separately because
+	// Karpenter ignores it when deciding whether a node is empty or its pods fit elsewhere
+	OverheadUtilization map[corev1.ResourceName]int
+	TotalUtilization    map[corev1.ResourceName]int // Movable workload plus fixed overhead
+	DaemonSetOverhead   corev1.ResourceList         // Summed requests of DaemonSet pods
+	HasUsage            bool                        // CPUUsage/MemoryUsage are set from metrics.k8s.io
+	CPUUsage            int
+	MemoryUsage         int
+	Thresholds          Thresholds            // Effective high-utilization thresholds for this node
+	HighUtilization     []corev1.ResourceName // Resources at or above their threshold
+	Countdown           Countdown             // Time until consolidateAfter elapses
+	Empty               bool                  // Only DaemonSet and static pods remain
+	EmptySince          time.Time             // Set when Empty
+	HeldBy              []Check               // Failed checks keeping an empty node past consolidateAfter
+	Blockers            []BlockerType
+	HasCost             bool    // HourlyCost and HourlyWaste are set from the node's price
+	HourlyCost          float64 // Dollars per hour
+	HourlyWaste         float64 // Share of HourlyCost the movable workload does not request; 0 for reserved nodes
 }
 
 // Collector gathers consolidation data from the cluster
 type Collector struct {
 	client       kubernetes.Interface
+	dynamic      dynamic.Interface
 	capabilities *karpenter.ClusterCapabilities
+	thresholds   *ThresholdConfig
+	pricer       NodePricer
+	warnings     io.Writer
 }
 
-// NewCollector creates a new Collector
-func NewCollector(client kubernetes.Interface, capabilities *karpenter.ClusterCapabilities) *Collector {
+// clusterData holds the cluster-wide lookups shared by every node
+type clusterData struct {
+	podsByNode   map[string][]corev1.Pod
+	eventsByNode map[string][]corev1.Event
+	usageByNode  map[string]corev1.ResourceList
+	nodeClaims   map[string]*karpenter.NodeClaim
+	nodePools    map[string]*karpenter.NodePool
+	nodesByPool  map[string][]corev1.Node
+	pdbs         []policyv1.PodDisruptionBudget
+	now          time.Time
+}
+
+// NewCollector creates a new Collector. dynamicClient is used to read Karpenter
+// custom resources and may be nil, in which case those checks report unknown.
+func NewCollector(client kubernetes.Interface, dynamicClient dynamic.Interface, capabilities *karpenter.ClusterCapabilities) *Collector {
 	return &Collector{
 		client:       client,
+		dynamic:      dynamicClient,
 		capabilities: capabilities,
 	}
 }
 
+// SetThresholds configures the high-utilization threshold rules
+func (c *Collector) SetThresholds(cfg *ThresholdConfig) {
+	c.thresholds = cfg
+}
+
+// SetWarningOutput sets where NodePools that cannot be parsed are reported.
+// Without it they are skipped silently.
+func (c *Collector) SetWarningOutput(w io.Writer) {
+	c.warnings = w
+}
+
+// fetchKarpenterResources reads NodeClaims (keyed by node name) and NodePools (keyed by
+// name). ok is false when the resources are unavailable, which callers treat as non-fatal.
+func (c *Collector) fetchKarpenterResources(ctx context.Context) (claims map[string]*karpenter.NodeClaim, pools map[string]*karpenter.NodePool, ok bool) {
+	if c.dynamic == nil || !c.capabilities.HasKarpenter() {
+		return nil, nil, false
+	}
+
+	claims, claimErr := karpenter.FetchNodeClaims(ctx, c.dynamic, c.capabilities)
+	pools, poolErr := karpenter.FetchNodePools(ctx, c.dynamic, c.capabilities, c.warnings)
+	if claimErr != nil || poolErr != nil {
+		return nil, nil, false
+	}
+	return claims, pools, true
+}
+
 // Collect gathers consolidation data for nodes matching the criteria
 func (c *Collector) Collect(ctx context.Context, nodeNames []string, selector string) ([]NodeInfo, error) {
 	// Fetch nodes
@@ -47,20 +118,29 @@ func (c *Collector) Collect(ctx context.Context, nodeNames []string, selector st
 		return nil, nil
 	}
 
-	// Fetch all pods and events in parallel (single API call each)
-	var podsByNode map[string][]corev1.Pod
-	var eventsByNode map[string][]corev1.Event
+	// Fetch all pods, events, PDBs and Karpenter resources in parallel (single API call each)
+	data := clusterData{now: time.Now()}
 	var podErr, eventErr error
 
 	var wg sync.WaitGroup
-	wg.Add(2)
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		data.podsByNode, podErr = FetchAllPods(ctx, c.client)
+	}()
 	go func() {
 		defer wg.Done()
-		podsByNode, podErr = FetchAllPods(ctx, c.client)
+		data.eventsByNode, eventErr = FetchAllNodeEvents(ctx, c.client)
 	}()
 	go func() {
 		defer wg.Done()
-		eventsByNode, eventErr = FetchAllNodeEvents(ctx, c.client)
+		// Non-fatal: without PDBs only Karpenter's events report pdb-violation
+		data.pdbs, _ = FetchAllPDBs(ctx, c.client)
+	}()
+	go func() {
+		defer wg.Done()
+		// Non-fatal: Karpenter-derived fields stay empty without them
+		data.nodeClaims, data.nodePools, _ = c.fetchKarpenterResources(ctx)
 	}()
 	wg.Wait()
 
@@ -69,16 +149,88 @@ func (c *Collector) Collect(ctx context.Context, nodeNames []string, selector st
 	}
 	if eventErr != nil {
 		// Non-fatal: continue without events
-		eventsByNode = make(map[string][]corev1.Event)
+		data.eventsByNode = make(map[string][]corev1.Event)
+	}
+
+	// Actual usage is optional: metrics-server may not be installed
+	if c.dynamic != nil {
+		if usage, err := FetchNodeUsage(ctx, c.dynamic, data.podsByNode); err == nil {
+			data.usageByNode = usage
+		}
+	}
+
+	// Disruption budgets count every node in a pool, not just the selected ones
+	if data.nodePools != nil {
+		poolNodes := nodes
+		if len(nodeNames) > 0 || selector != "" {
+			if all, err := FetchNodes(ctx, c.client, nil, ""); err == nil {
+				poolNodes = all
+			}
+		}
+		data.nodesByPool = groupNodesByPool(poolNodes)
 	}
 
 	// Process nodes concurrently
-	return c.collectParallel(nodes, podsByNode, eventsByNode)
+	results, err := c.collectParallel(nodes, &data)
+	if err != nil {
+		return nil, err
+	}
+	SortEmptyFirst(results)
+	return results, nil
+}
+
+// NodesFromSnapshot gathers the same consolidation data as Collect for the named
+// nodes of a snapshot, without reading the cluster. Times such as the
+// consolidateAfter countdown are measured from when the snapshot was taken, and
+// actual usage is not available.
+func (c *Collector) NodesFromSnapshot(snap *ClusterSnapshot, names []string) ([]NodeInfo, error) {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+	var nodes []corev1.Node
+	for _, node := range snap.Nodes {
+		if selected[node.Name] {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	data := clusterData{
+		podsByNode:   snap.PodsByNode,
+		eventsByNode: snap.EventsByNode,
+		nodeClaims:   snap.NodeClaims,
+		nodePools:    snap.NodePools,
+		pdbs:         snap.PDBs,
+		now:          snap.CollectedAt,
+	}
+	if data.nodePools != nil {
+		data.nodesByPool = groupNodesByPool(snap.Nodes)
+	}
+	results, err := c.collectParallel(nodes, &data)
+	if err != nil {
+		return nil, err
+	}
+	SortEmptyFirst(results)
+	return results, nil
+}
+
+// groupNodesByPool groups nodes by their NodePool or Provisioner name
+func groupNodesByPool(nodes []corev1.Node) map[string][]corev1.Node {
+	byPool := make(map[string][]corev1.Node)
+	for _, node := range nodes {
+		if poolName, _ := karpenter.GetPoolName(&node); poolName != "" {
+			byPool[poolName] = append(byPool[poolName], node)
+		}
+	}
+	return byPool
 }
 
 const maxWorkers = 10
 
-func (c *Collector) collectParallel(nodes []corev1.Node, podsByNode map[string][]corev1.Pod, eventsByNode map[string][]corev1.Event) ([]NodeInfo, error) {
+func (c *Collector) collectParallel(nodes []corev1.Node, data *clusterData) ([]NodeInfo, error) {
 	results := make([]NodeInfo, len(nodes))
 
 	// Use a semaphore to limit concurrency
@@ -93,8 +245,7 @@ func (c *Collector) collectParallel(nodes []corev1.Node, podsByNode map[string][
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			nodeName := nodes[idx].Name
-			results[idx] = c.collectNodeInfo(&nodes[idx], podsByNode[nodeName], eventsByNode[nodeName])
+			results[idx] = c.collectNodeInfo(&nodes[idx], data)
 		}(i)
 	}
 
@@ -103,23 +254,81 @@ func (c *Collector) collectParallel(nodes []corev1.Node, podsByNode map[string][
 	return results, nil
 }
 
-func (c *Collector) collectNodeInfo(node *corev1.Node, pods []corev1.Pod, events []corev1.Event) NodeInfo {
+func (c *Collector) collectNodeInfo(node *corev1.Node, data *clusterData) NodeInfo {
 	info := NodeInfo{
 		Node: node,
 	}
+	pods := data.podsByNode[node.Name]
+	events := data.eventsByNode[node.Name]
 
 	// Get Karpenter info
 	info.PoolName, info.PoolVersion = karpenter.GetPoolName(node)
 	info.CapacityType = karpenter.GetCapacityType(node)
+	info.ReservationID = karpenter.GetReservationID(node)
 
-	// Calculate utilization
-	info.CPUUtilization, info.MemoryUtilization = CalculateUtilization(node, pods)
+	// Calculate utilization, separating movable workload from fixed overhead
+	movable, overhead := SplitPods(pods)
+	info.Utilization = CalculateResourceUtilization(node, movable)
+	info.CPUUtilization, info.MemoryUtilization = CalculateUtilization(node, movable)
+	info.OverheadUtilization = CalculateResourceUtilization(node, overhead)
+	info.TotalUtilization = CalculateResourceUtilization(node, pods)
+	info.DaemonSetOverhead = DaemonSetOverhead(pods)
+	if usage, ok := data.usageByNode[node.Name]; ok {
+		info.HasUsage = true
+		info.CPUUsage, info.MemoryUsage = CalculateUsage(node, usage)
+	}
 
-	// Build pod name set for event validation
-	podNameSet := BuildPodNameSet(pods)
+	if c.pricer != nil {
+		if price, ok := c.pricer.NodePrice(node); ok {
+			info.HasCost = true
+			info.HourlyCost = price
+			// Reserved nodes are prepaid, see karpenter.IsReserved. Waste goes by
+			// the same movable-workload utilization as CPU-UTIL and MEM-UTIL.
+			if !karpenter.IsReserved(info.CapacityType) {
+				info.HourlyWaste = UnrequestedCost(price, info.CPUUtilization, info.MemoryUtilization)
+			}
+		}
+	}
 
-	// Detect blockers
-	info.Blockers = DetectBlockers(pods, events, info.CPUUtilization, info.MemoryUtilization, podNameSet)
+	// Resolve thresholds for the node's pool
+	info.Thresholds = c.thresholds.Resolve(info.PoolName, data.nodePools[info.PoolName])
+	info.HighUtilization = HighUtilizationResources(info.Utilization, info.Thresholds)
+
+	// Detect blockers the same way the what-if and plan commands do
+	state := &BlockerState{
+		Node:        node,
+		Pods:        pods,
+		Events:      events,
+		PDBs:        data.pdbs,
+		Utilization: info.Utilization,
+		Thresholds:  info.Thresholds,
+	}
+	info.Blockers = state.Blockers()
+	info.Pods = len(pods)
+	info.BlockingPods = len(FindBlockingPods(pods, node.Name))
+
+	claim := data.nodeClaims[node.Name]
+	if claim != nil {
+		info.NodeClaimName = claim.Name
+	}
+	info.Countdown = NewCountdown(claim, data.nodePools[info.PoolName])
+
+	// An empty node that outlives consolidateAfter is being held by something
+	if IsEmpty(pods) {
+		info.Empty = true
+		info.EmptySince = EmptySince(node, pods, claim)
+		info.HeldBy = EmptyNodeHolds(ExplainInput{
+			Node:                  node,
+			Pods:                  pods,
+			Events:                events,
+			NodeClaim:             claim,
+			NodePool:              data.nodePools[info.PoolName],
+			PoolNodes:             data.nodesByPool[info.PoolName],
+			HasKarpenterResources: data.nodePools != nil,
+			Thresholds:            info.Thresholds,
+			Now:                   data.now,
+		})
+	}
 
 	return info
 }
@@ -146,3 +355,50 @@ func (c *Collector) CollectPodBlockers(ctx context.Context, nodeNames []string)
 
 	return allBlockers, nil
 }
+
+// CollectExplanation runs every consolidation check for a single node
+func (c *Collector) CollectExplanation(ctx context.Context, nodeName string) (*Explanation, error) {
+	node, err := c.client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	in := ExplainInput{
+		Node: node,
+		Now:  time.Now(),
+	}
+
+	if in.Pods, err = FetchPodsOnNode(ctx, c.client, nodeName); err != nil {
+		return nil, err
+	}
+
+	// Events and PDBs are non-fatal: their checks simply find nothing
+	if events, err := FetchNodeEvents(ctx, c.client, nodeName); err == nil {
+		in.Events = events
+	}
+	if pdbs, err := FetchAllPDBs(ctx, c.client); err == nil {
+		in.PDBs = pdbs
+	}
+
+	poolName, version := karpenter.GetPoolName(node)
+	if claims, pools, ok := c.fetchKarpenterResources(ctx); ok {
+		in.HasKarpenterResources = true
+		in.NodeClaim = claims[nodeName]
+		in.NodePool = pools[poolName]
+	}
+	in.Thresholds = c.thresholds.Resolve(poolName, in.NodePool)
+
+	if poolName != "" {
+		label := karpenter.LabelNodePool
+		if version == karpenter.APIVersionV1Alpha5 {
+			label = karpenter.LabelProvisionerName
+		}
+		poolNodes, err := FetchNodes(ctx, c.client, nil, label+"="+poolName)
+		if err != nil {
+			return nil, err
+		}
+		in.PoolNodes = poolNodes
+	}
+
+	return Explain(in), nil
+}
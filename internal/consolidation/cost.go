@@ -0,0 +1,29 @@
+package consolidation
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodePricer returns a node's hourly price
+type NodePricer interface {
+	NodePrice(node *corev1.Node) (float64, bool)
+}
+
+// SetPricer configures where node prices come from
+func (c *Collector) SetPricer(pricer NodePricer) {
+	c.pricer = pricer
+}
+
+// UnrequestedCost is the share of a node's hourly price its pods do not
+// request, going by the more requested of CPU and memory
+func UnrequestedCost(hourly float64, cpuPercent, memPercent int) float64 {
+	requested := min(max(cpuPercent, memPercent), 100)
+	return hourly * float64(100-requested) / 100
+}
+
+// FormatCost formats an hourly price in dollars
+func FormatCost(hourly float64) string {
+	return fmt.Sprintf("$%.4f", hourly)
+}
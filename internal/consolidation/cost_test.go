@@ -0,0 +1,93 @@
+package consolidation
+
+import (
+	"math"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestUnrequestedCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		hourly   float64
+		cpu, mem int
+		want     float64
+	}{
+		{name: "empty node", hourly: 0.2, want: 0.2},
+		{name: "cpu dominates", hourly: 0.2, cpu: 75, mem: 20, want: 0.05},
+		{name: "memory dominates", hourly: 0.2, cpu: 10, mem: 50, want: 0.1},
+		{name: "full", hourly: 0.2, cpu: 100, mem: 40, want: 0},
+		{name: "over-committed", hourly: 0.2, cpu: 120, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnrequestedCost(tt.hourly, tt.cpu, tt.mem); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("UnrequestedCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// cpuPod is a running pod requesting cpu
+func cpuPod(name, cpu string, owners ...metav1.OwnerReference) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, OwnerReferences: owners},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+type flatPricer float64
+
+func (p flatPricer) NodePrice(*corev1.Node) (float64, bool) { return float64(p), true }
+
+func TestCollectedWaste(t *testing.T) {
+	tests := []struct {
+		name         string
+		capacityType string
+		pods         []corev1.Pod
+		want         float64
+	}{
+		{name: "on-demand", capacityType: karpenter.CapacityTypeOnDemand, want: 0.2},
+		{name: "reserved is prepaid", capacityType: karpenter.CapacityTypeReserved, want: 0},
+		{
+			// Only the web pod's 25% counts, as in CPU-UTIL
+			name:         "DaemonSet overhead is not requested workload",
+			capacityType: karpenter.CapacityTypeOnDemand,
+			pods: []corev1.Pod{
+				cpuPod("agent", "1", metav1.OwnerReference{Kind: "DaemonSet", Name: "agent"}),
+				cpuPod("web", "1"),
+			},
+			want: 0.15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{karpenter.LabelCapacityType: tt.capacityType}},
+				Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				}},
+			}
+			info := (&Collector{pricer: flatPricer(0.2)}).collectNodeInfo(node, &clusterData{
+				podsByNode: map[string][]corev1.Pod{"node-1": tt.pods},
+			})
+			if !info.HasCost || info.HourlyCost != 0.2 {
+				t.Errorf("HasCost = %v, HourlyCost = %v; want true, 0.2", info.HasCost, info.HourlyCost)
+			}
+			if math.Abs(info.HourlyWaste-tt.want) > 1e-9 {
+				t.Errorf("HourlyWaste = %v, want %v", info.HourlyWaste, tt.want)
+			}
+		})
+	}
+}
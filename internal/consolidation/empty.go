@@ -0,0 +1,83 @@
+package consolidation
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// IsEmpty reports whether the node runs only DaemonSet and static pods, which is
+// how Karpenter decides a node is empty
+func IsEmpty(pods []corev1.Pod) bool {
+	return countReschedulablePods(pods) == 0
+}
+
+// EmptySince estimates when an empty node last ran movable workload: the latest of
+// the NodeClaim's lastPodEventTime, the termination of any finished pods still bound
+// to the node, and the node's creation
+func EmptySince(node *corev1.Node, pods []corev1.Pod, claim *karpenter.NodeClaim) time.Time {
+	since := node.CreationTimestamp.Time
+	if claim != nil && claim.LastPodEventTime != nil && claim.LastPodEventTime.After(since) {
+		since = *claim.LastPodEventTime
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if !isTerminal(pod) || ClassifyPod(pod) != PodClassMovable {
+			continue
+		}
+		if finished := podFinishedAt(pod); finished.After(since) {
+			since = finished
+		}
+	}
+	return since
+}
+
+// podFinishedAt returns when the last container of a terminal pod exited,
+// falling back to the pod's deletion timestamp
+func podFinishedAt(pod *corev1.Pod) time.Time {
+	var finished time.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if term := status.State.Terminated; term != nil && term.FinishedAt.After(finished) {
+			finished = term.FinishedAt.Time
+		}
+	}
+	if finished.IsZero() && pod.DeletionTimestamp != nil {
+		finished = pod.DeletionTimestamp.Time
+	}
+	return finished
+}
+
+// EmptyNodeHolds returns the failed checks keeping an empty node alive once its
+// pool's consolidateAfter has elapsed. It returns nil while the node is still
+// within consolidateAfter or when the NodeClaim or NodePool is unknown.
+func EmptyNodeHolds(in ExplainInput) []Check {
+	countdown := NewCountdown(in.NodeClaim, in.NodePool)
+	if !countdown.Known || (!countdown.Never && in.Now.Before(countdown.At)) {
+		return nil
+	}
+
+	var holds []Check
+	for _, c := range Explain(in).Checks {
+		if c.Status == CheckFail {
+			holds = append(holds, c)
+		}
+	}
+	return holds
+}
+
+// SortEmptyFirst moves empty nodes to the front, longest-empty first, and lists
+// the remaining nodes oldest first
+func SortEmptyFirst(nodes []NodeInfo) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Empty != nodes[j].Empty {
+			return nodes[i].Empty
+		}
+		if nodes[i].Empty {
+			return nodes[i].EmptySince.Before(nodes[j].EmptySince)
+		}
+		return nodes[i].Node.CreationTimestamp.Before(&nodes[j].Node.CreationTimestamp)
+	})
+}
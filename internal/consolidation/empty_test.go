@@ -0,0 +1,150 @@
+package consolidation
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestEmptySince(t *testing.T) {
+	created := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", CreationTimestamp: metav1.NewTime(created)}}
+
+	daemon := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "agent",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	finishedJob := func(at time.Time) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "job"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(at)}},
+				}},
+			},
+		}
+	}
+	lastPodEvent := created.Add(2 * time.Hour)
+
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		claim    *karpenter.NodeClaim
+		expected time.Time
+	}{
+		{
+			name:     "never ran workload",
+			pods:     []corev1.Pod{daemon},
+			expected: created,
+		},
+		{
+			name:     "lastPodEventTime",
+			pods:     []corev1.Pod{daemon},
+			claim:    &karpenter.NodeClaim{LastPodEventTime: &lastPodEvent},
+			expected: lastPodEvent,
+		},
+		{
+			name:     "finished pod after lastPodEventTime",
+			pods:     []corev1.Pod{daemon, finishedJob(created.Add(3 * time.Hour))},
+			claim:    &karpenter.NodeClaim{LastPodEventTime: &lastPodEvent},
+			expected: created.Add(3 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsEmpty(tt.pods) {
+				t.Fatalf("IsEmpty() = false, want true")
+			}
+			if got := EmptySince(node, tt.pods, tt.claim); !got.Equal(tt.expected) {
+				t.Errorf("EmptySince() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmptyNodeHolds(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	node := corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "node-1",
+		Labels: map[string]string{karpenter.LabelNodePool: "default"},
+	}}
+	claim := func(lastPodEvent time.Time) *karpenter.NodeClaim {
+		return &karpenter.NodeClaim{
+			Name: "default-abc12",
+			Conditions: []karpenter.NodeClaimCondition{
+				{Type: karpenter.ConditionInitialized, Status: "True", LastTransitionTime: now.Add(-2 * time.Hour)},
+			},
+			LastPodEventTime: &lastPodEvent,
+		}
+	}
+	pool := &karpenter.NodePool{
+		Name:                "default",
+		Version:             karpenter.APIVersionV1,
+		ConsolidationPolicy: karpenter.ConsolidationPolicyWhenEmpty,
+		ConsolidateAfter:    &karpenter.Duration{Duration: 5 * time.Minute},
+		Budgets:             []karpenter.Budget{{Nodes: "0"}},
+	}
+
+	tests := []struct {
+		name     string
+		claim    *karpenter.NodeClaim
+		expected []string
+	}{
+		{name: "within consolidateAfter", claim: claim(now.Add(-time.Minute))},
+		{name: "held by budget", claim: claim(now.Add(-time.Hour)), expected: []string{CheckDisruptionBudget}},
+		{name: "unknown nodeclaim"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holds := EmptyNodeHolds(ExplainInput{
+				Node:                  &node,
+				NodeClaim:             tt.claim,
+				NodePool:              pool,
+				PoolNodes:             []corev1.Node{node},
+				HasKarpenterResources: true,
+				Thresholds:            DefaultThresholds(),
+				Now:                   now,
+			})
+			if len(holds) != len(tt.expected) {
+				t.Fatalf("EmptyNodeHolds() = %+v, want %v", holds, tt.expected)
+			}
+			for i, name := range tt.expected {
+				if holds[i].Name != name {
+					t.Errorf("EmptyNodeHolds()[%d] = %s, want %s", i, holds[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestSortEmptyFirst(t *testing.T) {
+	now := time.Now()
+	node := func(name string, age time.Duration) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))}}
+	}
+	nodes := []NodeInfo{
+		{Node: node("busy-new", time.Hour)},
+		{Node: node("empty-recent", 2*time.Hour), Empty: true, EmptySince: now.Add(-time.Minute)},
+		{Node: node("busy-old", 48*time.Hour)},
+		{Node: node("empty-old", time.Hour), Empty: true, EmptySince: now.Add(-time.Hour)},
+	}
+
+	SortEmptyFirst(nodes)
+
+	expected := []string{"empty-old", "empty-recent", "busy-old", "busy-new"}
+	for i, name := range expected {
+		if nodes[i].Node.Name != name {
+			t.Errorf("nodes[%d] = %s, want %s", i, nodes[i].Node.Name, name)
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package consolidation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// CheckStatus is the outcome of a single consolidation check
+type CheckStatus string
+
+const (
+	CheckPass    CheckStatus = "pass"
+	CheckFail    CheckStatus = "fail"
+	CheckUnknown CheckStatus = "unknown"
+)
+
+// Check names, in the order Karpenter evaluates them
+const (
+	CheckNodeClaimInitialized = "nodeclaim-initialized"
+	CheckNodePoolPolicy       = "nodepool-policy"
+	CheckConsolidateAfter     = "consolidate-after"
+	CheckDisruptionBudget     = "disruption-budget"
+	CheckNodeAnnotations      = "node-annotations"
+	CheckPodBlockers          = "pod-blockers"
+	CheckPDBs                 = "pdbs"
+	CheckUtilization          = "utilization"
+	CheckEvents               = "events"
+)
+
+// Check is the result of one consolidation check with the evidence behind it
+type Check struct {
+	Name     string
+	Status   CheckStatus
+	Evidence []string
+}
+
+// Explanation walks every consolidation check for a single node
+type Explanation struct {
+	NodeName      string
+	PoolName      string
+	CapacityType  string
+	ReservationID string
+	NodeClaimName string
+	Checks        []Check
+	Notes         []string // Context that does not change the verdict
+}
+
+// Verdict summarises the checks: "blocked" if any failed, "undetermined" if any
+// could not be evaluated, otherwise "consolidatable"
+func (e *Explanation) Verdict() string {
+	verdict := "consolidatable"
+	for _, c := range e.Checks {
+		switch c.Status {
+		case CheckFail:
+			return "blocked"
+		case CheckUnknown:
+			verdict = "undetermined"
+		}
+	}
+	return verdict
+}
+
+// ExplainInput holds everything needed to explain a node's consolidation state.
+// NodeClaim and NodePool are nil when they could not be found; HasKarpenterResources
+// is false when the Karpenter custom resources could not be read at all.
+type ExplainInput struct {
+	Node                  *corev1.Node
+	Pods                  []corev1.Pod
+	Events                []corev1.Event
+	PDBs                  []policyv1.PodDisruptionBudget
+	NodeClaim             *karpenter.NodeClaim
+	NodePool              *karpenter.NodePool
+	PoolNodes             []corev1.Node
+	HasKarpenterResources bool
+	Thresholds            Thresholds
+	Now                   time.Time
+}
+
+// Explain runs every consolidation check Karpenter performs against a node
+func Explain(in ExplainInput) *Explanation {
+	expl := &Explanation{
+		NodeName:      in.Node.Name,
+		CapacityType:  karpenter.GetCapacityType(in.Node),
+		ReservationID: karpenter.GetReservationID(in.Node),
+	}
+	expl.PoolName, _ = karpenter.GetPoolName(in.Node)
+	if in.NodeClaim != nil {
+		expl.NodeClaimName = in.NodeClaim.Name
+	}
+
+	expl.Checks = []Check{
+		checkNodeClaimInitialized(in),
+		checkNodePoolPolicy(in),
+		checkConsolidateAfter(in),
+		checkDisruptionBudget(in),
+		checkNodeAnnotations(in),
+		checkPodBlockers(in),
+		checkPDBs(in),
+		checkUtilization(in),
+		checkEvents(in),
+	}
+	if karpenter.IsReserved(expl.CapacityType) {
+		expl.Notes = append(expl.Notes, "reserved capacity is prepaid, so consolidating it saves nothing")
+	}
+
+	return expl
+}
+
+func checkNodeClaimInitialized(in ExplainInput) Check {
+	c := Check{Name: CheckNodeClaimInitialized}
+	switch {
+	case !in.HasKarpenterResources:
+		c.Status = CheckUnknown
+		c.Evidence = []string{"Karpenter NodeClaims could not be read"}
+	case in.NodeClaim == nil:
+		c.Status = CheckFail
+		c.Evidence = []string{"no NodeClaim references this node; it is not managed by Karpenter"}
+	default:
+		cond, ok := in.NodeClaim.Condition(karpenter.ConditionInitialized)
+		switch {
+		case !ok:
+			c.Status = CheckFail
+			c.Evidence = []string{fmt.Sprintf("NodeClaim %s has no %s condition", in.NodeClaim.Name, karpenter.ConditionInitialized)}
+		case cond.Status == string(corev1.ConditionTrue):
+			c.Status = CheckPass
+			c.Evidence = []string{fmt.Sprintf("NodeClaim %s %s=True for %s", in.NodeClaim.Name, cond.Type, FormatAge(cond.LastTransitionTime))}
+		default:
+			c.Status = CheckFail
+			c.Evidence = []string{fmt.Sprintf("NodeClaim %s %s=%s: %s %s", in.NodeClaim.Name, cond.Type, cond.Status, cond.Reason, cond.Message)}
+		}
+	}
+	return c
+}
+
+func checkNodePoolPolicy(in ExplainInput) Check {
+	c := Check{Name: CheckNodePoolPolicy}
+	pool := in.NodePool
+	if pool == nil {
+		c.Status = CheckUnknown
+		c.Evidence = []string{"NodePool for this node could not be found"}
+		return c
+	}
+
+	policy := pool.ConsolidationPolicy
+	if policy == "" {
+		policy = "<default>"
+	}
+	c.Evidence = []string{fmt.Sprintf("%s %s consolidationPolicy=%s", poolKind(pool), pool.Name, policy)}
+
+	if pool.ConsolidateAfter != nil && pool.ConsolidateAfter.Never {
+		c.Status = CheckFail
+		c.Evidence = append(c.Evidence, "consolidateAfter=Never disables consolidation")
+		return c
+	}
+
+	if pool.ConsolidationPolicy == karpenter.ConsolidationPolicyWhenEmpty {
+		movable := countReschedulablePods(in.Pods)
+		if movable > 0 {
+			c.Status = CheckFail
+			c.Evidence = append(c.Evidence, fmt.Sprintf("policy only consolidates empty nodes; node has %d non-daemon pods", movable))
+			return c
+		}
+	}
+
+	c.Status = CheckPass
+	return c
+}
+
+func checkConsolidateAfter(in ExplainInput) Check {
+	c := Check{Name: CheckConsolidateAfter}
+	if in.NodePool == nil || in.NodeClaim == nil {
+		c.Status = CheckUnknown
+		c.Evidence = []string{"requires both the NodePool and the NodeClaim"}
+		return c
+	}
+
+	at, reference, ok := ConsolidatableAt(in.NodeClaim, in.NodePool)
+	if !ok {
+		c.Status = CheckUnknown
+		c.Evidence = []string{"NodeClaim has neither lastPodEventTime nor an Initialized time"}
+		return c
+	}
+	if at.IsZero() {
+		c.Status = CheckFail
+		c.Evidence = []string{"consolidateAfter=Never"}
+		return c
+	}
+
+	after := time.Duration(0)
+	if in.NodePool.ConsolidateAfter != nil {
+		after = in.NodePool.ConsolidateAfter.Duration
+	}
+	c.Evidence = []string{fmt.Sprintf("consolidateAfter=%s, %s %s ago", after, reference, FormatDuration(in.Now.Sub(at.Add(-after))))}
+
+	if in.Now.Before(at) {
+		c.Status = CheckFail
+		c.Evidence = append(c.Evidence, fmt.Sprintf("eligible in %s; pod churn on the node resets this timer", FormatDuration(at.Sub(in.Now))))
+		return c
+	}
+
+	c.Status = CheckPass
+	return c
+}
+
+func checkDisruptionBudget(in ExplainInput) Check {
+	c := Check{Name: CheckDisruptionBudget}
+	if in.NodePool == nil {
+		c.Status = CheckUnknown
+		c.Evidence = []string{"NodePool for this node could not be found"}
+		return c
+	}
+
+	reason := karpenter.DisruptionReasonUnderutilized
+	if countReschedulablePods(in.Pods) == 0 {
+		reason = karpenter.DisruptionReasonEmpty
+	}
+
+	disrupting := 0
+	for i := range in.PoolNodes {
+		if IsDisrupting(&in.PoolNodes[i]) {
+			disrupting++
+		}
+	}
+
+	result, err := in.NodePool.AllowedDisruptions(reason, in.Now, len(in.PoolNodes), disrupting)
+	if err != nil {
+		c.Status = CheckUnknown
+		c.Evidence = []string{err.Error()}
+		return c
+	}
+
+	c.Evidence = []string{fmt.Sprintf("reason=%s nodes=%d disrupting=%d allowed=%d", reason, len(in.PoolNodes), result.Disrupting, result.Allowed)}
+	for _, b := range result.Active {
+		c.Evidence = append(c.Evidence, "active budget: "+b.String())
+	}
+
+	if result.Allowed == 0 {
+		c.Status = CheckFail
+		return c
+	}
+	c.Status = CheckPass
+	return c
+}
+
+func checkNodeAnnotations(in ExplainInput) Check {
+	c := Check{Name: CheckNodeAnnotations}
+	if blocker, found := DetectNodeBlocker(in.Node); found {
+		c.Status = CheckFail
+		c.Evidence = []string{fmt.Sprintf("node has %s annotation", blocker)}
+		return c
+	}
+	c.Status = CheckPass
+	c.Evidence = []string{"no blocking annotations on node"}
+	return c
+}
+
+func checkPodBlockers(in ExplainInput) Check {
+	c := Check{Name: CheckPodBlockers}
+	for i := range in.Pods {
+		pod := &in.Pods[i]
+		if !IsMovable(pod) {
+			continue
+		}
+		if blocker, found := DetectPodBlocker(pod); found {
+			c.Evidence = append(c.Evidence, fmt.Sprintf("%s/%s: %s", pod.Namespace, pod.Name, blocker))
+		}
+	}
+	if len(c.Evidence) > 0 {
+		c.Status = CheckFail
+		return c
+	}
+	c.Status = CheckPass
+	c.Evidence = []string{"no pods with blocking annotations"}
+	return c
+}
+
+func checkPDBs(in ExplainInput) Check {
+	c := Check{Name: CheckPDBs}
+	for _, block := range FindPDBBlocks(in.Pods, in.PDBs) {
+		c.Evidence = append(c.Evidence, fmt.Sprintf("%s/%s: PDB %s disruptionsAllowed=%d",
+			block.Pod.Namespace, block.Pod.Name, block.PDB.Name, block.PDB.Status.DisruptionsAllowed))
+	}
+	if len(c.Evidence) > 0 {
+		c.Status = CheckFail
+		return c
+	}
+	c.Status = CheckPass
+	c.Evidence = []string{"no pods covered by an exhausted PDB"}
+	return c
+}
+
+func checkUtilization(in ExplainInput) Check {
+	c := Check{Name: CheckUtilization}
+	movable, overhead := SplitPods(in.Pods)
+	util := CalculateResourceUtilization(in.Node, movable)
+	fixed := CalculateResourceUtilization(in.Node, overhead)
+
+	parts := make([]string, 0, len(util))
+	for _, name := range SortedResourceNames(util) {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, FormatUtilization(util[name])))
+	}
+	c.Evidence = []string{fmt.Sprintf("movable workload (%d pods): %s", len(movable), strings.Join(parts, " "))}
+	c.Evidence = append(c.Evidence, fmt.Sprintf("daemonset/static overhead (%d pods): cpu=%s memory=%s",
+		len(overhead), FormatUtilization(fixed[corev1.ResourceCPU]), FormatUtilization(fixed[corev1.ResourceMemory])))
+
+	high := HighUtilizationResources(util, in.Thresholds)
+	for _, name := range high {
+		threshold := in.Thresholds.For(name)
+		c.Evidence = append(c.Evidence, fmt.Sprintf("%s %s >= threshold %s (%s)",
+			name, FormatUtilization(util[name]), FormatUtilization(threshold.Percent), threshold.Source))
+	}
+	if len(high) > 0 {
+		c.Status = CheckFail
+		return c
+	}
+	c.Evidence = append(c.Evidence, fmt.Sprintf("default threshold %s (%s)", FormatUtilization(in.Thresholds.Default.Percent), in.Thresholds.Default.Source))
+	c.Status = CheckPass
+	return c
+}
+
+func checkEvents(in ExplainInput) Check {
+	c := Check{Name: CheckEvents}
+	blockers := DetectBlockers(nil, in.Events, nil, in.Thresholds, BuildPodNameSet(in.Pods))
+	if len(blockers) == 0 {
+		c.Status = CheckPass
+		c.Evidence = []string{"no consolidation-blocking events"}
+		return c
+	}
+
+	// Show the most recent message for each blocker type
+	latest := make(map[BlockerType]corev1.Event)
+	for _, event := range in.Events {
+		blocker := NormalizeEventMessage(event.Message)
+		if blocker == "" || !isConsolidationEvent(event) {
+			continue
+		}
+		if prev, ok := latest[blocker]; !ok || eventTime(event).After(eventTime(prev)) {
+			latest[blocker] = event
+		}
+	}
+
+	sort.Slice(blockers, func(i, j int) bool { return blockers[i] < blockers[j] })
+	for _, b := range blockers {
+		if event, ok := latest[b]; ok {
+			c.Evidence = append(c.Evidence, fmt.Sprintf("%s (%s ago): %s", b, FormatAge(eventTime(event)), event.Message))
+		}
+	}
+	c.Status = CheckFail
+	return c
+}
+
+// ConsolidatableAt returns when the node becomes eligible for consolidation:
+// consolidateAfter after the NodeClaim's last pod event (or its initialization if
+// no pod event was recorded). reference names the timestamp used. A zero time
+// with ok=true means consolidation is disabled (consolidateAfter=Never).
+func ConsolidatableAt(claim *karpenter.NodeClaim, pool *karpenter.NodePool) (at time.Time, reference string, ok bool) {
+	var after time.Duration
+	if pool.ConsolidateAfter != nil {
+		if pool.ConsolidateAfter.Never {
+			return time.Time{}, "", true
+		}
+		after = pool.ConsolidateAfter.Duration
+	}
+
+	if claim.LastPodEventTime != nil {
+		return claim.LastPodEventTime.Add(after), "lastPodEventTime", true
+	}
+	if cond, found := claim.Condition(karpenter.ConditionInitialized); found && cond.Status == string(corev1.ConditionTrue) {
+		return cond.LastTransitionTime.Add(after), "initialized", true
+	}
+	return time.Time{}, "", false
+}
+
+// Countdown tracks when consolidateAfter elapses for a node
+type Countdown struct {
+	Known     bool      // Both the NodeClaim and NodePool were found
+	Never     bool      // consolidateAfter=Never
+	At        time.Time // When the node becomes eligible
+	Reference string    // Timestamp the countdown started from
+}
+
+// NewCountdown computes the consolidateAfter countdown from the NodeClaim and
+// NodePool; either may be nil, leaving the countdown unknown
+func NewCountdown(claim *karpenter.NodeClaim, pool *karpenter.NodePool) Countdown {
+	if claim == nil || pool == nil {
+		return Countdown{}
+	}
+	at, reference, ok := ConsolidatableAt(claim, pool)
+	if !ok {
+		return Countdown{}
+	}
+	return Countdown{Known: true, Never: at.IsZero(), At: at, Reference: reference}
+}
+
+// Eligible reports whether consolidateAfter has elapsed at now
+func (c Countdown) Eligible(now time.Time) bool {
+	return c.Known && !c.Never && !now.Before(c.At)
+}
+
+// Format returns the remaining time, "eligible", "never" or <unknown>
+func (c Countdown) Format(now time.Time) string {
+	switch {
+	case !c.Known:
+		return "<unknown>"
+	case c.Never:
+		return "never"
+	case c.Eligible(now):
+		return "eligible"
+	default:
+		return FormatDuration(c.At.Sub(now))
+	}
+}
+
+// countReschedulablePods counts running pods that are neither DaemonSet nor static pods
+func countReschedulablePods(pods []corev1.Pod) int {
+	movable, _ := SplitPods(pods)
+	return len(movable)
+}
+
+func poolKind(pool *karpenter.NodePool) string {
+	if pool.Version == karpenter.APIVersionV1Alpha5 {
+		return "Provisioner"
+	}
+	return "NodePool"
+}
+
+func eventTime(event corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
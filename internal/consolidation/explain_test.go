@@ -0,0 +1,211 @@
+package consolidation
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestExplain(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	node := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{karpenter.LabelNodePool: "default"},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+
+	appPod := func(annotations map[string]string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "app",
+				Namespace:   "default",
+				Labels:      map[string]string{"app": "web"},
+				Annotations: annotations,
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	claim := func(lastPodEvent time.Time) *karpenter.NodeClaim {
+		return &karpenter.NodeClaim{
+			Name:     "default-abc12",
+			NodeName: "node-1",
+			Conditions: []karpenter.NodeClaimCondition{
+				{Type: karpenter.ConditionInitialized, Status: "True", LastTransitionTime: now.Add(-time.Hour)},
+			},
+			LastPodEventTime: &lastPodEvent,
+		}
+	}
+
+	pool := func(policy string, budgets ...karpenter.Budget) *karpenter.NodePool {
+		return &karpenter.NodePool{
+			Name:                "default",
+			Version:             karpenter.APIVersionV1,
+			ConsolidationPolicy: policy,
+			ConsolidateAfter:    &karpenter.Duration{Duration: 5 * time.Minute},
+			Budgets:             budgets,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		in          ExplainInput
+		wantVerdict string
+		wantFailed  []string
+	}{
+		{
+			name: "consolidatable",
+			in: ExplainInput{
+				Pods:      []corev1.Pod{appPod(nil)},
+				NodeClaim: claim(now.Add(-time.Hour)),
+				NodePool:  pool(karpenter.ConsolidationPolicyWhenEmptyOrUnderutilized),
+			},
+			wantVerdict: "consolidatable",
+		},
+		{
+			name: "when-empty policy with workload",
+			in: ExplainInput{
+				Pods:      []corev1.Pod{appPod(nil)},
+				NodeClaim: claim(now.Add(-time.Hour)),
+				NodePool:  pool(karpenter.ConsolidationPolicyWhenEmpty),
+			},
+			wantVerdict: "blocked",
+			wantFailed:  []string{CheckNodePoolPolicy},
+		},
+		{
+			name: "consolidateAfter not elapsed",
+			in: ExplainInput{
+				Pods:      []corev1.Pod{appPod(nil)},
+				NodeClaim: claim(now.Add(-time.Minute)),
+				NodePool:  pool(karpenter.ConsolidationPolicyWhenEmptyOrUnderutilized),
+			},
+			wantVerdict: "blocked",
+			wantFailed:  []string{CheckConsolidateAfter},
+		},
+		{
+			name: "zero budget",
+			in: ExplainInput{
+				Pods:      []corev1.Pod{appPod(nil)},
+				NodeClaim: claim(now.Add(-time.Hour)),
+				NodePool:  pool(karpenter.ConsolidationPolicyWhenEmptyOrUnderutilized, karpenter.Budget{Nodes: "0"}),
+			},
+			wantVerdict: "blocked",
+			wantFailed:  []string{CheckDisruptionBudget},
+		},
+		{
+			name: "pod annotation and exhausted pdb",
+			in: ExplainInput{
+				Pods:      []corev1.Pod{appPod(map[string]string{karpenter.AnnotationDoNotDisrupt: "true"})},
+				NodeClaim: claim(now.Add(-time.Hour)),
+				NodePool:  pool(karpenter.ConsolidationPolicyWhenEmptyOrUnderutilized),
+				PDBs: []policyv1.PodDisruptionBudget{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+						Spec: policyv1.PodDisruptionBudgetSpec{
+							Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+						},
+						Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+					},
+				},
+			},
+			wantVerdict: "blocked",
+			wantFailed:  []string{CheckPodBlockers, CheckPDBs},
+		},
+		{
+			name: "karpenter resources unavailable",
+			in: ExplainInput{
+				Pods: []corev1.Pod{appPod(nil)},
+			},
+			wantVerdict: "undetermined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.Node = &node
+			tt.in.Now = now
+			tt.in.Thresholds = DefaultThresholds()
+			tt.in.PoolNodes = []corev1.Node{node, node, node}
+			tt.in.HasKarpenterResources = tt.in.NodeClaim != nil
+
+			expl := Explain(tt.in)
+			if got := expl.Verdict(); got != tt.wantVerdict {
+				t.Errorf("Verdict() = %v, want %v (checks: %+v)", got, tt.wantVerdict, expl.Checks)
+			}
+
+			failed := make(map[string]bool)
+			for _, c := range expl.Checks {
+				if c.Status == CheckFail {
+					failed[c.Name] = true
+				}
+			}
+			if len(failed) != len(tt.wantFailed) {
+				t.Errorf("failed checks = %v, want %v", failed, tt.wantFailed)
+			}
+			for _, name := range tt.wantFailed {
+				if !failed[name] {
+					t.Errorf("check %s did not fail", name)
+				}
+			}
+		})
+	}
+}
+
+func TestCountdownFormat(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	lastPodEvent := now.Add(-2 * time.Minute)
+	claim := &karpenter.NodeClaim{Name: "default-abc12", LastPodEventTime: &lastPodEvent}
+
+	tests := []struct {
+		name     string
+		claim    *karpenter.NodeClaim
+		pool     *karpenter.NodePool
+		expected string
+	}{
+		{
+			name:     "missing nodepool",
+			claim:    claim,
+			expected: "<unknown>",
+		},
+		{
+			name:     "counting down",
+			claim:    claim,
+			pool:     &karpenter.NodePool{ConsolidateAfter: &karpenter.Duration{Duration: 5 * time.Minute}},
+			expected: "3m",
+		},
+		{
+			name:     "elapsed",
+			claim:    claim,
+			pool:     &karpenter.NodePool{ConsolidateAfter: &karpenter.Duration{Duration: time.Minute}},
+			expected: "eligible",
+		},
+		{
+			name:     "never",
+			claim:    claim,
+			pool:     &karpenter.NodePool{ConsolidateAfter: &karpenter.Duration{Never: true}},
+			expected: "never",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewCountdown(tt.claim, tt.pool).Format(now); got != tt.expected {
+				t.Errorf("Format() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package consolidation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// ShapeGroup is the node count estimate for one NodePool and instance shape
+type ShapeGroup struct {
+	PoolName     string // Empty for nodes not managed by Karpenter
+	InstanceType string // Instance-type label, or the allocatable CPU and memory when unlabelled
+	Nodes        int    // Actual node count
+	// LowerBound is the fewest nodes that could hold the group's pod requests,
+	// resource by resource; Estimate is what first-fit-decreasing packing needs
+	LowerBound int
+	Estimate   int
+}
+
+// Fragmentation is the share of the group's nodes the estimate would not need
+func (g ShapeGroup) Fragmentation() float64 {
+	return fragmentation(g.Nodes, g.Estimate)
+}
+
+// NodeWaste is how much of a node's capacity is unrequested
+type NodeWaste struct {
+	Node         string
+	PoolName     string
+	InstanceType string
+	Utilization  map[corev1.ResourceName]int // All pods, including overhead
+	Unused       int                         // Percent left of the most-requested of CPU and memory
+}
+
+// FragmentationReport compares the actual node count with the fewest nodes
+// that could hold the current pod requests
+type FragmentationReport struct {
+	Groups     []ShapeGroup
+	Nodes      int
+	LowerBound int
+	Estimate   int
+	// Nodes sorted by unused capacity, most first
+	Contributors []NodeWaste
+}
+
+// Fragmentation is the share of all nodes the estimate would not need
+func (r FragmentationReport) Fragmentation() float64 {
+	return fragmentation(r.Nodes, r.Estimate)
+}
+
+func fragmentation(actual, estimate int) float64 {
+	if actual == 0 || estimate >= actual {
+		return 0
+	}
+	return float64(actual-estimate) / float64(actual)
+}
+
+// CollectFragmentation reads the nodes matching selector and their pods and
+// estimates how many nodes the pods need
+func (c *Collector) CollectFragmentation(ctx context.Context, selector string) (FragmentationReport, error) {
+	var nodes []corev1.Node
+	var podsByNode map[string][]corev1.Pod
+	var nodeErr, podErr error
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		nodes, nodeErr = FetchNodes(ctx, c.client, nil, selector)
+	}()
+	go func() {
+		defer wg.Done()
+		podsByNode, podErr = FetchAllPods(ctx, c.client)
+	}()
+	wg.Wait()
+
+	if nodeErr != nil {
+		return FragmentationReport{}, nodeErr
+	}
+	if podErr != nil {
+		return FragmentationReport{}, podErr
+	}
+	return Fragmentation(nodes, podsByNode), nil
+}
+
+// vector holds the amounts pods are packed by: CPU in millicores, memory in
+// bytes and a pod count
+type vector [3]int64
+
+func (v vector) fits(free vector) bool {
+	for i := range v {
+		if v[i] > free[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// podVector is the pod's effective requests as a vector
+func podVector(pod *corev1.Pod) vector {
+	requests := PodRequests(pod)
+	return vector{requests.Cpu().MilliValue(), requests.Memory().Value(), 1}
+}
+
+// Fragmentation groups nodes by NodePool and instance shape and, for each group,
+// estimates the fewest nodes that could hold the movable pods currently on it.
+// A node's capacity is its allocatable minus the DaemonSet and static pods it
+// runs, and every node in a group is assumed to be as small as the smallest.
+// Only resource requests are considered, not scheduling constraints, so the
+// estimate is optimistic.
+func Fragmentation(nodes []corev1.Node, podsByNode map[string][]corev1.Pod) FragmentationReport {
+	type group struct {
+		ShapeGroup
+		capacity vector
+		pods     []vector
+	}
+	groups := make(map[string]*group)
+	var order []string
+	var report FragmentationReport
+
+	for i := range nodes {
+		node := &nodes[i]
+		poolName, _ := karpenter.GetPoolName(node)
+		instanceType := shapeName(node)
+		pods := podsByNode[node.Name]
+
+		key := poolName + "\x00" + instanceType
+		g, ok := groups[key]
+		if !ok {
+			g = &group{ShapeGroup: ShapeGroup{PoolName: poolName, InstanceType: instanceType}}
+			for j := range g.capacity {
+				g.capacity[j] = math.MaxInt64
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.Nodes++
+
+		movable, overhead := SplitPods(pods)
+		capacity := vector{
+			node.Status.Allocatable.Cpu().MilliValue(),
+			node.Status.Allocatable.Memory().Value(),
+			node.Status.Allocatable.Pods().Value(),
+		}
+		for j := range overhead {
+			v := podVector(&overhead[j])
+			for k := range capacity {
+				capacity[k] -= v[k]
+			}
+		}
+		for k := range capacity {
+			g.capacity[k] = min(g.capacity[k], capacity[k])
+		}
+		for j := range movable {
+			g.pods = append(g.pods, podVector(&movable[j]))
+		}
+
+		util := CalculateResourceUtilization(node, pods)
+		report.Contributors = append(report.Contributors, NodeWaste{
+			Node:         node.Name,
+			PoolName:     poolName,
+			InstanceType: instanceType,
+			Utilization:  util,
+			Unused:       100 - max(util[corev1.ResourceCPU], util[corev1.ResourceMemory]),
+		})
+	}
+
+	sort.Strings(order)
+	for _, key := range order {
+		g := groups[key]
+		g.LowerBound = lowerBound(g.pods, g.capacity)
+		g.Estimate = firstFitDecreasing(g.pods, g.capacity)
+		report.Groups = append(report.Groups, g.ShapeGroup)
+		report.Nodes += g.Nodes
+		report.LowerBound += g.LowerBound
+		report.Estimate += g.Estimate
+	}
+
+	sort.SliceStable(report.Contributors, func(i, j int) bool {
+		return report.Contributors[i].Unused > report.Contributors[j].Unused
+	})
+	return report
+}
+
+// shapeName identifies a node's instance shape
+func shapeName(node *corev1.Node) string {
+	if instanceType := node.Labels[corev1.LabelInstanceTypeStable]; instanceType != "" {
+		return instanceType
+	}
+	return fmt.Sprintf("%scpu/%s", node.Status.Allocatable.Cpu().String(), node.Status.Allocatable.Memory().String())
+}
+
+// lowerBound is the largest, over the packed resources, of the total requested
+// divided by one node's capacity, rounded up. A pod larger than a node counts
+// as one full node, matching firstFitDecreasing.
+func lowerBound(pods []vector, capacity vector) int {
+	var total vector
+	for _, p := range pods {
+		for i := range total {
+			total[i] += min(p[i], max(capacity[i], 0))
+		}
+	}
+	bound := 0
+	for i := range total {
+		if capacity[i] <= 0 {
+			continue
+		}
+		bound = max(bound, int((total[i]+capacity[i]-1)/capacity[i]))
+	}
+	if bound == 0 && len(pods) > 0 {
+		bound = 1
+	}
+	return bound
+}
+
+// firstFitDecreasing packs pods largest first, by their largest share of a
+// node, into the first node with room, opening a node when none has. A pod too
+// large for an empty node gets one to itself.
+func firstFitDecreasing(pods []vector, capacity vector) int {
+	share := func(p vector) float64 {
+		largest := 0.0
+		for i := range p {
+			if capacity[i] > 0 {
+				largest = max(largest, float64(p[i])/float64(capacity[i]))
+			}
+		}
+		return largest
+	}
+	sorted := append([]vector(nil), pods...)
+	sort.SliceStable(sorted, func(i, j int) bool { return share(sorted[i]) > share(sorted[j]) })
+
+	var free []vector
+	for _, p := range sorted {
+		placed := false
+		for i := range free {
+			if p.fits(free[i]) {
+				for k := range p {
+					free[i][k] -= p[k]
+				}
+				placed = true
+				break
+			}
+		}
+		if placed {
+			continue
+		}
+		bin := capacity
+		for k := range p {
+			bin[k] -= p[k]
+		}
+		free = append(free, bin)
+	}
+	return len(free)
+}
@@ -0,0 +1,146 @@
+package consolidation
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestFragmentation(t *testing.T) {
+	node := func(name, pool, instanceType string) corev1.Node {
+		labels := map[string]string{}
+		if pool != "" {
+			labels[karpenter.LabelNodePool] = pool
+		}
+		if instanceType != "" {
+			labels[corev1.LabelInstanceTypeStable] = instanceType
+		}
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			}},
+		}
+	}
+	pod := func(name, cpu string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container(cpu, "1Gi")}},
+		}
+	}
+	daemon := func(name string) corev1.Pod {
+		p := pod(name, "500m")
+		p.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}}
+		return p
+	}
+
+	nodes := []corev1.Node{
+		node("a", "default", "m5.xlarge"),
+		node("b", "default", "m5.xlarge"),
+		node("c", "default", "m5.xlarge"),
+		node("d", "", ""),
+	}
+	podsByNode := map[string][]corev1.Pod{
+		"a": {daemon("agent-a"), pod("a1", "1"), pod("a2", "1")},
+		"b": {daemon("agent-b"), pod("b1", "1")},
+		"c": {daemon("agent-c"), pod("c1", "2")},
+	}
+
+	report := Fragmentation(nodes, podsByNode)
+
+	if len(report.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(report.Groups), report.Groups)
+	}
+	// Capacity per node is 3500m after the DaemonSet pod; 5 CPUs of pods need
+	// two nodes both by the bound and by packing 2+1 and 1+1
+	want := ShapeGroup{PoolName: "default", InstanceType: "m5.xlarge", Nodes: 3, LowerBound: 2, Estimate: 2}
+	if got := report.Groups[1]; got != want {
+		t.Errorf("default group = %+v, want %+v", got, want)
+	}
+	// An unlabelled node falls back to its allocatable shape and, with no pods,
+	// is not needed at all
+	want = ShapeGroup{InstanceType: "4cpu/16Gi", Nodes: 1}
+	if got := report.Groups[0]; got != want {
+		t.Errorf("unmanaged group = %+v, want %+v", got, want)
+	}
+
+	if report.Nodes != 4 || report.Estimate != 2 || report.LowerBound != 2 {
+		t.Errorf("totals = %d nodes, %d estimate, %d lower bound; want 4, 2, 2", report.Nodes, report.Estimate, report.LowerBound)
+	}
+	if got := report.Fragmentation(); got != 0.5 {
+		t.Errorf("Fragmentation() = %v, want 0.5", got)
+	}
+
+	var order []string
+	for _, c := range report.Contributors {
+		order = append(order, c.Node)
+	}
+	wantOrder := []string{"d", "b", "a", "c"}
+	for i := range wantOrder {
+		if i >= len(order) || order[i] != wantOrder[i] {
+			t.Fatalf("contributors = %v, want %v", order, wantOrder)
+		}
+	}
+	if report.Contributors[0].Unused != 100 {
+		t.Errorf("empty node unused = %d, want 100", report.Contributors[0].Unused)
+	}
+}
+
+func TestMinimumNodeEstimates(t *testing.T) {
+	capacity := vector{1000, 1000, 10}
+	tests := []struct {
+		name      string
+		pods      []vector
+		wantBound int
+		wantFFD   int
+	}{
+		{name: "no pods"},
+		{
+			name:      "exact fit",
+			pods:      []vector{{500, 100, 1}, {500, 100, 1}},
+			wantBound: 1,
+			wantFFD:   1,
+		},
+		{
+			name:      "memory bound",
+			pods:      []vector{{100, 600, 1}, {100, 600, 1}, {100, 600, 1}},
+			wantBound: 2,
+			wantFFD:   3,
+		},
+		{
+			name:      "pod slots bound",
+			pods:      []vector{{1, 1, 6}, {1, 1, 6}},
+			wantBound: 2,
+			wantFFD:   2,
+		},
+		{
+			name:      "oversized pods get a node each",
+			pods:      []vector{{2000, 100, 1}, {2000, 100, 1}},
+			wantBound: 2,
+			wantFFD:   2,
+		},
+		{
+			name:      "largest first packs tighter",
+			pods:      []vector{{300, 0, 1}, {300, 0, 1}, {700, 0, 1}, {700, 0, 1}},
+			wantBound: 2,
+			wantFFD:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lowerBound(tt.pods, capacity); got != tt.wantBound {
+				t.Errorf("lowerBound() = %d, want %d", got, tt.wantBound)
+			}
+			if got := firstFitDecreasing(tt.pods, capacity); got != tt.wantFFD {
+				t.Errorf("firstFitDecreasing() = %d, want %d", got, tt.wantFFD)
+			}
+		})
+	}
+}
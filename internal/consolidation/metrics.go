@@ -0,0 +1,122 @@
+package consolidation
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Group/version/resources served by metrics-server
+var (
+	NodeMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+	PodMetricsGVR  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+)
+
+type rawNodeMetrics struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Usage             corev1.ResourceList `json:"usage"`
+}
+
+type rawPodMetrics struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Containers        []struct {
+		Name  string              `json:"name"`
+		Usage corev1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+// FetchNodeMetrics retrieves actual node usage from metrics.k8s.io NodeMetrics, keyed by node name
+func FetchNodeMetrics(ctx context.Context, client dynamic.Interface) (map[string]corev1.ResourceList, error) {
+	list, err := client.Resource(NodeMetricsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]corev1.ResourceList, len(list.Items))
+	for i := range list.Items {
+		var raw rawNodeMetrics
+		if err := fromUnstructured(&list.Items[i], &raw); err != nil {
+			return nil, err
+		}
+		usage[raw.Name] = raw.Usage
+	}
+	return usage, nil
+}
+
+// FetchPodMetrics retrieves actual pod usage from metrics.k8s.io PodMetrics, keyed by "namespace/name"
+func FetchPodMetrics(ctx context.Context, client dynamic.Interface) (map[string]corev1.ResourceList, error) {
+	list, err := client.Resource(PodMetricsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]corev1.ResourceList, len(list.Items))
+	for i := range list.Items {
+		var raw rawPodMetrics
+		if err := fromUnstructured(&list.Items[i], &raw); err != nil {
+			return nil, err
+		}
+		total := corev1.ResourceList{}
+		for _, c := range raw.Containers {
+			addResourceList(total, c.Usage)
+		}
+		usage[raw.Namespace+"/"+raw.Name] = total
+	}
+	return usage, nil
+}
+
+// FetchNodeUsage combines NodeMetrics and PodMetrics into actual usage per node.
+// NodeMetrics are preferred because they include system daemons; nodes without a
+// NodeMetrics entry fall back to the sum of their pods' PodMetrics. Returns an
+// error only when neither API is available (e.g. metrics-server is not installed).
+func FetchNodeUsage(ctx context.Context, client dynamic.Interface, podsByNode map[string][]corev1.Pod) (map[string]corev1.ResourceList, error) {
+	nodeUsage, nodeErr := FetchNodeMetrics(ctx, client)
+	podUsage, podErr := FetchPodMetrics(ctx, client)
+	if nodeErr != nil && podErr != nil {
+		return nil, nodeErr
+	}
+	if nodeUsage == nil {
+		nodeUsage = make(map[string]corev1.ResourceList)
+	}
+
+	for nodeName, pods := range podsByNode {
+		if _, ok := nodeUsage[nodeName]; ok || podUsage == nil {
+			continue
+		}
+		total := corev1.ResourceList{}
+		found := false
+		for _, pod := range pods {
+			if usage, ok := podUsage[pod.Namespace+"/"+pod.Name]; ok {
+				addResourceList(total, usage)
+				found = true
+			}
+		}
+		if found {
+			nodeUsage[nodeName] = total
+		}
+	}
+
+	return nodeUsage, nil
+}
+
+// CalculateUsage computes actual CPU and memory usage as percentages of node allocatable
+func CalculateUsage(node *corev1.Node, usage corev1.ResourceList) (cpuPercent, memPercent int) {
+	allocatable := node.Status.Allocatable
+	if allocatable == nil || usage == nil {
+		return 0, 0
+	}
+
+	cpu := usage[corev1.ResourceCPU]
+	mem := usage[corev1.ResourceMemory]
+
+	return calculatePercentage(cpu, *allocatable.Cpu()), calculatePercentage(mem, *allocatable.Memory())
+}
+
+func fromUnstructured(obj *unstructured.Unstructured, into interface{}) error {
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into)
+}
@@ -0,0 +1,91 @@
+package consolidation
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestFetchNodeUsage(t *testing.T) {
+	nodeMetrics := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "NodeMetrics",
+		"metadata":   map[string]interface{}{"name": "node-1"},
+		"usage":      map[string]interface{}{"cpu": "400m", "memory": "1Gi"},
+	}}
+	podMetrics := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]interface{}{"name": "app", "namespace": "default"},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "a", "usage": map[string]interface{}{"cpu": "100m", "memory": "256Mi"}},
+			map[string]interface{}{"name": "b", "usage": map[string]interface{}{"cpu": "50m", "memory": "256Mi"}},
+		},
+	}}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			NodeMetricsGVR: "NodeMetricsList",
+			PodMetricsGVR:  "PodMetricsList",
+		})
+
+	// Create through the resource clients: the fake tracker cannot derive the
+	// "nodes"/"pods" resource names from the NodeMetrics/PodMetrics kinds
+	ctx := context.Background()
+	if _, err := client.Resource(NodeMetricsGVR).Create(ctx, nodeMetrics, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create NodeMetrics: %v", err)
+	}
+	if _, err := client.Resource(PodMetricsGVR).Namespace("default").Create(ctx, podMetrics, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create PodMetrics: %v", err)
+	}
+
+	podsByNode := map[string][]corev1.Pod{
+		"node-1": {{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}},
+		"node-2": {{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}},
+		"node-3": {{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "default"}}},
+	}
+
+	usage, err := FetchNodeUsage(ctx, client, podsByNode)
+	if err != nil {
+		t.Fatalf("FetchNodeUsage() error = %v", err)
+	}
+
+	cpu := usage["node-1"][corev1.ResourceCPU]
+	if cpu.Cmp(resource.MustParse("400m")) != 0 {
+		t.Errorf("node-1 cpu = %v, want 400m from NodeMetrics", cpu.String())
+	}
+	mem := usage["node-2"][corev1.ResourceMemory]
+	if mem.Cmp(resource.MustParse("512Mi")) != 0 {
+		t.Errorf("node-2 memory = %v, want 512Mi summed from PodMetrics", mem.String())
+	}
+	if _, ok := usage["node-3"]; ok {
+		t.Errorf("node-3 has usage, want none")
+	}
+}
+
+func TestCalculateUsage(t *testing.T) {
+	node := &corev1.Node{
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+	usage := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("400m"),
+		corev1.ResourceMemory: resource.MustParse("2Gi"),
+	}
+
+	cpu, mem := CalculateUsage(node, usage)
+	if cpu != 10 || mem != 25 {
+		t.Errorf("CalculateUsage() = %d, %d, want 10, 25", cpu, mem)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
 )
 
 // FetchNodes retrieves nodes from the cluster, optionally filtered by names or label selector.
@@ -61,6 +63,16 @@ func GetNodeStatus(node *corev1.Node) string {
 	return "Unknown"
 }
 
+// GetNodeInternalIP returns the node's first InternalIP address, or "" if it has none
+func GetNodeInternalIP(node *corev1.Node) string {
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == corev1.NodeInternalIP {
+			return addr.Address
+		}
+	}
+	return ""
+}
+
 // GetNodeRoles returns a comma-separated string of node roles
 func GetNodeRoles(node *corev1.Node) string {
 	roles := []string{}
@@ -89,3 +101,17 @@ func join(strs []string, sep string) string {
 	}
 	return result
 }
+
+// IsDisrupting reports whether Karpenter is already disrupting the node
+// (deletion in progress or tainted for disruption)
+func IsDisrupting(node *corev1.Node) bool {
+	if node.DeletionTimestamp != nil {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == karpenter.TaintDisrupted || taint.Key == karpenter.TaintDisruptionV1Beta1 {
+			return true
+		}
+	}
+	return false
+}
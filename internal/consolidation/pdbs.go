@@ -0,0 +1,62 @@
+package consolidation
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PDBBlock records a pod whose eviction is currently disallowed by a PodDisruptionBudget
+type PDBBlock struct {
+	Pod *corev1.Pod
+	PDB *policyv1.PodDisruptionBudget
+}
+
+// FetchAllPDBs retrieves PodDisruptionBudgets in all namespaces
+func FetchAllPDBs(ctx context.Context, client kubernetes.Interface) ([]policyv1.PodDisruptionBudget, error) {
+	pdbList, err := client.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pdbList.Items, nil
+}
+
+// MatchingPDBs returns the PDBs in the pod's namespace whose selector matches the pod
+func MatchingPDBs(pod *corev1.Pod, pdbs []policyv1.PodDisruptionBudget) []*policyv1.PodDisruptionBudget {
+	var matches []*policyv1.PodDisruptionBudget
+	for i := range pdbs {
+		pdb := &pdbs[i]
+		if pdb.Namespace != pod.Namespace || pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			matches = append(matches, pdb)
+		}
+	}
+	return matches
+}
+
+// FindPDBBlocks returns movable pods covered by a PDB that currently allows no disruptions
+func FindPDBBlocks(pods []corev1.Pod, pdbs []policyv1.PodDisruptionBudget) []PDBBlock {
+	var blocks []PDBBlock
+	for i := range pods {
+		pod := &pods[i]
+		if !IsMovable(pod) {
+			continue
+		}
+		for _, pdb := range MatchingPDBs(pod, pdbs) {
+			if pdb.Status.DisruptionsAllowed <= 0 {
+				blocks = append(blocks, PDBBlock{Pod: pod, PDB: pdb})
+			}
+		}
+	}
+	return blocks
+}
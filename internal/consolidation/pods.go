@@ -34,18 +34,22 @@ func BuildPodNameSet(pods []corev1.Pod) map[string]bool {
 	return set
 }
 
-// FindBlockingPods returns pods that have consolidation-blocking annotations
+// FindBlockingPods returns movable pods that have consolidation-blocking annotations
 func FindBlockingPods(pods []corev1.Pod, nodeName string) []PodBlocker {
 	var blockers []PodBlocker
 
 	for i := range pods {
 		pod := &pods[i]
+		if !IsMovable(pod) {
+			continue
+		}
 		if blocker, found := DetectPodBlocker(pod); found {
 			blockers = append(blockers, PodBlocker{
 				NodeName:  nodeName,
 				Namespace: pod.Namespace,
 				PodName:   pod.Name,
 				Age:       FormatAge(pod.CreationTimestamp.Time),
+				CreatedAt: pod.CreationTimestamp.Time,
 				Reason:    blocker,
 			})
 		}
@@ -53,3 +57,17 @@ func FindBlockingPods(pods []corev1.Pod, nodeName string) []PodBlocker {
 
 	return blockers
 }
+
+// FetchPodsOnNode retrieves the pods scheduled to a single node
+func FetchPodsOnNode(ctx context.Context, client kubernetes.Interface, nodeName string) ([]corev1.Pod, error) {
+	listOpts := metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + nodeName,
+	}
+
+	podList, err := client.CoreV1().Pods("").List(ctx, listOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return podList.Items, nil
+}
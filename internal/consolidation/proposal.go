@@ -0,0 +1,147 @@
+package consolidation
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// PoolEvaluation is a node's consolidation state under one NodePool spec
+type PoolEvaluation struct {
+	Verdict  string   // Explain verdict
+	Failed   []string // Names of the failed Explain checks
+	Blockers []BlockerType
+	// Drift lists the pool requirements the node's labels no longer satisfy;
+	// Karpenter replaces drifted nodes
+	Drift     []string
+	ExpiresAt time.Time // Zero when expireAfter is unset or Never
+	// AtLimits lists pool resources whose limit the pool's nodes already reach,
+	// so no replacement node can be launched
+	AtLimits []corev1.ResourceName
+}
+
+// Expired reports whether expireAfter has elapsed at now
+func (e PoolEvaluation) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// NodePoolDiff compares a node under its live NodePool with a proposed spec
+type NodePoolDiff struct {
+	Node     string
+	PoolName string
+	Before   PoolEvaluation
+	After    PoolEvaluation
+}
+
+// Changed reports whether the proposed spec changes anything for the node
+func (d NodePoolDiff) Changed() bool {
+	return !reflect.DeepEqual(d.Before, d.After)
+}
+
+// EvaluateNodePool evaluates a node against in.NodePool: the Explain verdict,
+// the node's blockers with the pool's thresholds, requirement drift, expiry
+// and limits
+func EvaluateNodePool(in ExplainInput) PoolEvaluation {
+	expl := Explain(in)
+	eval := PoolEvaluation{Verdict: expl.Verdict()}
+	for _, c := range expl.Checks {
+		if c.Status == CheckFail {
+			eval.Failed = append(eval.Failed, c.Name)
+		}
+	}
+
+	eval.Blockers = NewBlockerState(in.Node, in.Pods, in.Events, in.PDBs, in.Thresholds).Blockers()
+
+	pool := in.NodePool
+	if pool == nil {
+		return eval
+	}
+	for _, req := range karpenter.UnsatisfiedRequirements(in.Node.Labels, pool.Requirements) {
+		eval.Drift = append(eval.Drift, karpenter.FormatRequirement(req))
+	}
+	if pool.ExpireAfter != nil && !pool.ExpireAfter.Never {
+		// Karpenter counts expireAfter from the NodeClaim's creation
+		created := in.Node.CreationTimestamp.Time
+		if in.NodeClaim != nil && !in.NodeClaim.CreationTimestamp.IsZero() {
+			created = in.NodeClaim.CreationTimestamp
+		}
+		eval.ExpiresAt = created.Add(pool.ExpireAfter.Duration)
+	}
+	eval.AtLimits = resourcesAtLimit(pool.Limits, in.PoolNodes)
+	return eval
+}
+
+// resourcesAtLimit returns the limited resources whose summed node capacity
+// reaches the limit
+func resourcesAtLimit(limits corev1.ResourceList, nodes []corev1.Node) []corev1.ResourceName {
+	var atLimit []corev1.ResourceName
+	for name, limit := range limits {
+		var used int64
+		for i := range nodes {
+			if capacity, ok := nodes[i].Status.Capacity[name]; ok {
+				used += capacity.MilliValue()
+			}
+		}
+		if used >= limit.MilliValue() {
+			atLimit = append(atLimit, name)
+		}
+	}
+	sort.Slice(atLimit, func(i, j int) bool { return atLimit[i] < atLimit[j] })
+	return atLimit
+}
+
+// DiffNodePools evaluates each candidate node managed by one of the proposed
+// pools under its live NodePool and under the proposed spec. Nodes of other
+// pools are skipped.
+func (c *Collector) DiffNodePools(snap *ClusterSnapshot, proposed []*karpenter.NodePool, candidates []string, now time.Time) []NodePoolDiff {
+	proposedByName := make(map[string]*karpenter.NodePool, len(proposed))
+	for _, pool := range proposed {
+		proposedByName[pool.Name] = pool
+	}
+	nodesByName := make(map[string]*corev1.Node, len(snap.Nodes))
+	for i := range snap.Nodes {
+		nodesByName[snap.Nodes[i].Name] = &snap.Nodes[i]
+	}
+	nodesByPool := groupNodesByPool(snap.Nodes)
+
+	var diffs []NodePoolDiff
+	for _, name := range candidates {
+		node := nodesByName[name]
+		if node == nil {
+			continue
+		}
+		poolName, _ := karpenter.GetPoolName(node)
+		next, ok := proposedByName[poolName]
+		if !ok {
+			continue
+		}
+
+		in := ExplainInput{
+			Node:                  node,
+			Pods:                  snap.PodsByNode[name],
+			Events:                snap.EventsByNode[name],
+			PDBs:                  snap.PDBs,
+			NodeClaim:             snap.NodeClaims[name],
+			PoolNodes:             nodesByPool[poolName],
+			HasKarpenterResources: snap.NodePools != nil,
+			Now:                   now,
+		}
+		before, after := in, in
+		before.NodePool = snap.NodePools[poolName]
+		before.Thresholds = c.thresholds.Resolve(poolName, before.NodePool)
+		after.NodePool = next
+		after.Thresholds = c.thresholds.Resolve(poolName, next)
+
+		diffs = append(diffs, NodePoolDiff{
+			Node:     name,
+			PoolName: poolName,
+			Before:   EvaluateNodePool(before),
+			After:    EvaluateNodePool(after),
+		})
+	}
+	return diffs
+}
@@ -0,0 +1,133 @@
+package consolidation
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestDiffNodePools(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	node := func(name, pool, capacityType string, age time.Duration) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Labels: map[string]string{
+					karpenter.LabelNodePool:     pool,
+					karpenter.LabelCapacityType: capacityType,
+				},
+			},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+			},
+		}
+	}
+	lastPodEvent := now.Add(-time.Hour)
+	claim := func(name string) *karpenter.NodeClaim {
+		return &karpenter.NodeClaim{
+			Name:     name + "-claim",
+			NodeName: name,
+			Conditions: []karpenter.NodeClaimCondition{
+				{Type: karpenter.ConditionInitialized, Status: "True", LastTransitionTime: now.Add(-2 * time.Hour)},
+			},
+			LastPodEventTime: &lastPodEvent,
+		}
+	}
+
+	live := &karpenter.NodePool{
+		Name:                "default",
+		Version:             karpenter.APIVersionV1,
+		ConsolidationPolicy: karpenter.ConsolidationPolicyWhenEmptyOrUnderutilized,
+		ConsolidateAfter:    &karpenter.Duration{Duration: 5 * time.Minute},
+	}
+	proposed := &karpenter.NodePool{
+		Name:                "default",
+		Version:             karpenter.APIVersionV1,
+		ConsolidationPolicy: karpenter.ConsolidationPolicyWhenEmptyOrUnderutilized,
+		ConsolidateAfter:    &karpenter.Duration{Duration: 2 * time.Hour},
+		ExpireAfter:         &karpenter.Duration{Duration: 24 * time.Hour},
+		Requirements: []corev1.NodeSelectorRequirement{
+			{Key: karpenter.LabelCapacityType, Operator: corev1.NodeSelectorOpIn, Values: []string{karpenter.CapacityTypeSpot}},
+		},
+		Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")},
+	}
+
+	snap := &ClusterSnapshot{
+		Nodes: []corev1.Node{
+			node("spot-1", "default", karpenter.CapacityTypeSpot, time.Hour),
+			node("od-1", "default", karpenter.CapacityTypeOnDemand, 48*time.Hour),
+			node("other-1", "other", karpenter.CapacityTypeSpot, time.Hour),
+		},
+		PodsByNode: map[string][]corev1.Pod{},
+		NodeClaims: map[string]*karpenter.NodeClaim{"spot-1": claim("spot-1"), "od-1": claim("od-1"), "other-1": claim("other-1")},
+		NodePools:  map[string]*karpenter.NodePool{"default": live, "other": live},
+	}
+
+	collector := &Collector{}
+	diffs := collector.DiffNodePools(snap, []*karpenter.NodePool{proposed}, []string{"spot-1", "od-1", "other-1"}, now)
+	if len(diffs) != 2 {
+		t.Fatalf("got %d diffs, want 2 (nodes of other pools are skipped)", len(diffs))
+	}
+
+	spot, onDemand := diffs[0], diffs[1]
+	if spot.Before.Verdict != "consolidatable" || spot.After.Verdict != "blocked" {
+		t.Errorf("spot-1 verdict %s -> %s, want consolidatable -> blocked", spot.Before.Verdict, spot.After.Verdict)
+	}
+	if len(spot.After.Failed) != 1 || spot.After.Failed[0] != CheckConsolidateAfter {
+		t.Errorf("spot-1 failed checks = %v, want [%s]", spot.After.Failed, CheckConsolidateAfter)
+	}
+	if len(spot.After.Drift) != 0 || spot.After.Expired(now) {
+		t.Errorf("spot-1 drift = %v, expired = %v, want neither", spot.After.Drift, spot.After.Expired(now))
+	}
+	if len(onDemand.After.Drift) != 1 || !onDemand.After.Expired(now) {
+		t.Errorf("od-1 drift = %v, expired = %v, want drifted and expired", onDemand.After.Drift, onDemand.After.Expired(now))
+	}
+	if len(spot.Before.AtLimits) != 0 || len(spot.After.AtLimits) != 1 || spot.After.AtLimits[0] != corev1.ResourceCPU {
+		t.Errorf("at limits %v -> %v, want [] -> [cpu]", spot.Before.AtLimits, spot.After.AtLimits)
+	}
+	if !spot.Changed() {
+		t.Error("Changed() = false")
+	}
+}
+
+func TestEvaluateNodePoolExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}}
+	pool := &karpenter.NodePool{Name: "default", ExpireAfter: &karpenter.Duration{Duration: 24 * time.Hour}}
+
+	tests := []struct {
+		name  string
+		claim *karpenter.NodeClaim
+		want  time.Time
+	}{
+		{
+			name:  "counted from the NodeClaim",
+			claim: &karpenter.NodeClaim{Name: "default-abc12", CreationTimestamp: now.Add(-25 * time.Hour)},
+			want:  now.Add(-time.Hour),
+		},
+		{
+			name: "node without a NodeClaim",
+			want: now.Add(23 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval := EvaluateNodePool(ExplainInput{Node: node, NodeClaim: tt.claim, NodePool: pool, Now: now})
+			if !eval.ExpiresAt.Equal(tt.want) {
+				t.Errorf("ExpiresAt = %v, want %v", eval.ExpiresAt, tt.want)
+			}
+		})
+	}
+}
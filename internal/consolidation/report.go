@@ -0,0 +1,191 @@
+package consolidation
+
+import (
+	"sort"
+	"time"
+)
+
+// HistogramBuckets is how many equal-width utilization buckets a report has
+const HistogramBuckets = 10
+
+// PoolSummary totals the nodes of one NodePool, or of the whole cluster
+type PoolSummary struct {
+	Name    string // Empty for nodes not managed by Karpenter and for cluster totals
+	Nodes   int
+	Pods    int
+	Blocked int // Nodes with at least one blocker
+	Empty   int
+	// Mean utilization of the movable workload across the nodes
+	CPUUtilization    int
+	MemoryUtilization int
+	Priced            int // Nodes with a price; the cost totals cover only these
+	HourlyCost        float64
+	HourlyWaste       float64
+}
+
+// BlockerCount is how many nodes a blocker type holds
+type BlockerCount struct {
+	Blocker BlockerType
+	Nodes   int
+}
+
+// WorkloadBlock is the blocking pods of one workload, named namespace/Kind/name
+type WorkloadBlock struct {
+	Name  string
+	Pods  int
+	Nodes int
+}
+
+// Report summarizes consolidation across a set of nodes
+type Report struct {
+	CollectedAt time.Time
+	Totals      PoolSummary
+	Pools       []PoolSummary // Sorted by name, unmanaged nodes last
+	Blockers    []BlockerCount
+	Workloads   []WorkloadBlock // Most blocked nodes first
+	// Node counts by movable CPU and memory utilization; bucket i holds
+	// i*10% up to (i+1)*10%, and the last bucket everything from 90%
+	CPUHistogram    [HistogramBuckets]int
+	MemoryHistogram [HistogramBuckets]int
+	Nodes           []NodeInfo
+}
+
+// NewReport summarizes nodes. states are the nodes' blocker states, used to
+// find the workloads whose pods block consolidation; nil states are skipped.
+func NewReport(nodes []NodeInfo, states []*BlockerState, collectedAt time.Time) Report {
+	report := Report{CollectedAt: collectedAt, Nodes: nodes}
+
+	pools := make(map[string]*poolTotals)
+	totals := &poolTotals{}
+	blockerNodes := make(map[BlockerType]int)
+	for _, info := range nodes {
+		if pools[info.PoolName] == nil {
+			pools[info.PoolName] = &poolTotals{PoolSummary: PoolSummary{Name: info.PoolName}}
+		}
+		pools[info.PoolName].add(info)
+		totals.add(info)
+		for _, b := range info.Blockers {
+			blockerNodes[b]++
+		}
+		report.CPUHistogram[histogramBucket(info.CPUUtilization)]++
+		report.MemoryHistogram[histogramBucket(info.MemoryUtilization)]++
+	}
+
+	report.Totals = totals.summary()
+	for _, p := range pools {
+		report.Pools = append(report.Pools, p.summary())
+	}
+	sort.Slice(report.Pools, func(i, j int) bool {
+		a, b := report.Pools[i].Name, report.Pools[j].Name
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a < b
+	})
+
+	for blocker, count := range blockerNodes {
+		report.Blockers = append(report.Blockers, BlockerCount{Blocker: blocker, Nodes: count})
+	}
+	sort.Slice(report.Blockers, func(i, j int) bool {
+		if report.Blockers[i].Nodes != report.Blockers[j].Nodes {
+			return report.Blockers[i].Nodes > report.Blockers[j].Nodes
+		}
+		return report.Blockers[i].Blocker < report.Blockers[j].Blocker
+	})
+
+	report.Workloads = blockingWorkloads(states)
+	return report
+}
+
+// poolTotals accumulates a PoolSummary, keeping utilization sums for the means
+type poolTotals struct {
+	PoolSummary
+	cpuSum, memSum int
+}
+
+func (t *poolTotals) add(info NodeInfo) {
+	t.Nodes++
+	t.Pods += info.Pods
+	if len(info.Blockers) > 0 {
+		t.Blocked++
+	}
+	if info.Empty {
+		t.Empty++
+	}
+	t.cpuSum += info.CPUUtilization
+	t.memSum += info.MemoryUtilization
+	if info.HasCost {
+		t.Priced++
+		t.HourlyCost += info.HourlyCost
+		t.HourlyWaste += info.HourlyWaste
+	}
+}
+
+func (t *poolTotals) summary() PoolSummary {
+	s := t.PoolSummary
+	if s.Nodes > 0 {
+		s.CPUUtilization = t.cpuSum / s.Nodes
+		s.MemoryUtilization = t.memSum / s.Nodes
+	}
+	return s
+}
+
+func histogramBucket(percent int) int {
+	return min(max(percent, 0)*HistogramBuckets/100, HistogramBuckets-1)
+}
+
+// blockingWorkloads counts, per workload, the movable pods responsible for a
+// blocker and the nodes they are on
+func blockingWorkloads(states []*BlockerState) []WorkloadBlock {
+	type workload struct {
+		pods  map[string]bool
+		nodes map[string]bool
+	}
+	byName := make(map[string]*workload)
+	for _, s := range states {
+		if s == nil {
+			continue
+		}
+		for _, blocker := range s.Blockers() {
+			for _, pod := range responsiblePods(s, blocker) {
+				name := pod.Namespace + "/" + WorkloadName(pod)
+				w, ok := byName[name]
+				if !ok {
+					w = &workload{pods: make(map[string]bool), nodes: make(map[string]bool)}
+					byName[name] = w
+				}
+				w.pods[pod.Namespace+"/"+pod.Name] = true
+				w.nodes[s.Node.Name] = true
+			}
+		}
+	}
+
+	workloads := make([]WorkloadBlock, 0, len(byName))
+	for name, w := range byName {
+		workloads = append(workloads, WorkloadBlock{Name: name, Pods: len(w.pods), Nodes: len(w.nodes)})
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		a, b := workloads[i], workloads[j]
+		if a.Nodes != b.Nodes {
+			return a.Nodes > b.Nodes
+		}
+		if a.Pods != b.Pods {
+			return a.Pods > b.Pods
+		}
+		return a.Name < b.Name
+	})
+	return workloads
+}
+
+// Report summarizes the named nodes of a snapshot
+func (c *Collector) Report(snap *ClusterSnapshot, names []string) (Report, error) {
+	nodes, err := c.NodesFromSnapshot(snap, names)
+	if err != nil {
+		return Report{}, err
+	}
+	states := make([]*BlockerState, len(nodes))
+	for i, info := range nodes {
+		states[i] = c.BlockerState(snap, info.Node.Name)
+	}
+	return NewReport(nodes, states, snap.CollectedAt), nil
+}
@@ -0,0 +1,139 @@
+package consolidation
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestNewReport(t *testing.T) {
+	controller := true
+	node := func(name string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	blocking := func(name, owner string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "payments",
+			Name:            name,
+			Annotations:     map[string]string{karpenter.AnnotationDoNotDisrupt: "true"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: owner, Controller: &controller}},
+		}}
+	}
+	info := func(name, pool string, cpu, mem int, blockers ...BlockerType) NodeInfo {
+		return NodeInfo{Node: node(name), PoolName: pool, CPUUtilization: cpu, MemoryUtilization: mem, Pods: 2, Blockers: blockers}
+	}
+
+	a := info("a", "default", 10, 20, BlockerDoNotDisrupt)
+	a.HasCost, a.HourlyCost, a.HourlyWaste = true, 0.2, 0.1
+	b := info("b", "default", 30, 100, BlockerDoNotDisrupt, BlockerPDBViolation)
+	c := info("c", "", 95, 5)
+	d := info("d", "batch", 0, 0)
+	d.Empty = true
+	nodes := []NodeInfo{a, b, c, d}
+
+	states := []*BlockerState{
+		NewBlockerState(a.Node, []corev1.Pod{blocking("ledger-0", "ledger")}, nil, nil, Thresholds{}),
+		NewBlockerState(b.Node, []corev1.Pod{blocking("ledger-1", "ledger"), blocking("cache-0", "cache")}, nil, nil, Thresholds{}),
+		nil,
+		nil,
+	}
+
+	collectedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := NewReport(nodes, states, collectedAt)
+
+	if !report.CollectedAt.Equal(collectedAt) || len(report.Nodes) != 4 {
+		t.Errorf("CollectedAt = %v, %d nodes; want %v, 4", report.CollectedAt, len(report.Nodes), collectedAt)
+	}
+	want := PoolSummary{Nodes: 4, Pods: 8, Blocked: 2, Empty: 1, CPUUtilization: 33, MemoryUtilization: 31, Priced: 1, HourlyCost: 0.2, HourlyWaste: 0.1}
+	if report.Totals != want {
+		t.Errorf("Totals = %+v, want %+v", report.Totals, want)
+	}
+
+	var pools []string
+	for _, p := range report.Pools {
+		pools = append(pools, p.Name)
+	}
+	if len(pools) != 3 || pools[0] != "batch" || pools[1] != "default" || pools[2] != "" {
+		t.Errorf("Pools = %q, want batch, default, unmanaged", pools)
+	}
+	if p := report.Pools[1]; p.Nodes != 2 || p.Blocked != 2 || p.CPUUtilization != 20 || p.MemoryUtilization != 60 {
+		t.Errorf("default pool = %+v", p)
+	}
+
+	wantBlockers := []BlockerCount{{BlockerDoNotDisrupt, 2}, {BlockerPDBViolation, 1}}
+	if len(report.Blockers) != len(wantBlockers) {
+		t.Fatalf("Blockers = %+v, want %+v", report.Blockers, wantBlockers)
+	}
+	for i := range wantBlockers {
+		if report.Blockers[i] != wantBlockers[i] {
+			t.Errorf("Blockers[%d] = %+v, want %+v", i, report.Blockers[i], wantBlockers[i])
+		}
+	}
+
+	if report.CPUHistogram[1] != 1 || report.CPUHistogram[3] != 1 || report.CPUHistogram[9] != 1 || report.CPUHistogram[0] != 1 {
+		t.Errorf("CPUHistogram = %v", report.CPUHistogram)
+	}
+	// 100% lands in the last bucket
+	if report.MemoryHistogram[9] != 1 || report.MemoryHistogram[0] != 2 {
+		t.Errorf("MemoryHistogram = %v", report.MemoryHistogram)
+	}
+
+	wantWorkloads := []WorkloadBlock{
+		{Name: "payments/StatefulSet/ledger", Pods: 2, Nodes: 2},
+		{Name: "payments/StatefulSet/cache", Pods: 1, Nodes: 1},
+	}
+	if len(report.Workloads) != len(wantWorkloads) {
+		t.Fatalf("Workloads = %+v, want %+v", report.Workloads, wantWorkloads)
+	}
+	for i := range wantWorkloads {
+		if report.Workloads[i] != wantWorkloads[i] {
+			t.Errorf("Workloads[%d] = %+v, want %+v", i, report.Workloads[i], wantWorkloads[i])
+		}
+	}
+}
+
+func TestSnapshotSaveLoad(t *testing.T) {
+	snap := &ClusterSnapshot{
+		CollectedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Capabilities: &karpenter.ClusterCapabilities{HasNodePools: true, PrimaryVersion: karpenter.APIVersionV1},
+		Nodes: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{
+			Name:   "a",
+			Labels: map[string]string{karpenter.LabelNodePool: "default"},
+		}}},
+		PodsByNode: map[string][]corev1.Pod{"a": {{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "api"}}}},
+		NodePools:  map[string]*karpenter.NodePool{"default": {Name: "default", ConsolidationPolicy: "WhenEmpty"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := snap.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !loaded.CollectedAt.Equal(snap.CollectedAt) || !loaded.Capabilities.HasNodePools {
+		t.Errorf("loaded CollectedAt = %v, Capabilities = %+v", loaded.CollectedAt, loaded.Capabilities)
+	}
+	if len(loaded.PodsByNode["a"]) != 1 || loaded.NodePools["default"].ConsolidationPolicy != "WhenEmpty" {
+		t.Errorf("loaded PodsByNode = %v, NodePools = %v", loaded.PodsByNode, loaded.NodePools)
+	}
+
+	nodes, err := NewCollector(nil, nil, loaded.Capabilities).NodesFromSnapshot(loaded, []string{"a", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].PoolName != "default" || nodes[0].Pods != 1 {
+		t.Errorf("NodesFromSnapshot = %+v, want node a in pool default with 1 pod", nodes)
+	}
+
+	if _, err := LoadSnapshot(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadSnapshot(missing) succeeded, want an error")
+	}
+}
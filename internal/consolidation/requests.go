@@ -0,0 +1,86 @@
+package consolidation
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodRequests computes a pod's effective resource requests the way the scheduler does:
+//
+//   - app containers are summed;
+//   - restartable (sidecar) init containers run for the pod's lifetime, so they are
+//     added to the app containers and to every init container that starts after them;
+//   - regular init containers run one at a time, so only the largest counts, and only
+//     when it exceeds the long-running total;
+//   - pod-level resources, when set, replace the aggregated container requests;
+//   - pod overhead (RuntimeClass) is added on top.
+func PodRequests(pod *corev1.Pod) corev1.ResourceList {
+	reqs := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResourceList(reqs, container.Resources.Requests)
+	}
+
+	restartableInitReqs := corev1.ResourceList{}
+	initReqs := corev1.ResourceList{}
+	for _, container := range pod.Spec.InitContainers {
+		containerReqs := container.Resources.Requests
+
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			// Sidecars keep running alongside app containers and later init containers
+			addResourceList(reqs, containerReqs)
+			addResourceList(restartableInitReqs, containerReqs)
+			containerReqs = restartableInitReqs
+		} else {
+			tmp := corev1.ResourceList{}
+			addResourceList(tmp, containerReqs)
+			addResourceList(tmp, restartableInitReqs)
+			containerReqs = tmp
+		}
+
+		maxResourceList(initReqs, containerReqs)
+	}
+	maxResourceList(reqs, initReqs)
+
+	if pod.Spec.Resources != nil {
+		for name, quantity := range pod.Spec.Resources.Requests {
+			if isSupportedPodLevelResource(name) {
+				reqs[name] = quantity.DeepCopy()
+			}
+		}
+	}
+
+	if pod.Spec.Overhead != nil {
+		addResourceList(reqs, pod.Spec.Overhead)
+	}
+
+	return reqs
+}
+
+// isSupportedPodLevelResource reports whether a resource may be set in pod.spec.resources
+func isSupportedPodLevelResource(name corev1.ResourceName) bool {
+	return name == corev1.ResourceCPU ||
+		name == corev1.ResourceMemory ||
+		strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix)
+}
+
+// addResourceList adds the resources in newList to list
+func addResourceList(list, newList corev1.ResourceList) {
+	for name, quantity := range newList {
+		if value, ok := list[name]; ok {
+			value.Add(quantity)
+			list[name] = value
+		} else {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// maxResourceList sets list to the greater of list/newList for every resource in newList
+func maxResourceList(list, newList corev1.ResourceList) {
+	for name, quantity := range newList {
+		if value, ok := list[name]; !ok || quantity.Cmp(value) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
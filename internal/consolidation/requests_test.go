@@ -0,0 +1,120 @@
+package consolidation
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func container(cpu, mem string) corev1.Container {
+	return corev1.Container{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(mem),
+			},
+		},
+	}
+}
+
+func sidecar(cpu, mem string) corev1.Container {
+	c := container(cpu, mem)
+	always := corev1.ContainerRestartPolicyAlways
+	c.RestartPolicy = &always
+	return c
+}
+
+func TestPodRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        corev1.PodSpec
+		expectedCPU string
+		expectedMem string
+	}{
+		{
+			name: "app containers are summed",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{container("500m", "1Gi"), container("250m", "512Mi")},
+			},
+			expectedCPU: "750m",
+			expectedMem: "1536Mi",
+		},
+		{
+			name: "smaller init container is ignored",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container("100m", "128Mi")},
+				Containers:     []corev1.Container{container("500m", "1Gi")},
+			},
+			expectedCPU: "500m",
+			expectedMem: "1Gi",
+		},
+		{
+			name: "largest init container wins per resource",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container("2", "256Mi"), container("1", "4Gi")},
+				Containers:     []corev1.Container{container("500m", "1Gi")},
+			},
+			expectedCPU: "2",
+			expectedMem: "4Gi",
+		},
+		{
+			name: "sidecar init container is long-running",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{sidecar("200m", "256Mi")},
+				Containers:     []corev1.Container{container("500m", "1Gi")},
+			},
+			expectedCPU: "700m",
+			expectedMem: "1280Mi",
+		},
+		{
+			name: "init container after sidecar includes sidecar",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{sidecar("200m", "256Mi"), container("1", "512Mi")},
+				Containers:     []corev1.Container{container("500m", "1Gi")},
+			},
+			expectedCPU: "1200m",
+			expectedMem: "1280Mi",
+		},
+		{
+			name: "overhead is added",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{container("500m", "1Gi")},
+				Overhead: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("120Mi"),
+				},
+			},
+			expectedCPU: "750m",
+			expectedMem: "1144Mi",
+		},
+		{
+			name: "pod-level resources replace container totals",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{container("500m", "1Gi"), container("500m", "1Gi")},
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("3"),
+					},
+				},
+			},
+			expectedCPU: "3",
+			expectedMem: "2Gi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs := PodRequests(&corev1.Pod{Spec: tt.spec})
+
+			cpu := reqs[corev1.ResourceCPU]
+			if want := resource.MustParse(tt.expectedCPU); cpu.Cmp(want) != 0 {
+				t.Errorf("PodRequests() cpu = %v, want %v", cpu.String(), want.String())
+			}
+			mem := reqs[corev1.ResourceMemory]
+			if want := resource.MustParse(tt.expectedMem); mem.Cmp(want) != 0 {
+				t.Errorf("PodRequests() memory = %v, want %v", mem.String(), want.String())
+			}
+		})
+	}
+}
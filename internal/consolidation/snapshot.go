@@ -0,0 +1,167 @@
+package consolidation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// ClusterSnapshot is the cluster state a scheduling simulation runs against
+type ClusterSnapshot struct {
+	CollectedAt  time.Time
+	Capabilities *karpenter.ClusterCapabilities // Karpenter APIs the cluster served
+	Nodes        []corev1.Node
+	PodsByNode   map[string][]corev1.Pod
+	PVCs         []corev1.PersistentVolumeClaim
+	PVs          []corev1.PersistentVolume
+	CSINodes     []storagev1.CSINode
+	NodeClaims   map[string]*karpenter.NodeClaim // Keyed by node name; nil if unavailable
+	NodePools    map[string]*karpenter.NodePool  // Keyed by name; nil if unavailable
+	// Events and PDBs are only read for blocker detection
+	EventsByNode map[string][]corev1.Event
+	PDBs         []policyv1.PodDisruptionBudget
+}
+
+// CollectSnapshot reads every node and pod, plus the volume and Karpenter
+// resources that placement depends on and the events and PDBs that blocker
+// detection depends on. Everything but nodes and pods is optional: the checks
+// that need it are skipped when it cannot be read.
+func (c *Collector) CollectSnapshot(ctx context.Context) (*ClusterSnapshot, error) {
+	snap := &ClusterSnapshot{CollectedAt: time.Now(), Capabilities: c.capabilities}
+	var nodeErr, podErr error
+
+	var wg sync.WaitGroup
+	wg.Add(5)
+	go func() {
+		defer wg.Done()
+		snap.Nodes, nodeErr = FetchNodes(ctx, c.client, nil, "")
+	}()
+	go func() {
+		defer wg.Done()
+		snap.PodsByNode, podErr = FetchAllPods(ctx, c.client)
+	}()
+	go func() {
+		defer wg.Done()
+		c.fetchVolumes(ctx, snap)
+	}()
+	go func() {
+		defer wg.Done()
+		snap.NodeClaims, snap.NodePools, _ = c.fetchKarpenterResources(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		snap.EventsByNode, _ = FetchAllNodeEvents(ctx, c.client)
+		snap.PDBs, _ = FetchAllPDBs(ctx, c.client)
+	}()
+	wg.Wait()
+
+	if nodeErr != nil {
+		return nil, nodeErr
+	}
+	if podErr != nil {
+		return nil, podErr
+	}
+	return snap, nil
+}
+
+// Save writes the snapshot as JSON so it can be loaded later with LoadSnapshot
+func (s *ClusterSnapshot) Save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadSnapshot reads a snapshot written by Save
+func LoadSnapshot(path string) (*ClusterSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap ClusterSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+	if snap.Capabilities == nil {
+		snap.Capabilities = &karpenter.ClusterCapabilities{}
+	}
+	return &snap, nil
+}
+
+// fetchVolumes fills in PVCs, PVs and CSINodes, leaving them empty on error
+func (c *Collector) fetchVolumes(ctx context.Context, snap *ClusterSnapshot) {
+	if pvcs, err := c.client.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{}); err == nil {
+		snap.PVCs = pvcs.Items
+	}
+	if pvs, err := c.client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{}); err == nil {
+		snap.PVs = pvs.Items
+	}
+	if csiNodes, err := c.client.StorageV1().CSINodes().List(ctx, metav1.ListOptions{}); err == nil {
+		snap.CSINodes = csiNodes.Items
+	}
+}
+
+// BlockerState returns the named node's blocker state using the collector's
+// thresholds, or nil if the node is not in the snapshot
+func (c *Collector) BlockerState(snap *ClusterSnapshot, nodeName string) *BlockerState {
+	for i := range snap.Nodes {
+		node := &snap.Nodes[i]
+		if node.Name != nodeName {
+			continue
+		}
+		poolName, _ := karpenter.GetPoolName(node)
+		thresholds := c.thresholds.Resolve(poolName, snap.NodePools[poolName])
+		return NewBlockerState(node, snap.PodsByNode[nodeName], snap.EventsByNode[nodeName], snap.PDBs, thresholds)
+	}
+	return nil
+}
+
+// SelectNodes returns the names of nodes matching names and selector. With neither,
+// it returns the nodes managed by a NodePool or Provisioner, or every node if
+// Karpenter manages none.
+func (s *ClusterSnapshot) SelectNodes(names []string, selector string) ([]string, error) {
+	sel := labels.Everything()
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return nil, err
+		}
+		sel = parsed
+	}
+	nameSet := make(map[string]bool, len(names))
+	for _, name := range names {
+		nameSet[name] = true
+	}
+
+	var selected, managed []string
+	for i := range s.Nodes {
+		node := &s.Nodes[i]
+		if len(nameSet) > 0 && !nameSet[node.Name] {
+			continue
+		}
+		if !sel.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		selected = append(selected, node.Name)
+		if poolName, _ := karpenter.GetPoolName(node); poolName != "" {
+			managed = append(managed, node.Name)
+		}
+	}
+
+	if len(nameSet) == 0 && selector == "" && len(managed) > 0 {
+		return managed, nil
+	}
+	return selected, nil
+}
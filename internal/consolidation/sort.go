@@ -0,0 +1,122 @@
+package consolidation
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// sortField orders by one field. has reports whether an item has a value;
+// items without one sort last in either order. It is nil when every item has one.
+type sortField[T any] struct {
+	has     func(T) bool
+	compare func(a, b T) int
+}
+
+var nodeSortFields = map[string]sortField[NodeInfo]{
+	"name": {compare: compareNodeNames},
+	"cpu": {compare: func(a, b NodeInfo) int {
+		return cmp.Compare(a.CPUUtilization, b.CPUUtilization)
+	}},
+	"memory": {compare: func(a, b NodeInfo) int {
+		return cmp.Compare(a.MemoryUtilization, b.MemoryUtilization)
+	}},
+	// Youngest first, like the AGE column read top to bottom
+	"age": {compare: func(a, b NodeInfo) int {
+		return b.Node.CreationTimestamp.Compare(a.Node.CreationTimestamp.Time)
+	}},
+	"pool": {
+		has:     func(n NodeInfo) bool { return n.PoolName != "" },
+		compare: func(a, b NodeInfo) int { return strings.Compare(a.PoolName, b.PoolName) },
+	},
+	"blocker-count": {compare: func(a, b NodeInfo) int {
+		return cmp.Compare(len(a.Blockers), len(b.Blockers))
+	}},
+	"cost": {
+		has:     func(n NodeInfo) bool { return n.HasCost },
+		compare: func(a, b NodeInfo) int { return cmp.Compare(a.HourlyCost, b.HourlyCost) },
+	},
+	// Shortest first
+	"empty-duration": {
+		has:     func(n NodeInfo) bool { return n.Empty },
+		compare: func(a, b NodeInfo) int { return b.EmptySince.Compare(a.EmptySince) },
+	},
+}
+
+var podBlockerSortFields = map[string]sortField[PodBlocker]{
+	"name": {compare: comparePodNames},
+	"node": {compare: func(a, b PodBlocker) int { return strings.Compare(a.NodeName, b.NodeName) }},
+	"namespace": {compare: func(a, b PodBlocker) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	}},
+	// Youngest first
+	"age": {compare: func(a, b PodBlocker) int { return b.CreatedAt.Compare(a.CreatedAt) }},
+	"reason": {compare: func(a, b PodBlocker) int {
+		return strings.Compare(string(a.Reason), string(b.Reason))
+	}},
+}
+
+// NodeSortFields are the fields SortNodes accepts
+func NodeSortFields() []string {
+	return slices.Sorted(maps.Keys(nodeSortFields))
+}
+
+// PodBlockerSortFields are the fields SortPodBlockers accepts
+func PodBlockerSortFields() []string {
+	return slices.Sorted(maps.Keys(podBlockerSortFields))
+}
+
+// SortNodes orders nodes by a field from NodeSortFields, then by name. Nodes
+// without a value for the field, such as unpriced nodes when sorting by cost,
+// come last in either order.
+func SortNodes(nodes []NodeInfo, field string, descending bool) error {
+	f, ok := nodeSortFields[field]
+	if !ok {
+		return fmt.Errorf("unknown node sort field %q, want one of %s", field, strings.Join(NodeSortFields(), ", "))
+	}
+	sortBy(nodes, f, descending, compareNodeNames)
+	return nil
+}
+
+// SortPodBlockers orders pod blockers by a field from PodBlockerSortFields,
+// then by namespace and pod name
+func SortPodBlockers(blockers []PodBlocker, field string, descending bool) error {
+	f, ok := podBlockerSortFields[field]
+	if !ok {
+		return fmt.Errorf("unknown pod sort field %q, want one of %s", field, strings.Join(PodBlockerSortFields(), ", "))
+	}
+	sortBy(blockers, f, descending, comparePodNames)
+	return nil
+}
+
+// sortBy stable-sorts items by f, breaking ties by name in ascending order
+func sortBy[T any](items []T, f sortField[T], descending bool, name func(a, b T) int) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		if f.has != nil {
+			if hasA, hasB := f.has(a), f.has(b); hasA != hasB {
+				if hasA {
+					return -1
+				}
+				return 1
+			}
+		}
+		c := f.compare(a, b)
+		if descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+		return name(a, b)
+	})
+}
+
+func compareNodeNames(a, b NodeInfo) int {
+	return strings.Compare(a.Node.Name, b.Node.Name)
+}
+
+func comparePodNames(a, b PodBlocker) int {
+	return cmp.Or(strings.Compare(a.Namespace, b.Namespace), strings.Compare(a.PodName, b.PodName))
+}
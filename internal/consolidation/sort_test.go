@@ -0,0 +1,107 @@
+package consolidation
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSortNodes(t *testing.T) {
+	now := time.Now()
+	info := func(name string, cpu int, age time.Duration, blockers int) NodeInfo {
+		return NodeInfo{
+			Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			}},
+			CPUUtilization: cpu,
+			Blockers:       make([]BlockerType, blockers),
+		}
+	}
+	a := info("a", 50, time.Hour, 0)
+	b := info("b", 20, 3*time.Hour, 2)
+	c := info("c", 50, 2*time.Hour, 1)
+	b.HasCost, b.HourlyCost = true, 0.4
+	c.HasCost, c.HourlyCost = true, 0.1
+	a.Empty, a.EmptySince = true, now.Add(-time.Minute)
+	c.Empty, c.EmptySince = true, now.Add(-time.Hour)
+	b.PoolName, c.PoolName = "default", "batch"
+
+	tests := []struct {
+		field      string
+		descending bool
+		want       []string
+	}{
+		{field: "name", want: []string{"a", "b", "c"}},
+		{field: "name", descending: true, want: []string{"c", "b", "a"}},
+		{field: "cpu", want: []string{"b", "a", "c"}},
+		// Ties stay in name order when descending
+		{field: "cpu", descending: true, want: []string{"a", "c", "b"}},
+		{field: "age", want: []string{"a", "c", "b"}},
+		{field: "blocker-count", descending: true, want: []string{"b", "c", "a"}},
+		// Nodes without a value come last in either order
+		{field: "cost", want: []string{"c", "b", "a"}},
+		{field: "cost", descending: true, want: []string{"b", "c", "a"}},
+		{field: "pool", want: []string{"c", "b", "a"}},
+		{field: "empty-duration", descending: true, want: []string{"c", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			nodes := []NodeInfo{c, a, b}
+			if err := SortNodes(nodes, tt.field, tt.descending); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, n := range nodes {
+				got = append(got, n.Node.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SortNodes(%s, descending=%v) = %v, want %v", tt.field, tt.descending, got, tt.want)
+			}
+		})
+	}
+
+	if err := SortNodes(nil, "zone", false); err == nil {
+		t.Error("SortNodes(zone) succeeded, want an unknown field error")
+	}
+}
+
+func TestSortPodBlockers(t *testing.T) {
+	now := time.Now()
+	blockers := []PodBlocker{
+		{NodeName: "n2", Namespace: "web", PodName: "api", CreatedAt: now.Add(-time.Hour), Reason: BlockerDoNotDisrupt},
+		{NodeName: "n1", Namespace: "payments", PodName: "ledger", CreatedAt: now.Add(-time.Hour), Reason: BlockerDoNotEvict},
+		{NodeName: "n1", Namespace: "payments", PodName: "api", CreatedAt: now.Add(-time.Minute), Reason: BlockerDoNotDisrupt},
+	}
+
+	tests := []struct {
+		field      string
+		descending bool
+		want       []string
+	}{
+		{field: "name", want: []string{"payments/api", "payments/ledger", "web/api"}},
+		{field: "node", descending: true, want: []string{"web/api", "payments/api", "payments/ledger"}},
+		{field: "age", want: []string{"payments/api", "payments/ledger", "web/api"}},
+		{field: "reason", want: []string{"payments/api", "web/api", "payments/ledger"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			sorted := slices.Clone(blockers)
+			if err := SortPodBlockers(sorted, tt.field, tt.descending); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, b := range sorted {
+				got = append(got, b.Namespace+"/"+b.PodName)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SortPodBlockers(%s, descending=%v) = %v, want %v", tt.field, tt.descending, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package consolidation
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// AnnotationUtilizationThresholds lets a NodePool override high-utilization thresholds,
+// e.g. "cpu=95,memory=90" or "default=95"
+const AnnotationUtilizationThresholds = "kubectl-consolidation/utilization-thresholds"
+
+// ThresholdDefaultKey sets the threshold for resources without an explicit entry
+const ThresholdDefaultKey = "default"
+
+// ThresholdSource identifies which rule set a threshold
+type ThresholdSource string
+
+const (
+	ThresholdSourceDefault            ThresholdSource = "default"
+	ThresholdSourceConfig             ThresholdSource = "config"
+	ThresholdSourceFlag               ThresholdSource = "flag"
+	ThresholdSourceNodePoolConfig     ThresholdSource = "nodepool-config"
+	ThresholdSourceNodePoolAnnotation ThresholdSource = "nodepool-annotation"
+)
+
+// Threshold is a high-utilization percentage and the rule it came from
+type Threshold struct {
+	Percent int
+	Source  ThresholdSource
+}
+
+// Thresholds holds the effective high-utilization threshold for each resource
+type Thresholds struct {
+	Default   Threshold
+	Resources map[corev1.ResourceName]Threshold
+}
+
+// DefaultThresholds applies HighUtilizationThreshold to every resource
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		Default: Threshold{Percent: HighUtilizationThreshold, Source: ThresholdSourceDefault},
+	}
+}
+
+// For returns the threshold that applies to a resource
+func (t Thresholds) For(name corev1.ResourceName) Threshold {
+	if threshold, ok := t.Resources[name]; ok {
+		return threshold
+	}
+	return t.Default
+}
+
+// With returns a copy of t overlaid with "resource: percent" values from source.
+// The "default" key replaces the fallback threshold.
+func (t Thresholds) With(values map[string]int, source ThresholdSource) Thresholds {
+	out := Thresholds{
+		Default:   t.Default,
+		Resources: make(map[corev1.ResourceName]Threshold, len(t.Resources)+len(values)),
+	}
+	for name, threshold := range t.Resources {
+		out.Resources[name] = threshold
+	}
+	for key, percent := range values {
+		threshold := Threshold{Percent: percent, Source: source}
+		if key == ThresholdDefaultKey {
+			out.Default = threshold
+			continue
+		}
+		out.Resources[corev1.ResourceName(key)] = threshold
+	}
+	return out
+}
+
+// ThresholdConfig holds threshold rules from every source. Precedence, lowest first:
+// built-in default, cluster-wide config file entries, NodePool entries in the
+// config file, NodePool annotation, --threshold flags.
+type ThresholdConfig struct {
+	Cluster   map[string]int
+	Flags     map[string]int
+	NodePools map[string]map[string]int
+
+	warnings io.Writer
+	mu       sync.Mutex
+	warned   map[string]bool // NodePools whose invalid annotation was reported
+}
+
+// SetWarningOutput sets where invalid NodePool annotations are reported.
+// Without it they are ignored silently.
+func (c *ThresholdConfig) SetWarningOutput(w io.Writer) {
+	c.warnings = w
+}
+
+// Resolve returns the effective thresholds for a node in the named pool.
+// pool may be nil when the NodePool could not be read.
+func (c *ThresholdConfig) Resolve(poolName string, pool *karpenter.NodePool) Thresholds {
+	t := DefaultThresholds()
+	if c == nil {
+		return t
+	}
+
+	t = t.With(c.Cluster, ThresholdSourceConfig)
+	t = t.With(c.NodePools[poolName], ThresholdSourceNodePoolConfig)
+
+	if pool != nil {
+		if spec, ok := pool.Annotations[AnnotationUtilizationThresholds]; ok {
+			// An invalid annotation is reported but does not fail the whole report
+			values, err := ParseThresholdSpec(spec)
+			if err != nil {
+				c.warnInvalidAnnotation(pool.Name, err)
+			} else {
+				t = t.With(values, ThresholdSourceNodePoolAnnotation)
+			}
+		}
+	}
+
+	return t.With(c.Flags, ThresholdSourceFlag)
+}
+
+// warnInvalidAnnotation reports an invalid threshold annotation once per NodePool
+func (c *ThresholdConfig) warnInvalidAnnotation(poolName string, err error) {
+	if c.warnings == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.warned[poolName] {
+		return
+	}
+	if c.warned == nil {
+		c.warned = make(map[string]bool)
+	}
+	c.warned[poolName] = true
+	_, _ = fmt.Fprintf(c.warnings, "Warning: ignoring %s annotation on NodePool %s: %v\n", AnnotationUtilizationThresholds, poolName, err)
+}
+
+// ParseThresholdSpec parses "resource=percent" pairs such as "cpu=95,memory=90"
+func ParseThresholdSpec(spec string) (map[string]int, error) {
+	values := make(map[string]int)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid threshold %q: expected resource=percent", pair)
+		}
+		percent, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q: %w", pair, err)
+		}
+		values[strings.TrimSpace(key)] = percent
+	}
+	if err := ValidateThresholds(values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ValidateThresholds checks that every percentage is between 1 and 100
+func ValidateThresholds(values map[string]int) error {
+	for key, percent := range values {
+		if percent < 1 || percent > 100 {
+			return fmt.Errorf("invalid threshold for %s: %d is not between 1 and 100", key, percent)
+		}
+	}
+	return nil
+}
+
+// thresholdFile is the on-disk format read by LoadThresholdConfig:
+//
+//	thresholds:
+//	  default: 80
+//	  nvidia.com/gpu: 100
+//	nodePools:
+//	  batch:
+//	    thresholds:
+//	      default: 95
+type thresholdFile struct {
+	Thresholds map[string]int `yaml:"thresholds"`
+	NodePools  map[string]struct {
+		Thresholds map[string]int `yaml:"thresholds"`
+	} `yaml:"nodePools"`
+}
+
+// LoadThresholdConfig reads cluster-wide and per-NodePool thresholds from a YAML file
+func LoadThresholdConfig(path string) (*ThresholdConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file thresholdFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	cfg := &ThresholdConfig{
+		Cluster:   file.Thresholds,
+		NodePools: make(map[string]map[string]int, len(file.NodePools)),
+	}
+	if err := ValidateThresholds(cfg.Cluster); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, pool := range file.NodePools {
+		if err := ValidateThresholds(pool.Thresholds); err != nil {
+			return nil, fmt.Errorf("%s: nodePool %s: %w", path, name, err)
+		}
+		cfg.NodePools[name] = pool.Thresholds
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,182 @@
+package consolidation
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestThresholdConfigResolve(t *testing.T) {
+	cfg := &ThresholdConfig{
+		Cluster:   map[string]int{"default": 85, "nvidia.com/gpu": 100},
+		Flags:     map[string]int{"cpu": 90},
+		NodePools: map[string]map[string]int{"batch": {"cpu": 95, "memory": 70}},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *ThresholdConfig
+		poolName string
+		pool     *karpenter.NodePool
+		resource corev1.ResourceName
+		expected Threshold
+	}{
+		{
+			name:     "nil config uses built-in default",
+			cfg:      nil,
+			resource: corev1.ResourceCPU,
+			expected: Threshold{Percent: HighUtilizationThreshold, Source: ThresholdSourceDefault},
+		},
+		{
+			name:     "config default",
+			cfg:      cfg,
+			resource: corev1.ResourceMemory,
+			expected: Threshold{Percent: 85, Source: ThresholdSourceConfig},
+		},
+		{
+			name:     "config per resource",
+			cfg:      cfg,
+			resource: "nvidia.com/gpu",
+			expected: Threshold{Percent: 100, Source: ThresholdSourceConfig},
+		},
+		{
+			name:     "flag overrides config",
+			cfg:      cfg,
+			resource: corev1.ResourceCPU,
+			expected: Threshold{Percent: 90, Source: ThresholdSourceFlag},
+		},
+		{
+			name:     "flag overrides nodepool config",
+			cfg:      cfg,
+			poolName: "batch",
+			resource: corev1.ResourceCPU,
+			expected: Threshold{Percent: 90, Source: ThresholdSourceFlag},
+		},
+		{
+			name:     "nodepool config overrides config",
+			cfg:      cfg,
+			poolName: "batch",
+			resource: corev1.ResourceMemory,
+			expected: Threshold{Percent: 70, Source: ThresholdSourceNodePoolConfig},
+		},
+		{
+			name:     "nodepool annotation overrides config",
+			cfg:      cfg,
+			poolName: "batch",
+			pool: &karpenter.NodePool{
+				Name:        "batch",
+				Annotations: map[string]string{AnnotationUtilizationThresholds: "cpu=99,memory=97"},
+			},
+			resource: corev1.ResourceMemory,
+			expected: Threshold{Percent: 97, Source: ThresholdSourceNodePoolAnnotation},
+		},
+		{
+			name:     "flag overrides nodepool annotation",
+			cfg:      cfg,
+			poolName: "batch",
+			pool: &karpenter.NodePool{
+				Name:        "batch",
+				Annotations: map[string]string{AnnotationUtilizationThresholds: "cpu=99,memory=97"},
+			},
+			resource: corev1.ResourceCPU,
+			expected: Threshold{Percent: 90, Source: ThresholdSourceFlag},
+		},
+		{
+			name:     "invalid annotation is ignored",
+			cfg:      cfg,
+			poolName: "batch",
+			pool: &karpenter.NodePool{
+				Name:        "batch",
+				Annotations: map[string]string{AnnotationUtilizationThresholds: "memory=lots"},
+			},
+			resource: corev1.ResourceMemory,
+			expected: Threshold{Percent: 70, Source: ThresholdSourceNodePoolConfig},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cfg.Resolve(tt.poolName, tt.pool).For(tt.resource)
+			if got != tt.expected {
+				t.Errorf("Resolve().For(%s) = %+v, want %+v", tt.resource, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestThresholdConfigWarnsOnInvalidAnnotation(t *testing.T) {
+	var warnings bytes.Buffer
+	cfg := &ThresholdConfig{}
+	cfg.SetWarningOutput(&warnings)
+	pool := &karpenter.NodePool{
+		Name:        "batch",
+		Annotations: map[string]string{AnnotationUtilizationThresholds: "cpu=lots"},
+	}
+
+	cfg.Resolve("batch", pool)
+	cfg.Resolve("batch", pool)
+
+	want := "Warning: ignoring kubectl-consolidation/utilization-thresholds annotation on NodePool batch: invalid threshold \"cpu=lots\""
+	if !strings.HasPrefix(warnings.String(), want) || strings.Count(warnings.String(), "\n") != 1 {
+		t.Errorf("warnings = %q, want one line starting %q", warnings.String(), want)
+	}
+}
+
+func TestParseThresholdSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[string]int
+		wantErr bool
+	}{
+		{spec: "cpu=95, memory=90%", want: map[string]int{"cpu": 95, "memory": 90}},
+		{spec: "default=100", want: map[string]int{"default": 100}},
+		{spec: "cpu", wantErr: true},
+		{spec: "cpu=0", wantErr: true},
+		{spec: "cpu=101", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseThresholdSpec(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThresholdSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("ParseThresholdSpec(%q)[%s] = %d, want %d", tt.spec, key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadThresholdConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `thresholds:
+  default: 85
+nodePools:
+  batch:
+    thresholds:
+      cpu: 95
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadThresholdConfig(path)
+	if err != nil {
+		t.Fatalf("LoadThresholdConfig() error = %v", err)
+	}
+	if cfg.Cluster["default"] != 85 {
+		t.Errorf("Cluster[default] = %d, want 85", cfg.Cluster["default"])
+	}
+	if cfg.NodePools["batch"]["cpu"] != 95 {
+		t.Errorf("NodePools[batch][cpu] = %d, want 95", cfg.NodePools["batch"]["cpu"])
+	}
+}
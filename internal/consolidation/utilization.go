@@ -1,64 +1,102 @@
 package consolidation
 
 import (
+	"sort"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
-// CalculateUtilization computes CPU and memory utilization based on pod requests.
-// Returns percentages relative to node allocatable resources.
+// CalculateUtilization computes CPU and memory utilization based on effective pod
+// requests (see PodRequests). Returns percentages relative to node allocatable resources.
 func CalculateUtilization(node *corev1.Node, pods []corev1.Pod) (cpuPercent, memPercent int) {
 	allocatable := node.Status.Allocatable
-	if allocatable == nil {
+	if allocatable == nil || allocatable.Cpu().IsZero() || allocatable.Memory().IsZero() {
 		return 0, 0
 	}
 
-	allocatableCPU := allocatable.Cpu()
-	allocatableMem := allocatable.Memory()
+	util := CalculateResourceUtilization(node, pods)
+	return util[corev1.ResourceCPU], util[corev1.ResourceMemory]
+}
 
-	if allocatableCPU.IsZero() || allocatableMem.IsZero() {
-		return 0, 0
+// CalculateResourceUtilization computes utilization for every resource in the node's
+// allocatable: CPU, memory, ephemeral storage, hugepages, GPUs and other extended
+// resources are based on effective pod requests, and "pods" on the number of
+// non-terminal pods against the node's pod capacity. Resources with zero
+// allocatable are omitted.
+func CalculateResourceUtilization(node *corev1.Node, pods []corev1.Pod) map[corev1.ResourceName]int {
+	allocatable := node.Status.Allocatable
+	util := make(map[corev1.ResourceName]int, len(allocatable))
+	if allocatable == nil {
+		return util
 	}
 
-	var totalCPURequests, totalMemRequests resource.Quantity
-
-	for _, pod := range pods {
+	requested := corev1.ResourceList{}
+	podCount := int64(0)
+	for i := range pods {
+		pod := &pods[i]
 		// Skip completed or failed pods
-		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		if isTerminal(pod) {
 			continue
 		}
+		podCount++
+		addResourceList(requested, PodRequests(pod))
+	}
+	requested[corev1.ResourcePods] = *resource.NewQuantity(podCount, resource.DecimalSI)
 
-		for _, container := range pod.Spec.Containers {
-			if container.Resources.Requests != nil {
-				if cpu, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-					totalCPURequests.Add(cpu)
-				}
-				if mem, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-					totalMemRequests.Add(mem)
-				}
-			}
+	for name, total := range allocatable {
+		if total.IsZero() {
+			continue
 		}
+		util[name] = calculatePercentage(requested[name], total)
+	}
+
+	return util
+}
+
+// IsHighUtilization reports whether any resource meets its high-utilization threshold
+func IsHighUtilization(util map[corev1.ResourceName]int, thresholds Thresholds) bool {
+	return len(HighUtilizationResources(util, thresholds)) > 0
+}
 
-		// Also count init containers (they run before main containers)
-		for _, container := range pod.Spec.InitContainers {
-			if container.Resources.Requests != nil {
-				if cpu, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-					totalCPURequests.Add(cpu)
-				}
-				if mem, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-					totalMemRequests.Add(mem)
-				}
-			}
+// HighUtilizationResources returns the resources at or above their high-utilization
+// threshold, sorted by name
+func HighUtilizationResources(util map[corev1.ResourceName]int, thresholds Thresholds) []corev1.ResourceName {
+	var high []corev1.ResourceName
+	for name, percent := range util {
+		if percent >= thresholds.For(name).Percent {
+			high = append(high, name)
 		}
 	}
+	sort.Slice(high, func(i, j int) bool { return high[i] < high[j] })
+	return high
+}
 
-	// Calculate percentages
-	cpuPercent = calculatePercentage(totalCPURequests, *allocatableCPU)
-	memPercent = calculatePercentage(totalMemRequests, *allocatableMem)
-
-	return cpuPercent, memPercent
+// SortedResourceNames returns the resources in util with cpu and memory first,
+// followed by the rest alphabetically
+func SortedResourceNames(util map[corev1.ResourceName]int) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(util))
+	for name := range util {
+		names = append(names, name)
+	}
+	rank := func(name corev1.ResourceName) int {
+		switch name {
+		case corev1.ResourceCPU:
+			return 0
+		case corev1.ResourceMemory:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if rank(names[i]) != rank(names[j]) {
+			return rank(names[i]) < rank(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
 }
 
 func calculatePercentage(used, total resource.Quantity) int {
@@ -94,8 +132,11 @@ func formatInt(n int) string {
 
 // FormatAge formats a duration as a human-readable age string
 func FormatAge(t time.Time) string {
-	d := time.Since(t)
+	return FormatDuration(time.Since(t))
+}
 
+// FormatDuration formats a duration using the same units as FormatAge
+func FormatDuration(d time.Duration) string {
 	if d < time.Minute {
 		return formatInt(int(d.Seconds())) + "s"
 	}
@@ -175,6 +175,48 @@ func TestCalculateUtilization(t *testing.T) {
 			expectedCPU:    50,
 			expectedMemory: 50,
 		},
+		{
+			name: "heavy init container is not added to app containers",
+			node: &corev1.Node{
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("8Gi"),
+					},
+				},
+			},
+			pods: []corev1.Pod{
+				{
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+					Spec: corev1.PodSpec{
+						InitContainers: []corev1.Container{
+							{
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("2"),
+										corev1.ResourceMemory: resource.MustParse("2Gi"),
+									},
+								},
+							},
+						},
+						Containers: []corev1.Container{
+							{
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("1"),
+										corev1.ResourceMemory: resource.MustParse("4Gi"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedCPU:    50,
+			expectedMemory: 50,
+		},
 	}
 
 	for _, tt := range tests {
@@ -190,6 +232,65 @@ func TestCalculateUtilization(t *testing.T) {
 	}
 }
 
+func TestCalculateResourceUtilization(t *testing.T) {
+	node := &corev1.Node{
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("8"),
+				corev1.ResourceMemory:           resource.MustParse("32Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+				corev1.ResourcePods:             resource.MustParse("10"),
+				"nvidia.com/gpu":                resource.MustParse("4"),
+				"hugepages-2Mi":                 resource.MustParse("0"),
+			},
+		},
+	}
+
+	gpuPod := func(phase corev1.PodPhase, gpus string) corev1.Pod {
+		return corev1.Pod{
+			Status: corev1.PodStatus{Phase: phase},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:              resource.MustParse("1"),
+								corev1.ResourceMemory:           resource.MustParse("4Gi"),
+								corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+								"nvidia.com/gpu":                resource.MustParse(gpus),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	pods := []corev1.Pod{
+		gpuPod(corev1.PodRunning, "2"),
+		gpuPod(corev1.PodRunning, "1"),
+		gpuPod(corev1.PodSucceeded, "1"),
+	}
+
+	expected := map[corev1.ResourceName]int{
+		corev1.ResourceCPU:              25,
+		corev1.ResourceMemory:           25,
+		corev1.ResourceEphemeralStorage: 20,
+		corev1.ResourcePods:             20,
+		"nvidia.com/gpu":                75,
+	}
+
+	got := CalculateResourceUtilization(node, pods)
+	if len(got) != len(expected) {
+		t.Errorf("CalculateResourceUtilization() returned %v, want %v", got, expected)
+	}
+	for name, want := range expected {
+		if got[name] != want {
+			t.Errorf("CalculateResourceUtilization()[%s] = %d, want %d", name, got[name], want)
+		}
+	}
+}
+
 func TestFormatUtilization(t *testing.T) {
 	tests := []struct {
 		percent  int
@@ -0,0 +1,263 @@
+package consolidation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// KnownBlockers lists every blocker type, in the order they are documented
+var KnownBlockers = []BlockerType{
+	BlockerHighUtilization,
+	BlockerDoNotEvict,
+	BlockerDoNotDisrupt,
+	BlockerDoNotConsolidate,
+	BlockerPDBViolation,
+	BlockerNonReplicated,
+	BlockerWouldIncreaseCost,
+	BlockerInUseSecurityGroup,
+	BlockerOnDemandProtection,
+	BlockerLocalStorage,
+}
+
+// blockerAnnotations maps annotation blockers to the annotation that causes them
+var blockerAnnotations = map[BlockerType]string{
+	BlockerDoNotEvict:       karpenter.AnnotationDoNotEvict,
+	BlockerDoNotDisrupt:     karpenter.AnnotationDoNotDisrupt,
+	BlockerDoNotConsolidate: karpenter.AnnotationDoNotConsolidate,
+}
+
+// BlockerRemoval is a hypothetical removal of a blocker, optionally limited to
+// the pods (or, for pdb-violation, the PDBs) in its scope
+type BlockerRemoval struct {
+	Blocker BlockerType
+	Scope   string // As given; empty means everywhere
+
+	namespace string          // Set for a namespace/name scope
+	name      string          // "*" matches the whole namespace
+	selector  labels.Selector // Set for a label selector scope
+}
+
+// ParseBlockerRemoval parses <blocker>[=<scope>], where scope is a pod (or PDB)
+// as namespace/name, every pod in a namespace as namespace/*, or a pod label
+// selector
+func ParseBlockerRemoval(s string) (BlockerRemoval, error) {
+	blocker, scope, _ := strings.Cut(s, "=")
+	r := BlockerRemoval{Blocker: BlockerType(strings.TrimSpace(blocker)), Scope: strings.TrimSpace(scope)}
+
+	known := false
+	for _, b := range KnownBlockers {
+		known = known || b == r.Blocker
+	}
+	if !known {
+		return r, fmt.Errorf("unknown blocker %q (valid: %s)", r.Blocker, FormatBlockers(KnownBlockers))
+	}
+
+	switch {
+	case r.Scope == "":
+	case r.Blocker == BlockerHighUtilization:
+		return r, fmt.Errorf("%s cannot be limited to a scope", r.Blocker)
+	case isObjectReference(r.Scope):
+		r.namespace, r.name, _ = strings.Cut(r.Scope, "/")
+	default:
+		selector, err := labels.Parse(r.Scope)
+		if err != nil {
+			return r, fmt.Errorf("invalid scope %q: %w", r.Scope, err)
+		}
+		r.selector = selector
+	}
+	return r, nil
+}
+
+// isObjectReference reports whether a scope is namespace/name rather than a selector
+func isObjectReference(scope string) bool {
+	namespace, name, found := strings.Cut(scope, "/")
+	return found && namespace != "" && name != "" && !strings.ContainsAny(scope, "=!(), ")
+}
+
+func (r BlockerRemoval) String() string {
+	if r.Scope == "" {
+		return string(r.Blocker)
+	}
+	return string(r.Blocker) + "=" + r.Scope
+}
+
+// matchesPod reports whether the pod is in the removal's scope
+func (r BlockerRemoval) matchesPod(pod *corev1.Pod) bool {
+	switch {
+	case r.selector != nil:
+		return r.selector.Matches(labels.Set(pod.Labels))
+	case r.namespace != "":
+		return pod.Namespace == r.namespace && (r.name == "*" || pod.Name == r.name)
+	default:
+		return true
+	}
+}
+
+// matchesPDBBlock reports whether a PDB block is in scope: either the pod
+// matches or the scope names the PDB itself
+func (r BlockerRemoval) matchesPDBBlock(block PDBBlock) bool {
+	return r.namesPDB(block.PDB.Namespace+"/"+block.PDB.Name) || r.matchesPod(block.Pod)
+}
+
+// namesPDB reports whether the scope is the namespace/name of the PDB
+func (r BlockerRemoval) namesPDB(pdb string) bool {
+	return r.namespace != "" && r.name != "*" && pdb == r.namespace+"/"+r.name
+}
+
+// BlockerState is the part of a node's state that blocker detection looks at.
+// Blockers is the one definition of a node's blockers, shared by the node
+// table, what-if removals, drain plans and cost attribution.
+type BlockerState struct {
+	Node        *corev1.Node
+	Pods        []corev1.Pod
+	Events      []corev1.Event
+	PDBs        []policyv1.PodDisruptionBudget
+	Utilization map[corev1.ResourceName]int // Movable workload
+	Thresholds  Thresholds
+
+	ignored    map[BlockerType]bool // Node-level blockers removed everywhere
+	pdbRemoved []BlockerRemoval     // PDB blocks in these scopes no longer count
+}
+
+// NewBlockerState builds a node's blocker state, computing utilization from the pods
+func NewBlockerState(node *corev1.Node, pods []corev1.Pod, events []corev1.Event, pdbs []policyv1.PodDisruptionBudget, thresholds Thresholds) *BlockerState {
+	movable, _ := SplitPods(pods)
+	return &BlockerState{
+		Node:        node,
+		Pods:        pods,
+		Events:      events,
+		PDBs:        pdbs,
+		Utilization: CalculateResourceUtilization(node, movable),
+		Thresholds:  thresholds,
+	}
+}
+
+// Blockers runs DetectBlockers and adds the node annotation and exhausted-PDB
+// blockers, sorted by name
+func (s *BlockerState) Blockers() []BlockerType {
+	set := make(map[BlockerType]bool)
+	for _, b := range DetectBlockers(s.Pods, s.Events, s.Utilization, s.Thresholds, BuildPodNameSet(s.Pods)) {
+		set[b] = true
+	}
+	if b, found := DetectNodeBlocker(s.Node); found {
+		set[b] = true
+	}
+	for _, block := range FindPDBBlocks(s.Pods, s.PDBs) {
+		if !s.pdbBlockRemoved(block) {
+			set[BlockerPDBViolation] = true
+			break
+		}
+	}
+
+	blockers := make([]BlockerType, 0, len(set))
+	for b := range set {
+		if !s.ignored[b] {
+			blockers = append(blockers, b)
+		}
+	}
+	sort.Slice(blockers, func(i, j int) bool { return blockers[i] < blockers[j] })
+	return blockers
+}
+
+// Without returns a copy of the state with the removals applied: blocking
+// annotations are dropped from pods in scope, exhausted PDBs no longer block
+// pods in scope (or at all, when the scope names the PDB), and events reporting
+// a removed blocker are dropped when the pod or PDB they name is in scope, or
+// when they name neither and the removal is not scoped. The receiver is not
+// modified.
+func (s *BlockerState) Without(removals []BlockerRemoval) *BlockerState {
+	out := *s
+	out.Pods = make([]corev1.Pod, len(s.Pods))
+	copy(out.Pods, s.Pods)
+	out.ignored = make(map[BlockerType]bool, len(s.ignored))
+	for b := range s.ignored {
+		out.ignored[b] = true
+	}
+	out.pdbRemoved = append([]BlockerRemoval(nil), s.pdbRemoved...)
+
+	podsByName := make(map[string]*corev1.Pod, len(out.Pods))
+	for i := range out.Pods {
+		podsByName[out.Pods[i].Namespace+"/"+out.Pods[i].Name] = &out.Pods[i]
+	}
+
+	for _, r := range removals {
+		if annotation, ok := blockerAnnotations[r.Blocker]; ok {
+			out.removeAnnotation(r, annotation)
+		}
+		if r.Blocker == BlockerPDBViolation {
+			out.pdbRemoved = append(out.pdbRemoved, r)
+		}
+		if r.Scope == "" && r.Blocker == BlockerHighUtilization {
+			out.ignored[r.Blocker] = true
+		}
+
+		events := out.Events[:0:0]
+		for _, event := range out.Events {
+			if NormalizeEventMessage(event.Message) != r.Blocker || !eventInScope(event, r, podsByName) {
+				events = append(events, event)
+			}
+		}
+		out.Events = events
+	}
+	return &out
+}
+
+// eventInScope reports whether a blocker event falls under a removal: the pod or
+// PDB it names is in scope, or it names neither and the removal is not scoped
+func eventInScope(event corev1.Event, r BlockerRemoval, podsByName map[string]*corev1.Pod) bool {
+	podName := extractPodFromMessage(event.Message)
+	pdbName := extractPDBFromMessage(event.Message)
+	switch {
+	case podName == "" && pdbName == "":
+		return r.Scope == ""
+	case pdbName != "" && (r.Scope == "" || r.namesPDB(pdbName) || (r.name == "*" && strings.HasPrefix(pdbName, r.namespace+"/"))):
+		return true
+	default:
+		pod := podsByName[podName]
+		return pod != nil && r.matchesPod(pod)
+	}
+}
+
+// removeAnnotation strips the annotation from pods in scope, and from the node
+// when the removal is not scoped
+func (s *BlockerState) removeAnnotation(r BlockerRemoval, annotation string) {
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		if _, ok := pod.Annotations[annotation]; !ok || !r.matchesPod(pod) {
+			continue
+		}
+		pod.Annotations = withoutKey(pod.Annotations, annotation)
+	}
+	if _, ok := s.Node.Annotations[annotation]; ok && r.Scope == "" {
+		node := s.Node.DeepCopy()
+		delete(node.Annotations, annotation)
+		s.Node = node
+	}
+}
+
+func (s *BlockerState) pdbBlockRemoved(block PDBBlock) bool {
+	for _, r := range s.pdbRemoved {
+		if r.matchesPDBBlock(block) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutKey returns a copy of m without key, leaving m untouched
+func withoutKey(m map[string]string, key string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if k != key {
+			out[k] = v
+		}
+	}
+	return out
+}
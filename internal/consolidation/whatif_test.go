@@ -0,0 +1,169 @@
+package consolidation
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestParseBlockerRemoval(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: "do-not-disrupt"},
+		{input: "do-not-disrupt=payments/*"},
+		{input: "do-not-disrupt=payments/ledger-0"},
+		{input: "do-not-disrupt=team=payments,tier!=db"},
+		{input: "pdb-violation=payments/ledger-pdb"},
+		{input: "high-utilization"},
+		{input: "high-utilization=payments/*", wantErr: true},
+		{input: "not-a-blocker", wantErr: true},
+		{input: "do-not-disrupt=a in (", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			r, err := ParseBlockerRemoval(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBlockerRemoval(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && r.String() != tt.input {
+				t.Errorf("String() = %q, want %q", r.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestBlockerStateWithout(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("16Gi"),
+		}},
+	}
+	pod := func(namespace, name string, annotations map[string]string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      map[string]string{"app": name},
+			Annotations: annotations,
+		}}
+	}
+	doNotDisrupt := map[string]string{karpenter.AnnotationDoNotDisrupt: "true"}
+	pods := []corev1.Pod{
+		pod("payments", "ledger-0", doNotDisrupt),
+		pod("payments", "api", nil),
+		pod("search", "indexer", doNotDisrupt),
+	}
+	pdbs := []policyv1.PodDisruptionBudget{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api-pdb"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+	}}
+	events := []corev1.Event{
+		{Reason: "DisruptionBlocked", Message: "Cannot disrupt Node: would increase cost"},
+		{Reason: "DisruptionBlocked", Message: `Cannot disrupt Node: pdb "payments/api-pdb" prevents pod evictions`},
+	}
+
+	tests := []struct {
+		name     string
+		removals []string
+		want     []BlockerType
+	}{
+		{
+			name: "nothing removed",
+			want: []BlockerType{BlockerDoNotDisrupt, BlockerPDBViolation, BlockerWouldIncreaseCost},
+		},
+		{
+			name:     "one namespace keeps the other's annotation",
+			removals: []string{"do-not-disrupt=payments/*"},
+			want:     []BlockerType{BlockerDoNotDisrupt, BlockerPDBViolation, BlockerWouldIncreaseCost},
+		},
+		{
+			name:     "both namespaces by selector",
+			removals: []string{"do-not-disrupt=app in (ledger-0,indexer)"},
+			want:     []BlockerType{BlockerPDBViolation, BlockerWouldIncreaseCost},
+		},
+		{
+			name:     "PDB by name and unscoped event blocker",
+			removals: []string{"pdb-violation=payments/api-pdb", "would-increase-cost"},
+			want:     []BlockerType{BlockerDoNotDisrupt},
+		},
+		{
+			name:     "PDB scoped to other pods still blocks",
+			removals: []string{"pdb-violation=search/*"},
+			want:     []BlockerType{BlockerDoNotDisrupt, BlockerPDBViolation, BlockerWouldIncreaseCost},
+		},
+		{
+			name:     "PDB by namespace",
+			removals: []string{"pdb-violation=payments/*"},
+			want:     []BlockerType{BlockerDoNotDisrupt, BlockerWouldIncreaseCost},
+		},
+		{
+			name:     "scoped removal keeps events naming no pod",
+			removals: []string{"would-increase-cost=payments/*"},
+			want:     []BlockerType{BlockerDoNotDisrupt, BlockerPDBViolation, BlockerWouldIncreaseCost},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewBlockerState(node, pods, events, pdbs, DefaultThresholds())
+			removals := make([]BlockerRemoval, len(tt.removals))
+			for i, s := range tt.removals {
+				r, err := ParseBlockerRemoval(s)
+				if err != nil {
+					t.Fatalf("ParseBlockerRemoval(%q) error = %v", s, err)
+				}
+				removals[i] = r
+			}
+
+			got := state.Without(removals).Blockers()
+			if FormatBlockers(got) != FormatBlockers(tt.want) {
+				t.Errorf("Blockers() = %v, want %v", got, tt.want)
+			}
+			// The original state is unchanged
+			if FormatBlockers(state.Blockers()) != "do-not-disrupt,pdb-violation,would-increase-cost" {
+				t.Errorf("original state modified: %v", state.Blockers())
+			}
+		})
+	}
+}
+
+func TestCollectedBlockersMatchBlockerState(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node-1",
+			Annotations: map[string]string{karpenter.AnnotationDoNotConsolidate: "true"},
+		},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("16Gi"),
+		}},
+	}
+	pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "api", Labels: map[string]string{"app": "api"}}}}
+	pdbs := []policyv1.PodDisruptionBudget{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "api"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+	}}
+
+	info := (&Collector{}).collectNodeInfo(node, &clusterData{
+		podsByNode: map[string][]corev1.Pod{"node-1": pods},
+		pdbs:       pdbs,
+	})
+	state := NewBlockerState(node, pods, nil, pdbs, DefaultThresholds())
+
+	want := "do-not-consolidate,pdb-violation"
+	if got := FormatBlockers(info.Blockers); got != want {
+		t.Errorf("collected Blockers = %s, want %s", got, want)
+	}
+	if got := FormatBlockers(state.Blockers()); got != want {
+		t.Errorf("BlockerState.Blockers() = %s, want %s", got, want)
+	}
+}
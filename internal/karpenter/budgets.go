@@ -0,0 +1,102 @@
+package karpenter
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DefaultBudgetNodes is the budget Karpenter applies when a NodePool defines none
+const DefaultBudgetNodes = "10%"
+
+// BudgetResult describes how many disruptions a NodePool allows right now
+type BudgetResult struct {
+	Allowed    int      // Nodes that may still be disrupted for the reason
+	Limit      int      // Most restrictive active budget, before subtracting in-flight disruptions
+	Disrupting int      // Nodes already being disrupted
+	Active     []Budget // Budgets that apply to the reason and are active now
+}
+
+// AppliesTo reports whether the budget covers the given disruption reason
+func (b Budget) AppliesTo(reason string) bool {
+	if len(b.Reasons) == 0 {
+		return true
+	}
+	for _, r := range b.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveAt reports whether the budget is in effect at t
+func (b Budget) ActiveAt(t time.Time) (bool, error) {
+	if b.Schedule == "" {
+		return true, nil
+	}
+	sched, err := ParseSchedule(b.Schedule)
+	if err != nil {
+		return false, err
+	}
+	return sched.ActiveAt(t, b.Duration), nil
+}
+
+// String formats the budget for display, e.g. "nodes=0 schedule=0 9 * * mon-fri duration=8h0m0s"
+func (b Budget) String() string {
+	s := "nodes=" + b.Nodes
+	if b.Schedule != "" {
+		s += fmt.Sprintf(" schedule=%q duration=%s", b.Schedule, b.Duration)
+	}
+	if len(b.Reasons) > 0 {
+		s += fmt.Sprintf(" reasons=%v", b.Reasons)
+	}
+	return s
+}
+
+// AllowedDisruptions evaluates the NodePool's budgets for a disruption reason at now.
+// totalNodes is the number of nodes in the pool and disrupting the number already
+// being disrupted, mirroring Karpenter's calculation.
+func (np *NodePool) AllowedDisruptions(reason string, now time.Time, totalNodes, disrupting int) (BudgetResult, error) {
+	budgets := np.Budgets
+	if len(budgets) == 0 {
+		budgets = []Budget{{Nodes: DefaultBudgetNodes}}
+	}
+
+	result := BudgetResult{
+		Limit:      totalNodes,
+		Disrupting: disrupting,
+	}
+
+	for _, b := range budgets {
+		if !b.AppliesTo(reason) {
+			continue
+		}
+		active, err := b.ActiveAt(now)
+		if err != nil {
+			return BudgetResult{}, err
+		}
+		if !active {
+			continue
+		}
+
+		nodes := intstr.Parse(b.Nodes)
+		limit, err := intstr.GetScaledValueFromIntOrPercent(&nodes, totalNodes, true)
+		if err != nil {
+			return BudgetResult{}, fmt.Errorf("invalid budget nodes %q: %w", b.Nodes, err)
+		}
+
+		result.Active = append(result.Active, b)
+		if limit < result.Limit {
+			result.Limit = limit
+		}
+	}
+
+	result.Allowed = result.Limit - disrupting
+	if result.Allowed < 0 {
+		result.Allowed = 0
+	}
+
+	return result, nil
+}
@@ -33,6 +33,9 @@ func DetectCapabilities(ctx context.Context, client discovery.DiscoveryInterface
 			case (list.GroupVersion == "karpenter.sh/v1beta1" || list.GroupVersion == "karpenter.sh/v1") && resource.Name == "nodeclaims":
 				caps.HasNodeClaims = true
 			}
+			if list.GroupVersion == "karpenter.sh/v1" && (resource.Name == "nodepools" || resource.Name == "nodeclaims") {
+				caps.ServesV1 = true
+			}
 		}
 	}
 
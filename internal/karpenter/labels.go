@@ -15,6 +15,20 @@ const (
 	LabelCapacityType = "karpenter.sh/capacity-type"
 )
 
+// Capacity types
+const (
+	CapacityTypeOnDemand = "on-demand"
+	CapacityTypeSpot     = "spot"
+	CapacityTypeReserved = "reserved"
+)
+
+// Provider labels
+const (
+	// LabelCapacityReservationID is set by the AWS provider on nodes launched
+	// into an EC2 capacity reservation
+	LabelCapacityReservationID = "karpenter.k8s.aws/capacity-reservation-id"
+)
+
 // Annotations (all versions)
 const (
 	AnnotationDoNotEvict       = "karpenter.sh/do-not-evict"
@@ -29,3 +43,28 @@ const (
 	CRDNodePools    = "nodepools.karpenter.sh"
 	CRDProvisioners = "provisioners.karpenter.sh"
 )
+
+// Consolidation policies
+const (
+	ConsolidationPolicyWhenEmpty                = "WhenEmpty"
+	ConsolidationPolicyWhenEmptyOrUnderutilized = "WhenEmptyOrUnderutilized" // v1
+	ConsolidationPolicyWhenUnderutilized        = "WhenUnderutilized"        // v1beta1
+)
+
+// Disruption reasons used by NodePool budgets
+const (
+	DisruptionReasonUnderutilized = "Underutilized"
+	DisruptionReasonEmpty         = "Empty"
+	DisruptionReasonDrifted       = "Drifted"
+)
+
+// NodeClaim condition types
+const (
+	ConditionInitialized = "Initialized"
+)
+
+// Taints (v1beta1/v1)
+const (
+	TaintDisrupted         = "karpenter.sh/disrupted"
+	TaintDisruptionV1Beta1 = "karpenter.sh/disruption"
+)
@@ -0,0 +1,57 @@
+package karpenter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// LoadNodePools reads NodePool or Provisioner manifests from a YAML or JSON file,
+// which may hold several documents. Documents of other kinds are ignored.
+func LoadNodePools(path string) ([]*NodePool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := ParseNodePoolManifests(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pools, nil
+}
+
+// ParseNodePoolManifests decodes every NodePool and Provisioner in a YAML or
+// JSON stream
+func ParseNodePoolManifests(data []byte) ([]*NodePool, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	var pools []*NodePool
+	for {
+		var obj unstructured.Unstructured
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if obj.Object == nil {
+			continue // Empty document
+		}
+		if kind := obj.GetKind(); kind != "NodePool" && kind != "Provisioner" {
+			continue
+		}
+		pool, err := ParseNodePool(&obj)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		pools = append(pools, pool)
+	}
+	if len(pools) == 0 {
+		return nil, errors.New("no NodePool or Provisioner found")
+	}
+	return pools, nil
+}
@@ -0,0 +1,113 @@
+package karpenter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseNodePoolManifests(t *testing.T) {
+	data := `
+apiVersion: karpenter.sh/v1
+kind: NodePool
+metadata:
+  name: default
+spec:
+  template:
+    spec:
+      expireAfter: 336h
+      requirements:
+        - key: karpenter.sh/capacity-type
+          operator: In
+          values: ["spot"]
+  disruption:
+    consolidationPolicy: WhenEmptyOrUnderutilized
+    consolidateAfter: 5m
+    budgets:
+      - nodes: "20%"
+  limits:
+    cpu: 100
+    memory: 400Gi
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+---
+apiVersion: karpenter.sh/v1alpha5
+kind: Provisioner
+metadata:
+  name: legacy
+spec:
+  ttlSecondsAfterEmpty: 30
+`
+	pools, err := ParseNodePoolManifests([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseNodePoolManifests() error = %v", err)
+	}
+	if len(pools) != 2 {
+		t.Fatalf("got %d pools, want 2", len(pools))
+	}
+
+	pool := pools[0]
+	if pool.Name != "default" || pool.Version != APIVersionV1 {
+		t.Errorf("pool = %s %s, want default v1", pool.Name, pool.Version)
+	}
+	if pool.ExpireAfter == nil || pool.ExpireAfter.Duration != 336*time.Hour {
+		t.Errorf("ExpireAfter = %v, want 336h", pool.ExpireAfter)
+	}
+	if len(pool.Budgets) != 1 || len(pool.Requirements) != 1 {
+		t.Errorf("budgets = %d, requirements = %d, want 1 and 1", len(pool.Budgets), len(pool.Requirements))
+	}
+	if cpu := pool.Limits[corev1.ResourceCPU]; cpu.String() != "100" {
+		t.Errorf("cpu limit = %s, want 100", cpu.String())
+	}
+	if pools[1].Name != "legacy" || pools[1].ConsolidationPolicy != ConsolidationPolicyWhenEmpty {
+		t.Errorf("provisioner = %s %s", pools[1].Name, pools[1].ConsolidationPolicy)
+	}
+}
+
+func TestParseNodePoolManifestsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "no pools", data: "apiVersion: v1\nkind: ConfigMap\n", wantErr: "no NodePool"},
+		{
+			name:    "invalid duration",
+			data:    "apiVersion: karpenter.sh/v1\nkind: NodePool\nmetadata:\n  name: bad\nspec:\n  disruption:\n    consolidateAfter: soon\n",
+			wantErr: "NodePool bad",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNodePoolManifests([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnsatisfiedRequirements(t *testing.T) {
+	labels := map[string]string{
+		LabelCapacityType:                CapacityTypeSpot,
+		"karpenter.k8s.aws/instance-cpu": "4",
+	}
+	reqs := []corev1.NodeSelectorRequirement{
+		{Key: LabelCapacityType, Operator: corev1.NodeSelectorOpIn, Values: []string{CapacityTypeOnDemand}},
+		{Key: "karpenter.k8s.aws/instance-cpu", Operator: corev1.NodeSelectorOpGt, Values: []string{"2"}},
+		{Key: "kubernetes.io/arch", Operator: corev1.NodeSelectorOpDoesNotExist},
+	}
+
+	got := UnsatisfiedRequirements(labels, reqs)
+	if len(got) != 1 || FormatRequirement(got[0]) != "karpenter.sh/capacity-type In [on-demand]" {
+		t.Errorf("UnsatisfiedRequirements() = %v", got)
+	}
+	if !MatchRequirements(labels, reqs[1:]) {
+		t.Error("MatchRequirements() = false for satisfied requirements")
+	}
+}
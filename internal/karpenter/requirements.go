@@ -0,0 +1,60 @@
+package karpenter
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// LabelRequirement converts a node selector requirement to a label requirement
+func LabelRequirement(req corev1.NodeSelectorRequirement) (*labels.Requirement, error) {
+	return labels.NewRequirement(req.Key, selectionOperator(req.Operator), req.Values)
+}
+
+// MatchRequirements reports whether labels satisfy every node selector requirement
+func MatchRequirements(nodeLabels map[string]string, reqs []corev1.NodeSelectorRequirement) bool {
+	return len(UnsatisfiedRequirements(nodeLabels, reqs)) == 0
+}
+
+// UnsatisfiedRequirements returns the requirements labels do not satisfy. An
+// invalid requirement is never satisfied.
+func UnsatisfiedRequirements(nodeLabels map[string]string, reqs []corev1.NodeSelectorRequirement) []corev1.NodeSelectorRequirement {
+	var unsatisfied []corev1.NodeSelectorRequirement
+	for _, req := range reqs {
+		r, err := LabelRequirement(req)
+		if err != nil || !r.Matches(labels.Set(nodeLabels)) {
+			unsatisfied = append(unsatisfied, req)
+		}
+	}
+	return unsatisfied
+}
+
+// FormatRequirement renders a requirement the way it reads in a manifest, e.g.
+// "karpenter.sh/capacity-type In [spot]"
+func FormatRequirement(req corev1.NodeSelectorRequirement) string {
+	if len(req.Values) == 0 {
+		return fmt.Sprintf("%s %s", req.Key, req.Operator)
+	}
+	return fmt.Sprintf("%s %s [%s]", req.Key, req.Operator, strings.Join(req.Values, ","))
+}
+
+func selectionOperator(op corev1.NodeSelectorOperator) selection.Operator {
+	switch op {
+	case corev1.NodeSelectorOpIn:
+		return selection.In
+	case corev1.NodeSelectorOpNotIn:
+		return selection.NotIn
+	case corev1.NodeSelectorOpExists:
+		return selection.Exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		return selection.DoesNotExist
+	case corev1.NodeSelectorOpGt:
+		return selection.GreaterThan
+	case corev1.NodeSelectorOpLt:
+		return selection.LessThan
+	}
+	return selection.Operator(op)
+}
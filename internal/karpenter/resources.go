@@ -0,0 +1,411 @@
+package karpenter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Group/version/resources for Karpenter custom resources
+var (
+	NodePoolsV1       = schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1", Resource: "nodepools"}
+	NodePoolsV1Beta1  = schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1beta1", Resource: "nodepools"}
+	NodeClaimsV1      = schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1", Resource: "nodeclaims"}
+	NodeClaimsV1Beta1 = schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1beta1", Resource: "nodeclaims"}
+	ProvisionersV1A5  = schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1alpha5", Resource: "provisioners"}
+	MachinesV1A5      = schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1alpha5", Resource: "machines"}
+)
+
+// Duration is a Karpenter duration field that may be set to "Never"
+type Duration struct {
+	Duration time.Duration
+	Never    bool
+}
+
+// String formats the duration the way Karpenter manifests spell it
+func (d Duration) String() string {
+	if d.Never {
+		return "Never"
+	}
+	return d.Duration.String()
+}
+
+// ParseDuration parses a Karpenter duration such as "30s", "720h" or "Never"
+func ParseDuration(s string) (Duration, error) {
+	if s == "Never" {
+		return Duration{Never: true}, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return Duration{}, err
+	}
+	return Duration{Duration: d}, nil
+}
+
+// Budget is a NodePool disruption budget
+type Budget struct {
+	Nodes    string
+	Schedule string
+	Duration time.Duration
+	Reasons  []string
+}
+
+// NodePool is a version-independent view of a NodePool (v1beta1/v1) or Provisioner (v1alpha5)
+type NodePool struct {
+	Name                string
+	Version             APIVersion
+	Annotations         map[string]string
+	ConsolidationPolicy string
+	ConsolidateAfter    *Duration
+	ExpireAfter         *Duration
+	Budgets             []Budget
+	Requirements        []corev1.NodeSelectorRequirement
+	Limits              corev1.ResourceList
+}
+
+// NodeClaimCondition is a status condition on a NodeClaim or Machine
+type NodeClaimCondition struct {
+	Type               string
+	Status             string
+	Reason             string
+	Message            string
+	LastTransitionTime time.Time
+}
+
+// NodeClaim is a version-independent view of a NodeClaim (v1beta1/v1) or Machine (v1alpha5)
+type NodeClaim struct {
+	Name              string
+	Version           APIVersion
+	PoolName          string
+	NodeName          string
+	ProviderID        string
+	CreationTimestamp time.Time // expireAfter counts from here, not from the node's creation
+	Conditions        []NodeClaimCondition
+	LastPodEventTime  *time.Time
+}
+
+// Condition returns the condition with the given type, if present
+func (nc *NodeClaim) Condition(conditionType string) (NodeClaimCondition, bool) {
+	for _, c := range nc.Conditions {
+		if c.Type == conditionType {
+			return c, true
+		}
+	}
+	return NodeClaimCondition{}, false
+}
+
+// rawRequirement mirrors NodeSelectorRequirementWithMinValues
+type rawRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+type rawBudget struct {
+	Nodes    string   `json:"nodes,omitempty"`
+	Schedule string   `json:"schedule,omitempty"`
+	Duration string   `json:"duration,omitempty"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+type rawNodePool struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		Template struct {
+			Spec struct {
+				Requirements []rawRequirement `json:"requirements,omitempty"`
+				ExpireAfter  string           `json:"expireAfter,omitempty"`
+			} `json:"spec"`
+		} `json:"template"`
+		Disruption struct {
+			ConsolidationPolicy string      `json:"consolidationPolicy,omitempty"`
+			ConsolidateAfter    string      `json:"consolidateAfter,omitempty"`
+			ExpireAfter         string      `json:"expireAfter,omitempty"`
+			Budgets             []rawBudget `json:"budgets,omitempty"`
+		} `json:"disruption"`
+		Limits corev1.ResourceList `json:"limits,omitempty"`
+	} `json:"spec"`
+}
+
+type rawProvisioner struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		Requirements  []rawRequirement `json:"requirements,omitempty"`
+		Consolidation *struct {
+			Enabled *bool `json:"enabled,omitempty"`
+		} `json:"consolidation,omitempty"`
+		TTLSecondsAfterEmpty   *int64 `json:"ttlSecondsAfterEmpty,omitempty"`
+		TTLSecondsUntilExpired *int64 `json:"ttlSecondsUntilExpired,omitempty"`
+		Limits                 *struct {
+			Resources corev1.ResourceList `json:"resources,omitempty"`
+		} `json:"limits,omitempty"`
+	} `json:"spec"`
+}
+
+type rawNodeClaim struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Status            struct {
+		NodeName   string `json:"nodeName,omitempty"`
+		ProviderID string `json:"providerID,omitempty"`
+		Conditions []struct {
+			Type               string      `json:"type"`
+			Status             string      `json:"status"`
+			Reason             string      `json:"reason,omitempty"`
+			Message            string      `json:"message,omitempty"`
+			LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+		} `json:"conditions,omitempty"`
+		LastPodEventTime *metav1.Time `json:"lastPodEventTime,omitempty"`
+	} `json:"status"`
+}
+
+// ParseNodePool converts an unstructured NodePool or Provisioner into a NodePool
+func ParseNodePool(obj *unstructured.Unstructured) (*NodePool, error) {
+	if obj.GetAPIVersion() == "karpenter.sh/v1alpha5" {
+		return parseProvisioner(obj)
+	}
+
+	var raw rawNodePool
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &raw); err != nil {
+		return nil, err
+	}
+
+	pool := &NodePool{
+		Name:                raw.Name,
+		Version:             APIVersionV1Beta1,
+		Annotations:         raw.Annotations,
+		ConsolidationPolicy: raw.Spec.Disruption.ConsolidationPolicy,
+		Requirements:        convertRequirements(raw.Spec.Template.Spec.Requirements),
+		Limits:              raw.Spec.Limits,
+	}
+	if obj.GetAPIVersion() == "karpenter.sh/v1" {
+		pool.Version = APIVersionV1
+	}
+
+	if raw.Spec.Disruption.ConsolidateAfter != "" {
+		d, err := ParseDuration(raw.Spec.Disruption.ConsolidateAfter)
+		if err != nil {
+			return nil, err
+		}
+		pool.ConsolidateAfter = &d
+	}
+
+	// expireAfter moved from disruption to the template in v1
+	expireAfter := raw.Spec.Template.Spec.ExpireAfter
+	if expireAfter == "" {
+		expireAfter = raw.Spec.Disruption.ExpireAfter
+	}
+	if expireAfter != "" {
+		d, err := ParseDuration(expireAfter)
+		if err != nil {
+			return nil, err
+		}
+		pool.ExpireAfter = &d
+	}
+
+	for _, b := range raw.Spec.Disruption.Budgets {
+		budget := Budget{
+			Nodes:    b.Nodes,
+			Schedule: b.Schedule,
+			Reasons:  b.Reasons,
+		}
+		if b.Duration != "" {
+			d, err := time.ParseDuration(b.Duration)
+			if err != nil {
+				return nil, err
+			}
+			budget.Duration = d
+		}
+		pool.Budgets = append(pool.Budgets, budget)
+	}
+
+	return pool, nil
+}
+
+func parseProvisioner(obj *unstructured.Unstructured) (*NodePool, error) {
+	var raw rawProvisioner
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &raw); err != nil {
+		return nil, err
+	}
+
+	pool := &NodePool{
+		Name:         raw.Name,
+		Version:      APIVersionV1Alpha5,
+		Annotations:  raw.Annotations,
+		Requirements: convertRequirements(raw.Spec.Requirements),
+	}
+	if raw.Spec.Limits != nil {
+		pool.Limits = raw.Spec.Limits.Resources
+	}
+
+	// v1alpha5 expresses consolidation as either full consolidation or empty-node TTL
+	switch {
+	case raw.Spec.Consolidation != nil && raw.Spec.Consolidation.Enabled != nil && *raw.Spec.Consolidation.Enabled:
+		pool.ConsolidationPolicy = ConsolidationPolicyWhenUnderutilized
+	case raw.Spec.TTLSecondsAfterEmpty != nil:
+		pool.ConsolidationPolicy = ConsolidationPolicyWhenEmpty
+		pool.ConsolidateAfter = &Duration{Duration: time.Duration(*raw.Spec.TTLSecondsAfterEmpty) * time.Second}
+	default:
+		pool.ConsolidateAfter = &Duration{Never: true}
+	}
+
+	if raw.Spec.TTLSecondsUntilExpired != nil {
+		pool.ExpireAfter = &Duration{Duration: time.Duration(*raw.Spec.TTLSecondsUntilExpired) * time.Second}
+	}
+
+	return pool, nil
+}
+
+// ParseNodeClaim converts an unstructured NodeClaim or Machine into a NodeClaim
+func ParseNodeClaim(obj *unstructured.Unstructured) (*NodeClaim, error) {
+	var raw rawNodeClaim
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &raw); err != nil {
+		return nil, err
+	}
+
+	claim := &NodeClaim{
+		Name:              raw.Name,
+		NodeName:          raw.Status.NodeName,
+		ProviderID:        raw.Status.ProviderID,
+		CreationTimestamp: raw.CreationTimestamp.Time,
+	}
+
+	switch obj.GetAPIVersion() {
+	case "karpenter.sh/v1alpha5":
+		claim.Version = APIVersionV1Alpha5
+		claim.PoolName = raw.Labels[LabelProvisionerName]
+	case "karpenter.sh/v1":
+		claim.Version = APIVersionV1
+		claim.PoolName = raw.Labels[LabelNodePool]
+	default:
+		claim.Version = APIVersionV1Beta1
+		claim.PoolName = raw.Labels[LabelNodePool]
+	}
+
+	for _, c := range raw.Status.Conditions {
+		claim.Conditions = append(claim.Conditions, NodeClaimCondition{
+			Type:               c.Type,
+			Status:             c.Status,
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime.Time,
+		})
+	}
+
+	if raw.Status.LastPodEventTime != nil && !raw.Status.LastPodEventTime.IsZero() {
+		t := raw.Status.LastPodEventTime.Time
+		claim.LastPodEventTime = &t
+	}
+
+	return claim, nil
+}
+
+func convertRequirements(raw []rawRequirement) []corev1.NodeSelectorRequirement {
+	if len(raw) == 0 {
+		return nil
+	}
+	reqs := make([]corev1.NodeSelectorRequirement, len(raw))
+	for i, r := range raw {
+		reqs[i] = corev1.NodeSelectorRequirement{
+			Key:      r.Key,
+			Operator: corev1.NodeSelectorOperator(r.Operator),
+			Values:   r.Values,
+		}
+	}
+	return reqs
+}
+
+// FetchNodePools retrieves NodePools (or Provisioners on v1alpha5 clusters) keyed by name.
+// Mixed-version clusters return both, with NodePools taking precedence on name collisions.
+// A pool that cannot be parsed is skipped and reported to warnings, which may be nil.
+func FetchNodePools(ctx context.Context, client dynamic.Interface, caps *ClusterCapabilities, warnings io.Writer) (map[string]*NodePool, error) {
+	pools := make(map[string]*NodePool)
+
+	add := func(kind string) func(*unstructured.Unstructured) error {
+		return func(obj *unstructured.Unstructured) error {
+			pool, err := ParseNodePool(obj)
+			if err != nil {
+				// One malformed pool should not hide the others
+				if warnings != nil {
+					_, _ = fmt.Fprintf(warnings, "Warning: skipping %s %s: %v\n", kind, obj.GetName(), err)
+				}
+				return nil
+			}
+			pools[pool.Name] = pool
+			return nil
+		}
+	}
+
+	if caps.HasProvisioners {
+		if err := listInto(ctx, client, ProvisionersV1A5, add("Provisioner")); err != nil {
+			return nil, err
+		}
+	}
+
+	if caps.HasNodePools {
+		gvr := NodePoolsV1Beta1
+		if caps.ServesV1 {
+			gvr = NodePoolsV1
+		}
+		if err := listInto(ctx, client, gvr, add("NodePool")); err != nil {
+			return nil, err
+		}
+	}
+
+	return pools, nil
+}
+
+// FetchNodeClaims retrieves NodeClaims (or Machines on v1alpha5 clusters) keyed by node name.
+// Claims that have not registered a node yet are skipped.
+func FetchNodeClaims(ctx context.Context, client dynamic.Interface, caps *ClusterCapabilities) (map[string]*NodeClaim, error) {
+	claims := make(map[string]*NodeClaim)
+
+	add := func(obj *unstructured.Unstructured) error {
+		claim, err := ParseNodeClaim(obj)
+		if err != nil {
+			return err
+		}
+		if claim.NodeName != "" {
+			claims[claim.NodeName] = claim
+		}
+		return nil
+	}
+
+	if caps.HasMachines {
+		if err := listInto(ctx, client, MachinesV1A5, add); err != nil {
+			return nil, err
+		}
+	}
+
+	if caps.HasNodeClaims {
+		gvr := NodeClaimsV1Beta1
+		if caps.ServesV1 {
+			gvr = NodeClaimsV1
+		}
+		if err := listInto(ctx, client, gvr, add); err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
+}
+
+func listInto(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, fn func(*unstructured.Unstructured) error) error {
+	list, err := client.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		if err := fn(&list.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
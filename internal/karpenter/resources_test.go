@@ -0,0 +1,188 @@
+package karpenter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestParseNodePool(t *testing.T) {
+	tests := []struct {
+		name             string
+		obj              map[string]interface{}
+		wantVersion      APIVersion
+		wantPolicy       string
+		wantAfter        string
+		wantExpire       string
+		wantBudgets      int
+		wantRequirements int
+	}{
+		{
+			name: "v1 nodepool",
+			obj: map[string]interface{}{
+				"apiVersion": "karpenter.sh/v1",
+				"kind":       "NodePool",
+				"metadata":   map[string]interface{}{"name": "default"},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"expireAfter": "720h",
+							"requirements": []interface{}{
+								map[string]interface{}{"key": "karpenter.sh/capacity-type", "operator": "In", "values": []interface{}{"spot"}},
+							},
+						},
+					},
+					"disruption": map[string]interface{}{
+						"consolidationPolicy": "WhenEmptyOrUnderutilized",
+						"consolidateAfter":    "1m",
+						"budgets": []interface{}{
+							map[string]interface{}{"nodes": "10%"},
+							map[string]interface{}{"nodes": "0", "schedule": "@daily", "duration": "10m", "reasons": []interface{}{"Drifted"}},
+						},
+					},
+					"limits": map[string]interface{}{"cpu": "1000"},
+				},
+			},
+			wantVersion:      APIVersionV1,
+			wantPolicy:       ConsolidationPolicyWhenEmptyOrUnderutilized,
+			wantAfter:        "1m0s",
+			wantExpire:       "720h0m0s",
+			wantBudgets:      2,
+			wantRequirements: 1,
+		},
+		{
+			name: "v1beta1 nodepool with expireAfter in disruption",
+			obj: map[string]interface{}{
+				"apiVersion": "karpenter.sh/v1beta1",
+				"kind":       "NodePool",
+				"metadata":   map[string]interface{}{"name": "batch"},
+				"spec": map[string]interface{}{
+					"disruption": map[string]interface{}{
+						"consolidationPolicy": "WhenEmpty",
+						"consolidateAfter":    "Never",
+						"expireAfter":         "Never",
+					},
+				},
+			},
+			wantVersion: APIVersionV1Beta1,
+			wantPolicy:  ConsolidationPolicyWhenEmpty,
+			wantAfter:   "Never",
+			wantExpire:  "Never",
+		},
+		{
+			name: "v1alpha5 provisioner with empty ttl",
+			obj: map[string]interface{}{
+				"apiVersion": "karpenter.sh/v1alpha5",
+				"kind":       "Provisioner",
+				"metadata":   map[string]interface{}{"name": "legacy"},
+				"spec": map[string]interface{}{
+					"ttlSecondsAfterEmpty":   int64(30),
+					"ttlSecondsUntilExpired": int64(3600),
+				},
+			},
+			wantVersion: APIVersionV1Alpha5,
+			wantPolicy:  ConsolidationPolicyWhenEmpty,
+			wantAfter:   "30s",
+			wantExpire:  "1h0m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := ParseNodePool(&unstructured.Unstructured{Object: tt.obj})
+			if err != nil {
+				t.Fatalf("ParseNodePool() error = %v", err)
+			}
+			if pool.Version != tt.wantVersion {
+				t.Errorf("Version = %v, want %v", pool.Version, tt.wantVersion)
+			}
+			if pool.ConsolidationPolicy != tt.wantPolicy {
+				t.Errorf("ConsolidationPolicy = %v, want %v", pool.ConsolidationPolicy, tt.wantPolicy)
+			}
+			if pool.ConsolidateAfter == nil || pool.ConsolidateAfter.String() != tt.wantAfter {
+				t.Errorf("ConsolidateAfter = %v, want %v", pool.ConsolidateAfter, tt.wantAfter)
+			}
+			if pool.ExpireAfter == nil || pool.ExpireAfter.String() != tt.wantExpire {
+				t.Errorf("ExpireAfter = %v, want %v", pool.ExpireAfter, tt.wantExpire)
+			}
+			if len(pool.Budgets) != tt.wantBudgets {
+				t.Errorf("len(Budgets) = %d, want %d", len(pool.Budgets), tt.wantBudgets)
+			}
+			if len(pool.Requirements) != tt.wantRequirements {
+				t.Errorf("len(Requirements) = %d, want %d", len(pool.Requirements), tt.wantRequirements)
+			}
+		})
+	}
+}
+
+func TestParseNodeClaim(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "karpenter.sh/v1",
+		"kind":       "NodeClaim",
+		"metadata": map[string]interface{}{
+			"name":              "default-abc12",
+			"labels":            map[string]interface{}{LabelNodePool: "default"},
+			"creationTimestamp": "2026-01-05T08:59:00Z",
+		},
+		"status": map[string]interface{}{
+			"nodeName":         "node-1",
+			"lastPodEventTime": "2026-01-05T10:00:00Z",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Initialized", "status": "True", "lastTransitionTime": "2026-01-05T09:00:00Z"},
+			},
+		},
+	}}
+
+	claim, err := ParseNodeClaim(obj)
+	if err != nil {
+		t.Fatalf("ParseNodeClaim() error = %v", err)
+	}
+	if claim.NodeName != "node-1" || claim.PoolName != "default" || claim.Version != APIVersionV1 {
+		t.Errorf("ParseNodeClaim() = %+v", claim)
+	}
+	if !claim.CreationTimestamp.Equal(time.Date(2026, 1, 5, 8, 59, 0, 0, time.UTC)) {
+		t.Errorf("CreationTimestamp = %v", claim.CreationTimestamp)
+	}
+	if claim.LastPodEventTime == nil || !claim.LastPodEventTime.Equal(time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("LastPodEventTime = %v", claim.LastPodEventTime)
+	}
+	if cond, ok := claim.Condition(ConditionInitialized); !ok || cond.Status != "True" {
+		t.Errorf("Initialized condition = %+v, found %v", cond, ok)
+	}
+}
+
+func TestFetchNodePoolsSkipsMalformed(t *testing.T) {
+	nodePool := func(name, consolidateAfter string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "karpenter.sh/v1",
+			"kind":       "NodePool",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec": map[string]interface{}{
+				"disruption": map[string]interface{}{"consolidateAfter": consolidateAfter},
+			},
+		}}
+	}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{NodePoolsV1: "NodePoolList"},
+		nodePool("default", "1m"), nodePool("broken", "soon"), nodePool("gpu", "5m"))
+
+	var warnings bytes.Buffer
+	caps := &ClusterCapabilities{HasNodePools: true, ServesV1: true}
+	pools, err := FetchNodePools(context.Background(), client, caps, &warnings)
+	if err != nil {
+		t.Fatalf("FetchNodePools() error = %v", err)
+	}
+	if len(pools) != 2 || pools["default"] == nil || pools["gpu"] == nil {
+		t.Errorf("FetchNodePools() = %v, want default and gpu", pools)
+	}
+	if got := warnings.String(); !strings.HasPrefix(got, "Warning: skipping NodePool broken: ") {
+		t.Errorf("warnings = %q, want NodePool broken skipped", got)
+	}
+}
@@ -0,0 +1,153 @@
+package karpenter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "every minute", spec: "* * * * *"},
+		{name: "weekday mornings", spec: "0 9 * * mon-fri"},
+		{name: "steps and lists", spec: "*/15 0,12 1-7 jan,jul *"},
+		{name: "macro", spec: "@daily"},
+		{name: "too few fields", spec: "0 9 * *", wantErr: true},
+		{name: "out of range", spec: "60 * * * *", wantErr: true},
+		{name: "bad name", spec: "0 0 * * funday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchedule(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSchedule(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleActiveAt(t *testing.T) {
+	// 2026-01-05 is a Monday
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		spec     string
+		window   time.Duration
+		at       time.Time
+		expected bool
+	}{
+		{
+			name:     "inside weekday window",
+			spec:     "0 9 * * mon-fri",
+			window:   8 * time.Hour,
+			at:       monday.Add(12 * time.Hour),
+			expected: true,
+		},
+		{
+			name:     "after weekday window",
+			spec:     "0 9 * * mon-fri",
+			window:   8 * time.Hour,
+			at:       monday.Add(18 * time.Hour),
+			expected: false,
+		},
+		{
+			name:     "weekend",
+			spec:     "0 9 * * mon-fri",
+			window:   8 * time.Hour,
+			at:       monday.Add(-2*24*time.Hour + 10*time.Hour),
+			expected: false,
+		},
+		{
+			name:     "window crossing midnight",
+			spec:     "0 22 * * *",
+			window:   4 * time.Hour,
+			at:       monday.Add(1 * time.Hour),
+			expected: true,
+		},
+		{
+			name:     "sunday as 7",
+			spec:     "0 0 * * 7",
+			window:   time.Hour,
+			at:       monday.Add(-24*time.Hour + 30*time.Minute),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error = %v", tt.spec, err)
+			}
+			if got := sched.ActiveAt(tt.at, tt.window); got != tt.expected {
+				t.Errorf("ActiveAt() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNodePoolAllowedDisruptions(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		budgets     []Budget
+		reason      string
+		total       int
+		disrupting  int
+		wantAllowed int
+	}{
+		{
+			name:        "default budget rounds up",
+			total:       5,
+			reason:      DisruptionReasonUnderutilized,
+			wantAllowed: 1,
+		},
+		{
+			name:        "in-flight disruptions consume budget",
+			budgets:     []Budget{{Nodes: "2"}},
+			total:       10,
+			disrupting:  2,
+			reason:      DisruptionReasonUnderutilized,
+			wantAllowed: 0,
+		},
+		{
+			name: "scheduled zero budget active",
+			budgets: []Budget{
+				{Nodes: "50%"},
+				{Nodes: "0", Schedule: "0 9 * * mon-fri", Duration: 8 * time.Hour},
+			},
+			total:       10,
+			reason:      DisruptionReasonUnderutilized,
+			wantAllowed: 0,
+		},
+		{
+			name: "zero budget for other reason",
+			budgets: []Budget{
+				{Nodes: "3"},
+				{Nodes: "0", Reasons: []string{DisruptionReasonDrifted}},
+			},
+			total:       10,
+			reason:      DisruptionReasonUnderutilized,
+			wantAllowed: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &NodePool{Budgets: tt.budgets}
+			got, err := pool.AllowedDisruptions(tt.reason, now, tt.total, tt.disrupting)
+			if err != nil {
+				t.Fatalf("AllowedDisruptions() error = %v", err)
+			}
+			if got.Allowed != tt.wantAllowed {
+				t.Errorf("AllowedDisruptions() allowed = %d, want %d", got.Allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ type ClusterCapabilities struct {
 	HasMachines     bool       // v1alpha5
 	HasNodePools    bool       // v1beta1/v1
 	HasProvisioners bool       // v1alpha5
+	ServesV1        bool       // karpenter.sh/v1 is served (preferred over v1beta1)
 	PrimaryVersion  APIVersion // Most likely version based on CRDs
 }
 
@@ -71,6 +72,24 @@ func GetCapacityType(node *corev1.Node) string {
 	return node.Labels[LabelCapacityType]
 }
 
+// IsReserved reports whether a capacity type is reserved capacity, such as an
+// EC2 On-Demand Capacity Reservation. A reservation is billed whether or not a
+// node runs in it, so reserved nodes are treated as prepaid: removing or
+// replacing one saves nothing, and cost reports count no waste or blocked cost
+// for them.
+func IsReserved(capacityType string) bool {
+	return capacityType == CapacityTypeReserved
+}
+
+// GetReservationID returns the capacity reservation a node was launched into,
+// or "" when the provider does not label it
+func GetReservationID(node *corev1.Node) string {
+	if node == nil || node.Labels == nil {
+		return ""
+	}
+	return node.Labels[LabelCapacityReservationID]
+}
+
 // DeterminePoolColumnHeader returns the appropriate column header based on cluster capabilities
 func (c *ClusterCapabilities) DeterminePoolColumnHeader() string {
 	// If we have v1beta1/v1 CRDs, prefer NODEPOOL
@@ -161,6 +161,17 @@ func TestGetCapacityType(t *testing.T) {
 			},
 			expected: "on-demand",
 		},
+		{
+			name: "reserved capacity",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						LabelCapacityType: "reserved",
+					},
+				},
+			},
+			expected: "reserved",
+		},
 	}
 
 	for _, tt := range tests {
@@ -173,6 +184,52 @@ func TestGetCapacityType(t *testing.T) {
 	}
 }
 
+func TestGetReservationID(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     *corev1.Node
+		expected string
+	}{
+		{
+			name:     "nil node",
+			node:     nil,
+			expected: "",
+		},
+		{
+			name: "reserved with reservation",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						LabelCapacityType:          "reserved",
+						LabelCapacityReservationID: "cr-0123456789abcdef0",
+					},
+				},
+			},
+			expected: "cr-0123456789abcdef0",
+		},
+		{
+			name: "reserved without reservation label",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						LabelCapacityType: "reserved",
+					},
+				},
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetReservationID(tt.node)
+			if got != tt.expected {
+				t.Errorf("GetReservationID() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestClusterCapabilities_DeterminePoolColumnHeader(t *testing.T) {
 	tests := []struct {
 		name         string
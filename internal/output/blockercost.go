@@ -0,0 +1,127 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// PrintBlockerCost outputs the cost of blocked nodes by blocker type,
+// namespace and workload
+func (p *Printer) PrintBlockerCost(report consolidation.BlockerCostReport) error {
+	switch p.outputFormat {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(blockerCostToOutput(report))
+	case "yaml":
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		return encoder.Encode(blockerCostToOutput(report))
+	case "":
+		return p.printBlockerCostTable(report)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml")
+	}
+}
+
+func (p *Printer) printBlockerCostTable(report consolidation.BlockerCostReport) error {
+	if _, err := fmt.Fprintf(p.out, "Blocked nodes: %d costing %s/h (%s/day)\n",
+		report.BlockedNodes, consolidation.FormatCost(report.HourlyCost), consolidation.FormatCost(report.HourlyCost*24)); err != nil {
+		return err
+	}
+	if report.Unattributed > 0 {
+		if _, err := fmt.Fprintf(p.out, "Not caused by any pod: %s/h\n", consolidation.FormatCost(report.Unattributed)); err != nil {
+			return err
+		}
+	}
+	if report.Reserved > 0 {
+		if _, err := fmt.Fprintf(p.out, "Blocked reserved nodes, prepaid and not counted: %d\n", report.Reserved); err != nil {
+			return err
+		}
+	}
+	if report.Unpriced > 0 {
+		if _, err := fmt.Fprintf(p.out, "Blocked nodes without a price: %d\n", report.Unpriced); err != nil {
+			return err
+		}
+	}
+
+	sections := []struct {
+		header string
+		shares []consolidation.CostShare
+	}{
+		{"BLOCKER", report.ByBlocker},
+		{"NAMESPACE", report.ByNamespace},
+		{"WORKLOAD", report.ByWorkload},
+	}
+	for _, section := range sections {
+		if len(section.shares) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintln(p.out); err != nil {
+			return err
+		}
+		if err := p.printCostShares(section.header, section.shares); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Printer) printCostShares(header string, shares []consolidation.CostShare) error {
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(w, "%s\tNODES\tCOST/HR\tCOST/DAY\n", header); err != nil {
+			return err
+		}
+	}
+	for _, share := range shares {
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+			share.Name, share.Nodes, consolidation.FormatCost(share.HourlyCost), consolidation.FormatCost(share.HourlyCost*24)); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+type costShareOutput struct {
+	Name        string  `json:"name" yaml:"name"`
+	Nodes       int     `json:"nodes" yaml:"nodes"`
+	CostPerHour float64 `json:"costPerHour" yaml:"costPerHour"`
+}
+
+type blockerCostOutput struct {
+	BlockedNodes        int               `json:"blockedNodes" yaml:"blockedNodes"`
+	UnpricedNodes       int               `json:"unpricedNodes" yaml:"unpricedNodes"`
+	ReservedNodes       int               `json:"reservedNodes" yaml:"reservedNodes"`
+	CostPerHour         float64           `json:"costPerHour" yaml:"costPerHour"`
+	UnattributedPerHour float64           `json:"unattributedPerHour" yaml:"unattributedPerHour"`
+	ByBlocker           []costShareOutput `json:"byBlocker" yaml:"byBlocker"`
+	ByNamespace         []costShareOutput `json:"byNamespace" yaml:"byNamespace"`
+	ByWorkload          []costShareOutput `json:"byWorkload" yaml:"byWorkload"`
+}
+
+func costSharesToOutput(shares []consolidation.CostShare) []costShareOutput {
+	out := make([]costShareOutput, len(shares))
+	for i, share := range shares {
+		out[i] = costShareOutput{Name: share.Name, Nodes: share.Nodes, CostPerHour: share.HourlyCost}
+	}
+	return out
+}
+
+func blockerCostToOutput(report consolidation.BlockerCostReport) blockerCostOutput {
+	return blockerCostOutput{
+		BlockedNodes:        report.BlockedNodes,
+		UnpricedNodes:       report.Unpriced,
+		ReservedNodes:       report.Reserved,
+		CostPerHour:         report.HourlyCost,
+		UnattributedPerHour: report.Unattributed,
+		ByBlocker:           costSharesToOutput(report.ByBlocker),
+		ByNamespace:         costSharesToOutput(report.ByNamespace),
+		ByWorkload:          costSharesToOutput(report.ByWorkload),
+	}
+}
@@ -0,0 +1,149 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+	"github.com/ssoriche/kubectl-consolidation/internal/scheduling"
+)
+
+// PrintCapacityMix outputs each NodePool's nodes by capacity type
+func (p *Printer) PrintCapacityMix(mixes []scheduling.CapacityMix) error {
+	switch p.outputFormat {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(capacityMixToOutput(mixes))
+	case "yaml":
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		return encoder.Encode(capacityMixToOutput(mixes))
+	case "":
+		return p.printCapacityMixTable(mixes)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml")
+	}
+}
+
+func (p *Printer) printCapacityMixTable(mixes []scheduling.CapacityMix) error {
+	poolHeader := p.capabilities.DeterminePoolColumnHeader()
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(w, "%s\tON-DEMAND\tSPOT\tRESERVED\tPROTECTED-ON-DEMAND\tSPOT-TOLERANT-ON-DEMAND\tSPOT-TO-SPOT\n", poolHeader); err != nil {
+			return err
+		}
+	}
+	for _, m := range mixes {
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			m.PoolName,
+			m.Nodes(karpenter.CapacityTypeOnDemand), m.Nodes(karpenter.CapacityTypeSpot), m.Nodes(karpenter.CapacityTypeReserved),
+			m.ProtectedOnDemand, m.SpotTolerantOnDemand, formatSpotToSpot(m)); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(mixes) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(p.out); err != nil {
+		return err
+	}
+	w = tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(w, "%s\tCAPACITY-TYPE\tNODES\tCPU-REQUESTED\tMEM-REQUESTED\tRESERVATIONS\n", poolHeader); err != nil {
+			return err
+		}
+	}
+	for _, m := range mixes {
+		for _, t := range m.Types {
+			capacityType := t.CapacityType
+			if capacityType == "" {
+				capacityType = "<none>"
+			}
+			reservations := "<none>"
+			if len(t.Reservations) > 0 {
+				reservations = strings.Join(t.Reservations, ",")
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+				m.PoolName, capacityType, t.Nodes,
+				formatRequestedOf(t, corev1.ResourceCPU), formatRequestedOf(t, corev1.ResourceMemory), reservations); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// formatSpotToSpot is yes or no with the reason behind it
+func formatSpotToSpot(m scheduling.CapacityMix) string {
+	possible, reason := m.SpotToSpot()
+	if possible {
+		return "yes (" + reason + ")"
+	}
+	return "no (" + reason + ")"
+}
+
+// formatRequestedOf formats requested/allocatable for one resource
+func formatRequestedOf(t scheduling.CapacityTypeUsage, name corev1.ResourceName) string {
+	return formatQuantity(t.Requested, name) + "/" + formatQuantity(t.Allocatable, name)
+}
+
+type capacityTypeUsageOutput struct {
+	CapacityType string            `json:"capacityType" yaml:"capacityType"`
+	Nodes        int               `json:"nodes" yaml:"nodes"`
+	Requested    map[string]string `json:"requested" yaml:"requested"`
+	Allocatable  map[string]string `json:"allocatable" yaml:"allocatable"`
+	Reservations []string          `json:"reservations,omitempty" yaml:"reservations,omitempty"`
+}
+
+type capacityMixOutput struct {
+	PoolName             string                    `json:"poolName" yaml:"poolName"`
+	CapacityTypes        []capacityTypeUsageOutput `json:"capacityTypes" yaml:"capacityTypes"`
+	ProtectedOnDemand    int                       `json:"protectedOnDemand" yaml:"protectedOnDemand"`
+	SpotTolerantOnDemand int                       `json:"spotTolerantOnDemand" yaml:"spotTolerantOnDemand"`
+	AllowsSpot           *bool                     `json:"allowsSpot,omitempty" yaml:"allowsSpot,omitempty"`
+	InstanceTypes        *int                      `json:"instanceTypes,omitempty" yaml:"instanceTypes,omitempty"`
+	SpotToSpot           bool                      `json:"spotToSpot" yaml:"spotToSpot"`
+	SpotToSpotReason     string                    `json:"spotToSpotReason" yaml:"spotToSpotReason"`
+}
+
+func capacityMixToOutput(mixes []scheduling.CapacityMix) []capacityMixOutput {
+	out := make([]capacityMixOutput, len(mixes))
+	for i, m := range mixes {
+		out[i] = capacityMixOutput{
+			PoolName:             m.PoolName,
+			CapacityTypes:        make([]capacityTypeUsageOutput, len(m.Types)),
+			ProtectedOnDemand:    m.ProtectedOnDemand,
+			SpotTolerantOnDemand: m.SpotTolerantOnDemand,
+		}
+		out[i].SpotToSpot, out[i].SpotToSpotReason = m.SpotToSpot()
+		if m.PoolKnown {
+			allows := m.AllowsSpot
+			out[i].AllowsSpot = &allows
+		}
+		if m.InstanceTypesKnown {
+			count := m.InstanceTypes
+			out[i].InstanceTypes = &count
+		}
+		for j, t := range m.Types {
+			out[i].CapacityTypes[j] = capacityTypeUsageOutput{
+				CapacityType: t.CapacityType,
+				Nodes:        t.Nodes,
+				Requested:    resourceListToOutput(t.Requested),
+				Allocatable:  resourceListToOutput(t.Allocatable),
+				Reservations: t.Reservations,
+			}
+		}
+	}
+	return out
+}
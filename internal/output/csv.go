@@ -0,0 +1,145 @@
+package output
+
+import (
+	"encoding/csv"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// DefaultListSeparator joins blockers and other lists in csv and tsv output
+const DefaultListSeparator = ";"
+
+// SetListSeparator sets what joins blockers and other lists in csv and tsv output
+func (p *Printer) SetListSeparator(sep string) {
+	p.listSeparator = sep
+}
+
+// writeRecords writes a header and rows as csv or tsv
+func (p *Printer) writeRecords(comma rune, header []string, rows [][]string) error {
+	w := csv.NewWriter(p.out)
+	w.Comma = comma
+	if !p.noHeaders {
+		if err := w.Write(header); err != nil {
+			return err
+		}
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// printNodesDelimited prints the -o json fields of each node as one record, in
+// the same order.
+// Per-resource maps become one column per resource, such as utilization.cpu,
+// percentages are plain numbers and lists are joined by the list separator.
+func (p *Printer) printNodesDelimited(nodes []consolidation.NodeInfo, comma rune, now time.Time) error {
+	var resources, overheads []corev1.ResourceName
+	for _, info := range nodes {
+		for name := range info.Utilization {
+			if !slices.Contains(resources, name) {
+				resources = append(resources, name)
+			}
+		}
+		for name := range info.DaemonSetOverhead {
+			if !slices.Contains(overheads, name) {
+				overheads = append(overheads, name)
+			}
+		}
+	}
+	slices.Sort(resources)
+	slices.Sort(overheads)
+
+	header := []string{
+		"name", "status", "roles", "age", "version", "poolName", "capacityType", "reservationId",
+		"instanceType", "zone", "architecture", "nodeClaim", "pods", "blockingPods", "internalIP",
+		"cpuUtilization", "memoryUtilization",
+	}
+	for _, prefix := range []string{"utilization", "overheadUtilization", "totalUtilization"} {
+		for _, name := range resources {
+			header = append(header, prefix+"."+string(name))
+		}
+	}
+	for _, name := range overheads {
+		header = append(header, "daemonSetOverhead."+string(name))
+	}
+	for _, name := range resources {
+		header = append(header, "thresholds."+string(name))
+	}
+	header = append(header, "highUtilization", "cpuUsage", "memoryUsage", "costPerHour", "wastePerHour",
+		"consolidatableIn", "consolidatableAt", "empty", "emptySince", "emptyFor", "heldBy", "blockers")
+
+	out := p.nodesToOutput(nodes, now)
+	rows := make([][]string, len(nodes))
+	for i, info := range nodes {
+		o := out[i]
+		row := []string{
+			o.Name, o.Status, o.Roles, o.Age, o.Version, o.PoolName, o.CapacityType, o.ReservationID,
+			o.InstanceType, o.Zone, o.Architecture, o.NodeClaim, strconv.Itoa(o.Pods), strconv.Itoa(o.BlockingPods), o.InternalIP,
+			strconv.Itoa(info.CPUUtilization), strconv.Itoa(info.MemoryUtilization),
+		}
+		for _, util := range []map[corev1.ResourceName]int{info.Utilization, info.OverheadUtilization, info.TotalUtilization} {
+			for _, name := range resources {
+				row = append(row, percentOrEmpty(util, name))
+			}
+		}
+		for _, name := range overheads {
+			row = append(row, o.DaemonSetOverhead[string(name)])
+		}
+		for _, name := range resources {
+			row = append(row, strconv.Itoa(info.Thresholds.For(name).Percent))
+		}
+
+		var cpuUsage, memUsage, cost, waste string
+		if info.HasUsage {
+			cpuUsage, memUsage = strconv.Itoa(info.CPUUsage), strconv.Itoa(info.MemoryUsage)
+		}
+		if info.HasCost {
+			cost = strconv.FormatFloat(info.HourlyCost, 'f', -1, 64)
+			waste = strconv.FormatFloat(info.HourlyWaste, 'f', -1, 64)
+		}
+		heldBy := make([]string, len(o.HeldBy))
+		for j, c := range o.HeldBy {
+			heldBy[j] = c.Name
+		}
+		row = append(row,
+			strings.Join(o.HighUtilization, p.listSeparator), cpuUsage, memUsage, cost, waste,
+			o.ConsolidatableIn, formatTimestamp(o.ConsolidatableAt), strconv.FormatBool(o.Empty),
+			formatTimestamp(o.EmptySince), o.EmptyFor,
+			strings.Join(heldBy, p.listSeparator), strings.Join(o.Blockers, p.listSeparator))
+		rows[i] = row
+	}
+	return p.writeRecords(comma, header, rows)
+}
+
+// percentOrEmpty is a utilization percent without the %, or empty when the
+// node does not offer the resource
+func percentOrEmpty(util map[corev1.ResourceName]int, name corev1.ResourceName) string {
+	percent, ok := util[name]
+	if !ok {
+		return ""
+	}
+	return strconv.Itoa(percent)
+}
+
+func formatTimestamp(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (p *Printer) printPodBlockersDelimited(blockers []consolidation.PodBlocker, comma rune) error {
+	header := []string{"nodeName", "namespace", "podName", "age", "reason"}
+	rows := make([][]string, 0, len(blockers))
+	for _, b := range podBlockersToOutput(blockers) {
+		rows = append(rows, []string{b.NodeName, b.Namespace, b.PodName, b.Age, b.Reason})
+	}
+	return p.writeRecords(comma, header, rows)
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"encoding/csv"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPrintNodesDelimited(t *testing.T) {
+	tests := []struct {
+		name      string
+		comma     rune
+		separator string
+	}{
+		{name: "csv", comma: ','},
+		{name: "tsv", comma: '\t'},
+		{name: "separator collides with the delimiter", comma: ',', separator: ","},
+		{name: "tsv with a custom separator", comma: '\t', separator: "|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, buf := newTestPrinter("")
+			sep := DefaultListSeparator
+			if tt.separator != "" {
+				sep = tt.separator
+				p.SetListSeparator(sep)
+			}
+			if err := p.printNodesDelimited(testNodes(), tt.comma, testNow); err != nil {
+				t.Fatalf("printNodesDelimited() error = %v", err)
+			}
+
+			reader := csv.NewReader(strings.NewReader(buf.String()))
+			reader.Comma = tt.comma
+			records, err := reader.ReadAll()
+			if err != nil {
+				t.Fatalf("output does not parse: %v\n%s", err, buf.String())
+			}
+			if len(records) != 3 {
+				t.Fatalf("got %d records, want a header and 2 rows", len(records))
+			}
+			header := records[0]
+			rows := make([]map[string]string, 2)
+			for i, record := range records[1:] {
+				if len(record) != len(header) {
+					t.Fatalf("row %d has %d fields, header has %d", i, len(record), len(header))
+				}
+				rows[i] = make(map[string]string, len(header))
+				for j, column := range header {
+					rows[i][column] = record[j]
+				}
+			}
+
+			// node-b has a GPU and node-a does not; every node gets the column
+			want := []map[string]string{
+				{
+					"name": "node-a", "reservationId": "", "cpuUtilization": "40",
+					"utilization.cpu": "40", "utilization.nvidia.com/gpu": "", "overheadUtilization.cpu": "5",
+					"thresholds.memory": "80", "daemonSetOverhead.cpu": "150m",
+					"consolidatableIn": "5m", "consolidatableAt": "2026-01-05T12:05:00Z", "empty": "false", "blockers": "",
+				},
+				{
+					"name": "node-b", "reservationId": "cr-0123456789abcdef0", "cpuUtilization": "10",
+					"utilization.cpu": "10", "utilization.nvidia.com/gpu": "50", "overheadUtilization.cpu": "2",
+					"thresholds.memory": "80", "daemonSetOverhead.cpu": "",
+					"consolidatableIn": "<unknown>", "consolidatableAt": "", "empty": "false",
+					"blockers": "do-not-disrupt" + sep + "pdb-violation",
+				},
+			}
+			for i := range want {
+				for column, value := range want[i] {
+					got, ok := rows[i][column]
+					if !ok {
+						t.Errorf("no %s column in %v", column, header)
+						continue
+					}
+					if got != value {
+						t.Errorf("%s %s = %q, want %q", rows[i]["name"], column, got, value)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestDelimitedHeaderMatchesJSON fails when a field is added to or removed
+// from nodeOutput without updating the csv and tsv columns
+func TestDelimitedHeaderMatchesJSON(t *testing.T) {
+	p, buf := newTestPrinter("")
+	if err := p.printNodesDelimited(testNodes(), ',', testNow); err != nil {
+		t.Fatalf("printNodesDelimited() error = %v", err)
+	}
+	header, err := csv.NewReader(strings.NewReader(buf.String())).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Per-resource columns such as utilization.cpu stand for their JSON field
+	var columns []string
+	for _, column := range header {
+		field, _, _ := strings.Cut(column, ".")
+		if !slices.Contains(columns, field) {
+			columns = append(columns, field)
+		}
+	}
+
+	var fields []string
+	outputType := reflect.TypeFor[nodeOutput]()
+	for i := range outputType.NumField() {
+		tag, _, _ := strings.Cut(outputType.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, tag)
+	}
+
+	if !slices.Equal(columns, fields) {
+		t.Errorf("csv columns %v\ndo not match the -o json fields %v", columns, fields)
+	}
+}
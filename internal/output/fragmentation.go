@@ -0,0 +1,141 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// PrintFragmentation outputs the minimum node count estimates per NodePool and
+// instance shape and the nodes contributing most to fragmentation
+func (p *Printer) PrintFragmentation(report consolidation.FragmentationReport) error {
+	switch p.outputFormat {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(fragmentationToOutput(report))
+	case "yaml":
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		return encoder.Encode(fragmentationToOutput(report))
+	case "":
+		return p.printFragmentationTable(report)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml")
+	}
+}
+
+func (p *Printer) printFragmentationTable(report consolidation.FragmentationReport) error {
+	if _, err := fmt.Fprintf(p.out, "Nodes: %d actual, %d estimated (lower bound %d), fragmentation %s\n\n",
+		report.Nodes, report.Estimate, report.LowerBound, formatRatio(report.Fragmentation())); err != nil {
+		return err
+	}
+
+	poolHeader := p.capabilities.DeterminePoolColumnHeader()
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(w, "%s\tINSTANCE-TYPE\tNODES\tLOWER-BOUND\tESTIMATE\tFRAGMENTATION\n", poolHeader); err != nil {
+			return err
+		}
+	}
+	for _, g := range report.Groups {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n",
+			orNone(g.PoolName), g.InstanceType, g.Nodes, g.LowerBound, g.Estimate, formatRatio(g.Fragmentation())); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Contributors) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(p.out, "\nMost fragmented nodes:"); err != nil {
+		return err
+	}
+	w = tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(w, "NAME\t%s\tINSTANCE-TYPE\tCPU\tMEMORY\tUNUSED\n", poolHeader); err != nil {
+			return err
+		}
+	}
+	for _, c := range report.Contributors {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.Node, orNone(c.PoolName), c.InstanceType,
+			consolidation.FormatUtilization(c.Utilization[corev1.ResourceCPU]),
+			consolidation.FormatUtilization(c.Utilization[corev1.ResourceMemory]),
+			consolidation.FormatUtilization(c.Unused)); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// formatRatio formats a 0-1 ratio as a whole percentage
+func formatRatio(ratio float64) string {
+	return consolidation.FormatUtilization(int(math.Round(ratio * 100)))
+}
+
+type shapeGroupOutput struct {
+	PoolName      string  `json:"poolName" yaml:"poolName"`
+	InstanceType  string  `json:"instanceType" yaml:"instanceType"`
+	Nodes         int     `json:"nodes" yaml:"nodes"`
+	LowerBound    int     `json:"lowerBound" yaml:"lowerBound"`
+	Estimate      int     `json:"estimate" yaml:"estimate"`
+	Fragmentation float64 `json:"fragmentation" yaml:"fragmentation"`
+}
+
+type nodeWasteOutput struct {
+	Name         string            `json:"name" yaml:"name"`
+	PoolName     string            `json:"poolName" yaml:"poolName"`
+	InstanceType string            `json:"instanceType" yaml:"instanceType"`
+	Utilization  map[string]string `json:"utilization" yaml:"utilization"`
+	Unused       string            `json:"unused" yaml:"unused"`
+}
+
+type fragmentationOutput struct {
+	Nodes         int                `json:"nodes" yaml:"nodes"`
+	LowerBound    int                `json:"lowerBound" yaml:"lowerBound"`
+	Estimate      int                `json:"estimate" yaml:"estimate"`
+	Fragmentation float64            `json:"fragmentation" yaml:"fragmentation"`
+	Groups        []shapeGroupOutput `json:"groups" yaml:"groups"`
+	Contributors  []nodeWasteOutput  `json:"contributors" yaml:"contributors"`
+}
+
+func fragmentationToOutput(report consolidation.FragmentationReport) fragmentationOutput {
+	out := fragmentationOutput{
+		Nodes:         report.Nodes,
+		LowerBound:    report.LowerBound,
+		Estimate:      report.Estimate,
+		Fragmentation: report.Fragmentation(),
+		Groups:        make([]shapeGroupOutput, len(report.Groups)),
+		Contributors:  make([]nodeWasteOutput, len(report.Contributors)),
+	}
+	for i, g := range report.Groups {
+		out.Groups[i] = shapeGroupOutput{
+			PoolName:      g.PoolName,
+			InstanceType:  g.InstanceType,
+			Nodes:         g.Nodes,
+			LowerBound:    g.LowerBound,
+			Estimate:      g.Estimate,
+			Fragmentation: g.Fragmentation(),
+		}
+	}
+	for i, c := range report.Contributors {
+		out.Contributors[i] = nodeWasteOutput{
+			Name:         c.Node,
+			PoolName:     c.PoolName,
+			InstanceType: c.InstanceType,
+			Utilization:  formatUtilizationMap(c.Utilization),
+			Unused:       consolidation.FormatUtilization(c.Unused),
+		}
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// PrintNodePoolDiff outputs each node's consolidation state under its live
+// NodePool and under the proposed spec
+func (p *Printer) PrintNodePoolDiff(diffs []consolidation.NodePoolDiff, now time.Time) error {
+	switch p.outputFormat {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(nodePoolDiffToOutput(diffs, now))
+	case "yaml":
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		return encoder.Encode(nodePoolDiffToOutput(diffs, now))
+	case "":
+		return p.printNodePoolDiffTable(diffs, now)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml")
+	}
+}
+
+func (p *Printer) printNodePoolDiffTable(diffs []consolidation.NodePoolDiff, now time.Time) error {
+	// Summarize per pool first so the table can be skimmed for arrows
+	changed := make(map[string]int)
+	total := make(map[string]int)
+	for _, d := range diffs {
+		total[d.PoolName]++
+		if d.Changed() {
+			changed[d.PoolName]++
+		}
+	}
+	pools := make([]string, 0, len(total))
+	for name := range total {
+		pools = append(pools, name)
+	}
+	sort.Strings(pools)
+	for _, name := range pools {
+		if _, err := fmt.Fprintf(p.out, "%s: %d of %d nodes change\n", name, changed[name], total[name]); err != nil {
+			return err
+		}
+	}
+	if len(pools) > 0 {
+		if _, err := fmt.Fprintln(p.out); err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(w, "NAME\t%s\tELIGIBILITY\tBLOCKERS\tDRIFT\tEXPIRES\tAT-LIMIT\n", p.capabilities.DeterminePoolColumnHeader()); err != nil {
+			return err
+		}
+	}
+	for _, d := range diffs {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			d.Node, d.PoolName,
+			formatChange(formatEligibility(d.Before), formatEligibility(d.After)),
+			formatChange(consolidation.FormatBlockers(d.Before.Blockers), consolidation.FormatBlockers(d.After.Blockers)),
+			formatChange(formatList(d.Before.Drift), formatList(d.After.Drift)),
+			formatChange(formatExpiry(d.Before, now), formatExpiry(d.After, now)),
+			formatChange(formatResourceNames(d.Before.AtLimits), formatResourceNames(d.After.AtLimits))); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// formatChange shows "before->after", or a single value if unchanged
+func formatChange(before, after string) string {
+	if before == after {
+		return before
+	}
+	return before + "->" + after
+}
+
+// formatEligibility is the Explain verdict with the failed checks behind it
+func formatEligibility(e consolidation.PoolEvaluation) string {
+	if len(e.Failed) == 0 {
+		return e.Verdict
+	}
+	return e.Verdict + " (" + strings.Join(e.Failed, ",") + ")"
+}
+
+func formatExpiry(e consolidation.PoolEvaluation, now time.Time) string {
+	switch {
+	case e.ExpiresAt.IsZero():
+		return "never"
+	case e.Expired(now):
+		return "expired"
+	default:
+		return consolidation.FormatDuration(e.ExpiresAt.Sub(now))
+	}
+}
+
+func formatList(values []string) string {
+	if len(values) == 0 {
+		return "<none>"
+	}
+	return strings.Join(values, "; ")
+}
+
+func formatResourceNames(names []corev1.ResourceName) string {
+	strs := make([]string, len(names))
+	for i, name := range names {
+		strs[i] = string(name)
+	}
+	return formatList(strs)
+}
+
+type poolEvaluationOutput struct {
+	Verdict   string   `json:"verdict" yaml:"verdict"`
+	Failed    []string `json:"failedChecks" yaml:"failedChecks"`
+	Blockers  []string `json:"blockers" yaml:"blockers"`
+	Drift     []string `json:"drift" yaml:"drift"`
+	ExpiresAt string   `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"`
+	Expired   bool     `json:"expired" yaml:"expired"`
+	AtLimits  []string `json:"atLimits" yaml:"atLimits"`
+}
+
+type nodePoolDiffOutput struct {
+	Name     string               `json:"name" yaml:"name"`
+	PoolName string               `json:"poolName" yaml:"poolName"`
+	Changed  bool                 `json:"changed" yaml:"changed"`
+	Before   poolEvaluationOutput `json:"before" yaml:"before"`
+	After    poolEvaluationOutput `json:"after" yaml:"after"`
+}
+
+func poolEvaluationToOutput(e consolidation.PoolEvaluation, now time.Time) poolEvaluationOutput {
+	out := poolEvaluationOutput{
+		Verdict:  e.Verdict,
+		Failed:   e.Failed,
+		Blockers: blockerStrings(e.Blockers),
+		Drift:    e.Drift,
+		Expired:  e.Expired(now),
+		AtLimits: make([]string, len(e.AtLimits)),
+	}
+	if !e.ExpiresAt.IsZero() {
+		out.ExpiresAt = e.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	for i, name := range e.AtLimits {
+		out.AtLimits[i] = string(name)
+	}
+	return out
+}
+
+func nodePoolDiffToOutput(diffs []consolidation.NodePoolDiff, now time.Time) []nodePoolDiffOutput {
+	out := make([]nodePoolDiffOutput, len(diffs))
+	for i, d := range diffs {
+		out[i] = nodePoolDiffOutput{
+			Name:     d.Node,
+			PoolName: d.PoolName,
+			Changed:  d.Changed(),
+			Before:   poolEvaluationToOutput(d.Before, now),
+			After:    poolEvaluationToOutput(d.After, now),
+		}
+	}
+	return out
+}
@@ -0,0 +1,196 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/scheduling"
+)
+
+// drainFlags are passed to every kubectl drain in a plan script
+const drainFlags = "--ignore-daemonsets --delete-emptydir-data --timeout=15m"
+
+// PrintDrainPlan outputs a drain plan as a table, a shell script, JSON or YAML
+func (p *Printer) PrintDrainPlan(plan scheduling.DrainPlan) error {
+	switch p.outputFormat {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(drainPlanToOutput(plan))
+	case "yaml":
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		return encoder.Encode(drainPlanToOutput(plan))
+	case "script":
+		return p.printDrainPlanScript(plan)
+	case "":
+		return p.printDrainPlanTable(plan)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml", "script")
+	}
+}
+
+func (p *Printer) printDrainPlanTable(plan scheduling.DrainPlan) error {
+	if _, err := fmt.Fprintf(p.out, "Plan: drain %d nodes in %d waves, cordoning all of them first\n\n", len(plan.Steps), plan.Waves()); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if len(plan.Steps) > 0 {
+		if !p.noHeaders {
+			if _, err := fmt.Fprintln(w, "WAVE\tNODE\tPODS\tPDBS\tDESTINATIONS"); err != nil {
+				return err
+			}
+		}
+		for _, step := range plan.Steps {
+			if _, err := fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n",
+				step.Wave, step.Node, len(step.Moves), formatPDBUsage(step.PDBs), formatDestinations(step.Moves)); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(plan.Skipped) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(p.out, "\nSkipped:"); err != nil {
+		return err
+	}
+	for _, s := range plan.Skipped {
+		if _, err := fmt.Fprintf(p.out, "  %s: %s\n", s.Node, s.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatPDBUsage lists each PDB with the covered pods the drain evicts and how
+// many it allows at once
+func formatPDBUsage(usage []scheduling.PDBUsage) string {
+	if len(usage) == 0 {
+		return "<none>"
+	}
+	parts := make([]string, len(usage))
+	for i, u := range usage {
+		parts[i] = fmt.Sprintf("%s/%s (%d of %d allowed)", u.PDB.Namespace, u.PDB.Name, u.Pods, u.PDB.Status.DisruptionsAllowed)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatDestinations lists the distinct nodes the step's pods move to
+func formatDestinations(moves []scheduling.Placement) string {
+	if len(moves) == 0 {
+		return "<none>"
+	}
+	var nodes []string
+	seen := make(map[string]bool)
+	for _, m := range moves {
+		if !seen[m.Node] {
+			seen[m.Node] = true
+			nodes = append(nodes, m.Node)
+		}
+	}
+	return strings.Join(nodes, ",")
+}
+
+func (p *Printer) printDrainPlanScript(plan scheduling.DrainPlan) error {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# Drain %d nodes in %d waves. Nodes in a wave share no PodDisruptionBudget.\n", len(plan.Steps), plan.Waves())
+	for _, s := range plan.Skipped {
+		fmt.Fprintf(&b, "# Skipped %s: %s\n", s.Node, s.Reason)
+	}
+	b.WriteString("set -eu\n")
+
+	if len(plan.Steps) > 0 {
+		b.WriteString("\n# Cordon every node first so evicted pods only land on nodes that stay\n")
+		fmt.Fprintf(&b, "kubectl cordon %s\n", strings.Join(plan.Nodes(), " "))
+	}
+
+	for wave := 1; wave <= plan.Waves(); wave++ {
+		fmt.Fprintf(&b, "\n# Wave %d\n", wave)
+		// PDBs touched by this wave, to wait on before the next one
+		var waits []string
+		seen := make(map[string]bool)
+		for _, step := range plan.Steps {
+			if step.Wave != wave {
+				continue
+			}
+			fmt.Fprintf(&b, "kubectl drain %s %s\n", step.Node, drainFlags)
+			fmt.Fprintf(&b, "kubectl delete node %s\n", step.Node)
+			for _, u := range step.PDBs {
+				key := u.PDB.Namespace + "/" + u.PDB.Name
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				waits = append(waits, fmt.Sprintf("kubectl wait pdb/%s -n %s --for=jsonpath='{.status.disruptionsAllowed}'=%d --timeout=15m",
+					u.PDB.Name, u.PDB.Namespace, u.PDB.Status.DisruptionsAllowed))
+			}
+		}
+		if wave < plan.Waves() && len(waits) > 0 {
+			b.WriteString("# Wait for the PDBs to recover before the next wave\n")
+			b.WriteString(strings.Join(waits, "\n") + "\n")
+		}
+	}
+
+	_, err := fmt.Fprint(p.out, b.String())
+	return err
+}
+
+type pdbUsageOutput struct {
+	PDB     string `json:"pdb" yaml:"pdb"`
+	Pods    int    `json:"pods" yaml:"pods"`
+	Allowed int32  `json:"disruptionsAllowed" yaml:"disruptionsAllowed"`
+}
+
+type drainStepOutput struct {
+	Wave  int               `json:"wave" yaml:"wave"`
+	Node  string            `json:"node" yaml:"node"`
+	Moves []placementOutput `json:"moves" yaml:"moves"`
+	PDBs  []pdbUsageOutput  `json:"pdbs" yaml:"pdbs"`
+}
+
+type skippedNodeOutput struct {
+	Node   string `json:"node" yaml:"node"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+type drainPlanOutput struct {
+	Waves   int                 `json:"waves" yaml:"waves"`
+	Steps   []drainStepOutput   `json:"steps" yaml:"steps"`
+	Skipped []skippedNodeOutput `json:"skipped" yaml:"skipped"`
+}
+
+func drainPlanToOutput(plan scheduling.DrainPlan) drainPlanOutput {
+	out := drainPlanOutput{
+		Waves:   plan.Waves(),
+		Steps:   make([]drainStepOutput, len(plan.Steps)),
+		Skipped: make([]skippedNodeOutput, len(plan.Skipped)),
+	}
+	for i, step := range plan.Steps {
+		out.Steps[i] = drainStepOutput{
+			Wave:  step.Wave,
+			Node:  step.Node,
+			Moves: make([]placementOutput, len(step.Moves)),
+			PDBs:  make([]pdbUsageOutput, len(step.PDBs)),
+		}
+		for j, m := range step.Moves {
+			out.Steps[i].Moves[j] = placementOutput{Pod: podName(m.Pod), From: m.From, Node: m.Node}
+		}
+		for j, u := range step.PDBs {
+			out.Steps[i].PDBs[j] = pdbUsageOutput{PDB: u.PDB.Namespace + "/" + u.PDB.Name, Pods: u.Pods, Allowed: u.PDB.Status.DisruptionsAllowed}
+		}
+	}
+	for i, s := range plan.Skipped {
+		out.Skipped[i] = skippedNodeOutput{Node: s.Node, Reason: s.Reason}
+	}
+	return out
+}
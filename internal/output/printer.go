@@ -5,9 +5,15 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/jsonpath"
 
 	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
 	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
@@ -19,37 +25,117 @@ type Printer struct {
 	noHeaders    bool
 	outputFormat string
 	capabilities *karpenter.ClusterCapabilities
+	resources    []corev1.ResourceName
+	// sortBy orders node and pod blocker output; sortPath is set when it is a JSONPath
+	sortBy         string
+	sortDescending bool
+	sortPath       *jsonpath.JSONPath
+	listSeparator  string
 }
 
+// DefaultResources are the utilization columns shown when none are requested
+var DefaultResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
 // NewPrinter creates a new Printer
 func NewPrinter(capabilities *karpenter.ClusterCapabilities, outputFormat string, noHeaders bool) *Printer {
 	return &Printer{
-		out:          os.Stdout,
-		noHeaders:    noHeaders,
-		outputFormat: outputFormat,
-		capabilities: capabilities,
+		out:           os.Stdout,
+		noHeaders:     noHeaders,
+		outputFormat:  outputFormat,
+		capabilities:  capabilities,
+		resources:     DefaultResources,
+		listSeparator: DefaultListSeparator,
+	}
+}
+
+// SetResources selects which resources get a utilization column in the node table
+func (p *Printer) SetResources(resources []corev1.ResourceName) {
+	if len(resources) > 0 {
+		p.resources = resources
+	}
+}
+
+// unknownFormat is the error for an output format a printer does not support,
+// worded the way kubectl reports one
+func unknownFormat(format string, allowed ...string) error {
+	return fmt.Errorf("unable to match a printer suitable for the output format %q, allowed formats are: %s",
+		format, strings.Join(allowed, ","))
+}
+
+// ResourceColumnHeader returns the table header for a resource's utilization column,
+// e.g. CPU-UTIL, MEM-UTIL, GPU-UTIL (nvidia.com/gpu) or EPHEMERAL-STORAGE-UTIL
+func ResourceColumnHeader(name corev1.ResourceName) string {
+	switch name {
+	case corev1.ResourceCPU:
+		return "CPU-UTIL"
+	case corev1.ResourceMemory:
+		return "MEM-UTIL"
+	}
+	short := string(name)
+	if i := strings.LastIndex(short, "/"); i >= 0 {
+		short = short[i+1:]
 	}
+	return strings.ToUpper(short) + "-UTIL"
 }
 
 // PrintNodes outputs node information in the requested format
 func (p *Printer) PrintNodes(nodes []consolidation.NodeInfo) error {
+	// Ages and countdowns are all measured from the same moment
+	now := time.Now()
+	nodes, err := p.sortNodes(nodes, now)
+	if err != nil {
+		return err
+	}
+	if isTemplateFormat(p.outputFormat) {
+		return p.printTemplate(p.nodesToOutput(nodes, now))
+	}
 	switch p.outputFormat {
 	case "json":
-		return p.printNodesJSON(nodes)
+		return p.printNodesJSON(nodes, now)
 	case "yaml":
-		return p.printNodesYAML(nodes)
+		return p.printNodesYAML(nodes, now)
+	case "csv":
+		return p.printNodesDelimited(nodes, ',', now)
+	case "tsv":
+		return p.printNodesDelimited(nodes, '\t', now)
+	case "", "wide":
+		return p.printNodesTable(nodes, p.outputFormat == "wide", now)
 	default:
-		return p.printNodesTable(nodes)
+		return unknownFormat(p.outputFormat, append([]string{"json", "yaml", "wide", "csv", "tsv"}, templateFormats...)...)
 	}
 }
 
-func (p *Printer) printNodesTable(nodes []consolidation.NodeInfo) error {
+// printNodesTable prints the node table; wide adds columns that locate each
+// node, the way kubectl get nodes -o wide does. Ages and countdowns are
+// measured from now.
+func (p *Printer) printNodesTable(nodes []consolidation.NodeInfo, wide bool, now time.Time) error {
 	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
 
 	poolHeader := p.capabilities.DeterminePoolColumnHeader()
+	showUsage := hasUsage(nodes)
+	showCost := hasCost(nodes)
 
 	if !p.noHeaders {
-		if _, err := fmt.Fprintf(w, "NAME\tSTATUS\tROLES\tAGE\tVERSION\t%s\tCAPACITY-TYPE\tCPU-UTIL\tMEM-UTIL\tCONSOLIDATION-BLOCKER\n", poolHeader); err != nil {
+		headers := []string{"NAME", "STATUS", "ROLES", "AGE", "VERSION", poolHeader, "CAPACITY-TYPE"}
+		for _, name := range p.resources {
+			headers = append(headers, ResourceColumnHeader(name))
+			if showUsage && slices.Contains(usageResources, name) {
+				headers = append(headers, usageColumnHeader(name))
+			}
+		}
+		if showUsage {
+			for _, name := range p.unselectedUsageResources() {
+				headers = append(headers, usageColumnHeader(name))
+			}
+		}
+		if showCost {
+			headers = append(headers, "COST/HR", "WASTE/HR")
+		}
+		headers = append(headers, "OVERHEAD", "EMPTY-FOR", "HELD-BY", "CONSOLIDATABLE-IN", "CONSOLIDATION-BLOCKER")
+		if wide {
+			headers = append(headers, "INSTANCE-TYPE", "ZONE", "ARCH", "RESERVATION-ID", "NODECLAIM", "PODS", "BLOCKING-PODS", "INTERNAL-IP")
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(headers, "\t")); err != nil {
 			return err
 		}
 	}
@@ -58,7 +144,7 @@ func (p *Printer) printNodesTable(nodes []consolidation.NodeInfo) error {
 		node := info.Node
 		status := consolidation.GetNodeStatus(node)
 		roles := consolidation.GetNodeRoles(node)
-		age := consolidation.FormatAge(node.CreationTimestamp.Time)
+		age := consolidation.FormatDuration(now.Sub(node.CreationTimestamp.Time))
 		version := node.Status.NodeInfo.KubeletVersion
 
 		poolName := info.PoolName
@@ -70,13 +156,43 @@ func (p *Printer) printNodesTable(nodes []consolidation.NodeInfo) error {
 			capacityType = "<none>"
 		}
 
-		cpuUtil := consolidation.FormatUtilization(info.CPUUtilization)
-		memUtil := consolidation.FormatUtilization(info.MemoryUtilization)
 		blockers := consolidation.FormatBlockers(info.Blockers)
 
-		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			node.Name, status, roles, age, version,
-			poolName, capacityType, cpuUtil, memUtil, blockers); err != nil {
+		row := []string{node.Name, status, roles, age, version, poolName, capacityType}
+		for _, name := range p.resources {
+			row = append(row, formatResourceUtilization(info, name))
+			if showUsage && slices.Contains(usageResources, name) {
+				row = append(row, formatResourceUsage(info, name))
+			}
+		}
+		if showUsage {
+			for _, name := range p.unselectedUsageResources() {
+				row = append(row, formatResourceUsage(info, name))
+			}
+		}
+		if showCost {
+			cost, waste := "<unknown>", "<unknown>"
+			if info.HasCost {
+				cost = consolidation.FormatCost(info.HourlyCost)
+				waste = consolidation.FormatCost(info.HourlyWaste)
+			}
+			row = append(row, cost, waste)
+		}
+		row = append(row, formatOverhead(info), formatEmptyFor(info, now), formatHeldBy(info), info.Countdown.Format(now), blockers)
+		if wide {
+			row = append(row,
+				orNone(node.Labels[corev1.LabelInstanceTypeStable]),
+				orNone(node.Labels[corev1.LabelTopologyZone]),
+				orNone(nodeArchitecture(node)),
+				orNone(info.ReservationID),
+				orNone(info.NodeClaimName),
+				strconv.Itoa(info.Pods),
+				strconv.Itoa(info.BlockingPods),
+				orNone(consolidation.GetNodeInternalIP(node)),
+			)
+		}
+
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
 			return err
 		}
 	}
@@ -84,20 +200,163 @@ func (p *Printer) printNodesTable(nodes []consolidation.NodeInfo) error {
 	return w.Flush()
 }
 
+// orNone returns the value, or <none> when it is empty
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+// nodeArchitecture is the node's architecture label, falling back to what the
+// kubelet reports
+func nodeArchitecture(node *corev1.Node) string {
+	if arch := node.Labels[corev1.LabelArchStable]; arch != "" {
+		return arch
+	}
+	return node.Status.NodeInfo.Architecture
+}
+
+// formatResourceUtilization formats a node's utilization of one resource,
+// or <none> if the node does not offer it
+func formatResourceUtilization(info consolidation.NodeInfo, name corev1.ResourceName) string {
+	switch name {
+	case corev1.ResourceCPU:
+		return consolidation.FormatUtilization(info.CPUUtilization)
+	case corev1.ResourceMemory:
+		return consolidation.FormatUtilization(info.MemoryUtilization)
+	}
+	percent, ok := info.Utilization[name]
+	if !ok {
+		return "<none>"
+	}
+	return consolidation.FormatUtilization(percent)
+}
+
+// usageResources are the resources metrics.k8s.io reports usage for
+var usageResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// usageColumnHeader returns CPU-USE or MEM-USE
+func usageColumnHeader(name corev1.ResourceName) string {
+	return strings.TrimSuffix(ResourceColumnHeader(name), "-UTIL") + "-USE"
+}
+
+// unselectedUsageResources lists the usage resources without a utilization
+// column, whose usage columns follow the utilization columns
+func (p *Printer) unselectedUsageResources() []corev1.ResourceName {
+	var names []corev1.ResourceName
+	for _, name := range usageResources {
+		if !slices.Contains(p.resources, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// formatResourceUsage formats a node's actual usage of cpu or memory, or
+// <unknown> without metrics for the node
+func formatResourceUsage(info consolidation.NodeInfo, name corev1.ResourceName) string {
+	if !info.HasUsage {
+		return "<unknown>"
+	}
+	if name == corev1.ResourceCPU {
+		return consolidation.FormatUtilization(info.CPUUsage)
+	}
+	return consolidation.FormatUtilization(info.MemoryUsage)
+}
+
+// formatOverhead formats fixed DaemonSet/static pod overhead as "cpu%/mem%"
+func formatOverhead(info consolidation.NodeInfo) string {
+	return consolidation.FormatUtilization(info.OverheadUtilization[corev1.ResourceCPU]) + "/" +
+		consolidation.FormatUtilization(info.OverheadUtilization[corev1.ResourceMemory])
+}
+
+// formatEmptyFor formats how long a node has run only DaemonSet and static pods
+// as of now
+func formatEmptyFor(info consolidation.NodeInfo, now time.Time) string {
+	if !info.Empty {
+		return "<none>"
+	}
+	return consolidation.FormatDuration(now.Sub(info.EmptySince))
+}
+
+// formatHeldBy lists the checks holding an empty node past consolidateAfter
+func formatHeldBy(info consolidation.NodeInfo) string {
+	if len(info.HeldBy) == 0 {
+		return "<none>"
+	}
+	names := make([]string, len(info.HeldBy))
+	for i, c := range info.HeldBy {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// hasUsage reports whether any node has actual usage from metrics.k8s.io
+func hasUsage(nodes []consolidation.NodeInfo) bool {
+	for _, info := range nodes {
+		if info.HasUsage {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCost reports whether any node has a price
+func hasCost(nodes []consolidation.NodeInfo) bool {
+	for _, info := range nodes {
+		if info.HasCost {
+			return true
+		}
+	}
+	return false
+}
+
+type thresholdOutput struct {
+	Percent int    `json:"percent" yaml:"percent"`
+	Source  string `json:"source" yaml:"source"`
+}
+
 type nodeOutput struct {
-	Name              string   `json:"name" yaml:"name"`
-	Status            string   `json:"status" yaml:"status"`
-	Roles             string   `json:"roles" yaml:"roles"`
-	Age               string   `json:"age" yaml:"age"`
-	Version           string   `json:"version" yaml:"version"`
-	PoolName          string   `json:"poolName" yaml:"poolName"`
-	CapacityType      string   `json:"capacityType" yaml:"capacityType"`
-	CPUUtilization    string   `json:"cpuUtilization" yaml:"cpuUtilization"`
-	MemoryUtilization string   `json:"memoryUtilization" yaml:"memoryUtilization"`
-	Blockers          []string `json:"blockers" yaml:"blockers"`
-}
-
-func (p *Printer) nodesToOutput(nodes []consolidation.NodeInfo) []nodeOutput {
+	Name                string                     `json:"name" yaml:"name"`
+	Status              string                     `json:"status" yaml:"status"`
+	Roles               string                     `json:"roles" yaml:"roles"`
+	Age                 string                     `json:"age" yaml:"age"`
+	Version             string                     `json:"version" yaml:"version"`
+	PoolName            string                     `json:"poolName" yaml:"poolName"`
+	CapacityType        string                     `json:"capacityType" yaml:"capacityType"`
+	ReservationID       string                     `json:"reservationId,omitempty" yaml:"reservationId,omitempty"`
+	InstanceType        string                     `json:"instanceType" yaml:"instanceType"`
+	Zone                string                     `json:"zone" yaml:"zone"`
+	Architecture        string                     `json:"architecture" yaml:"architecture"`
+	NodeClaim           string                     `json:"nodeClaim" yaml:"nodeClaim"`
+	Pods                int                        `json:"pods" yaml:"pods"`
+	BlockingPods        int                        `json:"blockingPods" yaml:"blockingPods"`
+	InternalIP          string                     `json:"internalIP" yaml:"internalIP"`
+	CPUUtilization      string                     `json:"cpuUtilization" yaml:"cpuUtilization"`
+	MemoryUtilization   string                     `json:"memoryUtilization" yaml:"memoryUtilization"`
+	Utilization         map[string]string          `json:"utilization" yaml:"utilization"`
+	OverheadUtilization map[string]string          `json:"overheadUtilization" yaml:"overheadUtilization"`
+	TotalUtilization    map[string]string          `json:"totalUtilization" yaml:"totalUtilization"`
+	DaemonSetOverhead   map[string]string          `json:"daemonSetOverhead" yaml:"daemonSetOverhead"`
+	Thresholds          map[string]thresholdOutput `json:"thresholds" yaml:"thresholds"`
+	HighUtilization     []string                   `json:"highUtilization,omitempty" yaml:"highUtilization,omitempty"`
+	CPUUsage            string                     `json:"cpuUsage,omitempty" yaml:"cpuUsage,omitempty"`
+	MemoryUsage         string                     `json:"memoryUsage,omitempty" yaml:"memoryUsage,omitempty"`
+	CostPerHour         *float64                   `json:"costPerHour,omitempty" yaml:"costPerHour,omitempty"`
+	WastePerHour        *float64                   `json:"wastePerHour,omitempty" yaml:"wastePerHour,omitempty"`
+	ConsolidatableIn    string                     `json:"consolidatableIn" yaml:"consolidatableIn"`
+	ConsolidatableAt    *time.Time                 `json:"consolidatableAt,omitempty" yaml:"consolidatableAt,omitempty"`
+	Empty               bool                       `json:"empty" yaml:"empty"`
+	EmptySince          *time.Time                 `json:"emptySince,omitempty" yaml:"emptySince,omitempty"`
+	EmptyFor            string                     `json:"emptyFor,omitempty" yaml:"emptyFor,omitempty"`
+	HeldBy              []checkOutput              `json:"heldBy,omitempty" yaml:"heldBy,omitempty"`
+	Blockers            []string                   `json:"blockers" yaml:"blockers"`
+}
+
+// nodesToOutput converts nodes for structured output, measuring ages and
+// countdowns from now
+func (p *Printer) nodesToOutput(nodes []consolidation.NodeInfo, now time.Time) []nodeOutput {
 	out := make([]nodeOutput, len(nodes))
 	for i, info := range nodes {
 		blockers := make([]string, len(info.Blockers))
@@ -106,30 +365,87 @@ func (p *Printer) nodesToOutput(nodes []consolidation.NodeInfo) []nodeOutput {
 		}
 
 		out[i] = nodeOutput{
-			Name:              info.Node.Name,
-			Status:            consolidation.GetNodeStatus(info.Node),
-			Roles:             consolidation.GetNodeRoles(info.Node),
-			Age:               consolidation.FormatAge(info.Node.CreationTimestamp.Time),
-			Version:           info.Node.Status.NodeInfo.KubeletVersion,
-			PoolName:          info.PoolName,
-			CapacityType:      info.CapacityType,
-			CPUUtilization:    consolidation.FormatUtilization(info.CPUUtilization),
-			MemoryUtilization: consolidation.FormatUtilization(info.MemoryUtilization),
-			Blockers:          blockers,
+			Name:                info.Node.Name,
+			Status:              consolidation.GetNodeStatus(info.Node),
+			Roles:               consolidation.GetNodeRoles(info.Node),
+			Age:                 consolidation.FormatDuration(now.Sub(info.Node.CreationTimestamp.Time)),
+			Version:             info.Node.Status.NodeInfo.KubeletVersion,
+			PoolName:            info.PoolName,
+			CapacityType:        info.CapacityType,
+			ReservationID:       info.ReservationID,
+			InstanceType:        info.Node.Labels[corev1.LabelInstanceTypeStable],
+			Zone:                info.Node.Labels[corev1.LabelTopologyZone],
+			Architecture:        nodeArchitecture(info.Node),
+			NodeClaim:           info.NodeClaimName,
+			Pods:                info.Pods,
+			BlockingPods:        info.BlockingPods,
+			InternalIP:          consolidation.GetNodeInternalIP(info.Node),
+			CPUUtilization:      consolidation.FormatUtilization(info.CPUUtilization),
+			MemoryUtilization:   consolidation.FormatUtilization(info.MemoryUtilization),
+			Utilization:         formatUtilizationMap(info.Utilization),
+			OverheadUtilization: formatUtilizationMap(info.OverheadUtilization),
+			TotalUtilization:    formatUtilizationMap(info.TotalUtilization),
+			DaemonSetOverhead:   make(map[string]string, len(info.DaemonSetOverhead)),
+			Thresholds:          make(map[string]thresholdOutput, len(info.Utilization)),
+			ConsolidatableIn:    info.Countdown.Format(now),
+			Empty:               info.Empty,
+			Blockers:            blockers,
+		}
+		if info.Countdown.Known && !info.Countdown.Never {
+			at := info.Countdown.At
+			out[i].ConsolidatableAt = &at
+		}
+		if info.Empty {
+			since := info.EmptySince
+			out[i].EmptySince = &since
+			out[i].EmptyFor = consolidation.FormatDuration(now.Sub(since))
+		}
+		for _, c := range info.HeldBy {
+			out[i].HeldBy = append(out[i].HeldBy, checkToOutput(c))
+		}
+		for name, quantity := range info.DaemonSetOverhead {
+			out[i].DaemonSetOverhead[string(name)] = quantity.String()
+		}
+		for name := range info.Utilization {
+			threshold := info.Thresholds.For(name)
+			out[i].Thresholds[string(name)] = thresholdOutput{
+				Percent: threshold.Percent,
+				Source:  string(threshold.Source),
+			}
+		}
+		for _, name := range info.HighUtilization {
+			out[i].HighUtilization = append(out[i].HighUtilization, string(name))
+		}
+		if info.HasUsage {
+			out[i].CPUUsage = consolidation.FormatUtilization(info.CPUUsage)
+			out[i].MemoryUsage = consolidation.FormatUtilization(info.MemoryUsage)
+		}
+		if info.HasCost {
+			cost, waste := info.HourlyCost, info.HourlyWaste
+			out[i].CostPerHour = &cost
+			out[i].WastePerHour = &waste
 		}
 	}
 	return out
 }
 
-func (p *Printer) printNodesJSON(nodes []consolidation.NodeInfo) error {
-	out := p.nodesToOutput(nodes)
+func formatUtilizationMap(util map[corev1.ResourceName]int) map[string]string {
+	out := make(map[string]string, len(util))
+	for name, percent := range util {
+		out[string(name)] = consolidation.FormatUtilization(percent)
+	}
+	return out
+}
+
+func (p *Printer) printNodesJSON(nodes []consolidation.NodeInfo, now time.Time) error {
+	out := p.nodesToOutput(nodes, now)
 	encoder := json.NewEncoder(p.out)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(out)
 }
 
-func (p *Printer) printNodesYAML(nodes []consolidation.NodeInfo) error {
-	out := p.nodesToOutput(nodes)
+func (p *Printer) printNodesYAML(nodes []consolidation.NodeInfo, now time.Time) error {
+	out := p.nodesToOutput(nodes, now)
 	encoder := yaml.NewEncoder(p.out)
 	encoder.SetIndent(2)
 	return encoder.Encode(out)
@@ -137,13 +453,26 @@ func (p *Printer) printNodesYAML(nodes []consolidation.NodeInfo) error {
 
 // PrintPodBlockers outputs pod blocker information
 func (p *Printer) PrintPodBlockers(blockers []consolidation.PodBlocker) error {
+	blockers, err := p.sortPodBlockers(blockers)
+	if err != nil {
+		return err
+	}
+	if isTemplateFormat(p.outputFormat) {
+		return p.printTemplate(podBlockersToOutput(blockers))
+	}
 	switch p.outputFormat {
 	case "json":
 		return p.printPodBlockersJSON(blockers)
 	case "yaml":
 		return p.printPodBlockersYAML(blockers)
-	default:
+	case "csv":
+		return p.printPodBlockersDelimited(blockers, ',')
+	case "tsv":
+		return p.printPodBlockersDelimited(blockers, '\t')
+	case "":
 		return p.printPodBlockersTable(blockers)
+	default:
+		return unknownFormat(p.outputFormat, append([]string{"json", "yaml", "csv", "tsv"}, templateFormats...)...)
 	}
 }
 
@@ -201,3 +530,140 @@ func (p *Printer) printPodBlockersYAML(blockers []consolidation.PodBlocker) erro
 	encoder.SetIndent(2)
 	return encoder.Encode(out)
 }
+
+// PrintExplanation outputs the consolidation checklist for a node
+func (p *Printer) PrintExplanation(expl *consolidation.Explanation) error {
+	switch p.outputFormat {
+	case "json":
+		return p.printExplanationJSON(expl)
+	case "yaml":
+		return p.printExplanationYAML(expl)
+	case "":
+		return p.printExplanationTable(expl)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml")
+	}
+}
+
+func (p *Printer) printExplanationTable(expl *consolidation.Explanation) error {
+	poolName := expl.PoolName
+	if poolName == "" {
+		poolName = "<none>"
+	}
+	capacityType := expl.CapacityType
+	if capacityType == "" {
+		capacityType = "<none>"
+	}
+	nodeClaim := expl.NodeClaimName
+	if nodeClaim == "" {
+		nodeClaim = "<none>"
+	}
+
+	if expl.ReservationID != "" {
+		capacityType += " (" + expl.ReservationID + ")"
+	}
+
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(p.out, "Node: %s  %s: %s  Capacity-Type: %s  NodeClaim: %s\n\n",
+			expl.NodeName, p.capabilities.DeterminePoolColumnHeader(), poolName, capacityType, nodeClaim); err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+
+	if !p.noHeaders {
+		if _, err := fmt.Fprintln(w, "CHECK\tRESULT\tEVIDENCE"); err != nil {
+			return err
+		}
+	}
+
+	for _, check := range expl.Checks {
+		evidence := check.Evidence
+		if len(evidence) == 0 {
+			evidence = []string{""}
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, check.Status, evidence[0]); err != nil {
+			return err
+		}
+		// Continuation lines keep each piece of evidence on its own row
+		for _, e := range evidence[1:] {
+			if _, err := fmt.Fprintf(w, "\t\t%s\n", e); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(p.out, "\nVerdict: %s\n", expl.Verdict()); err != nil {
+			return err
+		}
+	}
+	for _, note := range expl.Notes {
+		if _, err := fmt.Fprintf(p.out, "Note: %s\n", note); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type checkOutput struct {
+	Name     string   `json:"name" yaml:"name"`
+	Status   string   `json:"status" yaml:"status"`
+	Evidence []string `json:"evidence" yaml:"evidence"`
+}
+
+type explanationOutput struct {
+	NodeName      string        `json:"nodeName" yaml:"nodeName"`
+	PoolName      string        `json:"poolName" yaml:"poolName"`
+	CapacityType  string        `json:"capacityType" yaml:"capacityType"`
+	ReservationID string        `json:"reservationId,omitempty" yaml:"reservationId,omitempty"`
+	NodeClaimName string        `json:"nodeClaimName" yaml:"nodeClaimName"`
+	Verdict       string        `json:"verdict" yaml:"verdict"`
+	Checks        []checkOutput `json:"checks" yaml:"checks"`
+	Notes         []string      `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+func checkToOutput(c consolidation.Check) checkOutput {
+	return checkOutput{
+		Name:     c.Name,
+		Status:   string(c.Status),
+		Evidence: c.Evidence,
+	}
+}
+
+func explanationToOutput(expl *consolidation.Explanation) explanationOutput {
+	out := explanationOutput{
+		NodeName:      expl.NodeName,
+		PoolName:      expl.PoolName,
+		CapacityType:  expl.CapacityType,
+		ReservationID: expl.ReservationID,
+		NodeClaimName: expl.NodeClaimName,
+		Verdict:       expl.Verdict(),
+		Checks:        make([]checkOutput, len(expl.Checks)),
+		Notes:         expl.Notes,
+	}
+	for i, c := range expl.Checks {
+		out.Checks[i] = checkToOutput(c)
+	}
+	return out
+}
+
+func (p *Printer) printExplanationJSON(expl *consolidation.Explanation) error {
+	out := explanationToOutput(expl)
+	encoder := json.NewEncoder(p.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+func (p *Printer) printExplanationYAML(expl *consolidation.Explanation) error {
+	out := explanationToOutput(expl)
+	encoder := yaml.NewEncoder(p.out)
+	encoder.SetIndent(2)
+	return encoder.Encode(out)
+}
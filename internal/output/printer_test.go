@@ -0,0 +1,137 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// testNow is the moment test ages and countdowns are measured from
+var testNow = time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+
+func newTestPrinter(format string) (*Printer, *bytes.Buffer) {
+	var buf bytes.Buffer
+	p := NewPrinter(&karpenter.ClusterCapabilities{HasNodePools: true}, format, false)
+	p.out = &buf
+	return p, &buf
+}
+
+func testNode(name string, age time.Duration, ip string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, CreationTimestamp: metav1.NewTime(testNow.Add(-age))},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: ip}},
+			NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: "v1.33.1"},
+		},
+	}
+}
+
+// testNodes is an unblocked on-demand node and a blocked reserved node
+func testNodes() []consolidation.NodeInfo {
+	return []consolidation.NodeInfo{
+		{
+			Node: testNode("node-a", 2*time.Hour, "10.0.0.1", map[string]string{
+				corev1.LabelInstanceTypeStable: "m5.large",
+				corev1.LabelTopologyZone:       "us-east-1a",
+				corev1.LabelArchStable:         "amd64",
+			}),
+			PoolName:            "default",
+			CapacityType:        karpenter.CapacityTypeOnDemand,
+			NodeClaimName:       "default-abc12",
+			Pods:                5,
+			CPUUtilization:      40,
+			MemoryUtilization:   30,
+			Utilization:         map[corev1.ResourceName]int{corev1.ResourceCPU: 40, corev1.ResourceMemory: 30},
+			OverheadUtilization: map[corev1.ResourceName]int{corev1.ResourceCPU: 5, corev1.ResourceMemory: 3},
+			DaemonSetOverhead:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("150m")},
+			Thresholds:          consolidation.DefaultThresholds(),
+			Countdown:           consolidation.Countdown{Known: true, At: testNow.Add(5 * time.Minute)},
+		},
+		{
+			Node: testNode("node-b", 26*time.Hour, "10.0.0.2", map[string]string{
+				corev1.LabelInstanceTypeStable: "m5.xlarge",
+				corev1.LabelTopologyZone:       "us-east-1b",
+				corev1.LabelArchStable:         "arm64",
+			}),
+			PoolName:            "reserved",
+			CapacityType:        karpenter.CapacityTypeReserved,
+			ReservationID:       "cr-0123456789abcdef0",
+			Pods:                3,
+			BlockingPods:        1,
+			CPUUtilization:      10,
+			MemoryUtilization:   20,
+			Utilization:         map[corev1.ResourceName]int{corev1.ResourceCPU: 10, corev1.ResourceMemory: 20, "nvidia.com/gpu": 50},
+			OverheadUtilization: map[corev1.ResourceName]int{corev1.ResourceCPU: 2, corev1.ResourceMemory: 1},
+			Thresholds:          consolidation.DefaultThresholds(),
+			Blockers:            []consolidation.BlockerType{consolidation.BlockerDoNotDisrupt, consolidation.BlockerPDBViolation},
+		},
+	}
+}
+
+func TestPrintNodesTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		wide      bool
+		usage     bool                  // Give node-a metrics.k8s.io usage
+		resources []corev1.ResourceName // Utilization columns, default cpu and memory
+		want      string
+	}{
+		{
+			name: "default",
+			want: `NAME    STATUS  ROLES   AGE  VERSION  NODEPOOL  CAPACITY-TYPE  CPU-UTIL  MEM-UTIL  OVERHEAD  EMPTY-FOR  HELD-BY  CONSOLIDATABLE-IN  CONSOLIDATION-BLOCKER
+node-a  Ready   <none>  2h   v1.33.1  default   on-demand      40%       30%       5%/3%     <none>     <none>   5m                 <none>
+node-b  Ready   <none>  1d   v1.33.1  reserved  reserved       10%       20%       2%/1%     <none>     <none>   <unknown>          do-not-disrupt,pdb-violation
+`,
+		},
+		{
+			name: "wide",
+			wide: true,
+			want: `NAME    STATUS  ROLES   AGE  VERSION  NODEPOOL  CAPACITY-TYPE  CPU-UTIL  MEM-UTIL  OVERHEAD  EMPTY-FOR  HELD-BY  CONSOLIDATABLE-IN  CONSOLIDATION-BLOCKER         INSTANCE-TYPE  ZONE        ARCH   RESERVATION-ID        NODECLAIM      PODS  BLOCKING-PODS  INTERNAL-IP
+node-a  Ready   <none>  2h   v1.33.1  default   on-demand      40%       30%       5%/3%     <none>     <none>   5m                 <none>                        m5.large       us-east-1a  amd64  <none>                default-abc12  5     0              10.0.0.1
+node-b  Ready   <none>  1d   v1.33.1  reserved  reserved       10%       20%       2%/1%     <none>     <none>   <unknown>          do-not-disrupt,pdb-violation  m5.xlarge      us-east-1b  arm64  cr-0123456789abcdef0  <none>         3     1              10.0.0.2
+`,
+		},
+		{
+			name:  "usage follows utilization",
+			usage: true,
+			want: `NAME    STATUS  ROLES   AGE  VERSION  NODEPOOL  CAPACITY-TYPE  CPU-UTIL  CPU-USE    MEM-UTIL  MEM-USE    OVERHEAD  EMPTY-FOR  HELD-BY  CONSOLIDATABLE-IN  CONSOLIDATION-BLOCKER
+node-a  Ready   <none>  2h   v1.33.1  default   on-demand      40%       25%        30%       35%        5%/3%     <none>     <none>   5m                 <none>
+node-b  Ready   <none>  1d   v1.33.1  reserved  reserved       10%       <unknown>  20%       <unknown>  2%/1%     <none>     <none>   <unknown>          do-not-disrupt,pdb-violation
+`,
+		},
+		{
+			name:      "usage without a utilization column",
+			usage:     true,
+			resources: []corev1.ResourceName{"nvidia.com/gpu", corev1.ResourceCPU},
+			want: `NAME    STATUS  ROLES   AGE  VERSION  NODEPOOL  CAPACITY-TYPE  GPU-UTIL  CPU-UTIL  CPU-USE    MEM-USE    OVERHEAD  EMPTY-FOR  HELD-BY  CONSOLIDATABLE-IN  CONSOLIDATION-BLOCKER
+node-a  Ready   <none>  2h   v1.33.1  default   on-demand      <none>    40%       25%        35%        5%/3%     <none>     <none>   5m                 <none>
+node-b  Ready   <none>  1d   v1.33.1  reserved  reserved       50%       10%       <unknown>  <unknown>  2%/1%     <none>     <none>   <unknown>          do-not-disrupt,pdb-violation
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, buf := newTestPrinter("")
+			p.SetResources(tt.resources)
+			nodes := testNodes()
+			if tt.usage {
+				nodes[0].HasUsage, nodes[0].CPUUsage, nodes[0].MemoryUsage = true, 25, 35
+			}
+			if err := p.printNodesTable(nodes, tt.wide, testNow); err != nil {
+				t.Fatalf("printNodesTable() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("printNodesTable() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// histogramWidth is the longest bar in a report histogram, in characters for
+// Markdown and as the full width for HTML
+const histogramWidth = 40
+
+// PrintReport outputs a consolidation report as Markdown, a self-contained HTML
+// page, JSON or YAML
+func (p *Printer) PrintReport(report consolidation.Report) error {
+	switch p.outputFormat {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(p.reportToOutput(report))
+	case "yaml":
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		return encoder.Encode(p.reportToOutput(report))
+	case "html":
+		return reportTemplate.Execute(p.out, p.reportSections(report))
+	case "", "markdown":
+		return p.printReportMarkdown(p.reportSections(report))
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml", "markdown", "html")
+	}
+}
+
+// reportTable is one table of a report
+type reportTable struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+	Empty   string // Shown instead of the table when it has no rows
+}
+
+// reportBar is one bucket of a utilization histogram
+type reportBar struct {
+	Label string
+	Count int
+	Width int // Bar length out of histogramWidth
+}
+
+type reportHistogram struct {
+	Title string
+	Bars  []reportBar
+}
+
+// reportSections is a report laid out for Markdown and HTML alike
+type reportSections struct {
+	Title       string
+	CollectedAt string
+	Summary     []string
+	Tables      []reportTable // Pools, blockers and workloads
+	Histograms  []reportHistogram
+	Nodes       reportTable
+}
+
+func (p *Printer) reportSections(report consolidation.Report) reportSections {
+	poolHeader := p.capabilities.DeterminePoolColumnHeader()
+	totals := report.Totals
+	showCost := totals.Priced > 0
+
+	s := reportSections{
+		Title:       "Consolidation report",
+		CollectedAt: report.CollectedAt.UTC().Format(time.RFC3339),
+		Summary: []string{
+			fmt.Sprintf("Nodes: %d, of which %d blocked and %d empty", totals.Nodes, totals.Blocked, totals.Empty),
+			fmt.Sprintf("Pods: %d", totals.Pods),
+			fmt.Sprintf("Mean utilization: CPU %s, memory %s",
+				consolidation.FormatUtilization(totals.CPUUtilization), consolidation.FormatUtilization(totals.MemoryUtilization)),
+		},
+	}
+	if showCost {
+		s.Summary = append(s.Summary, fmt.Sprintf("Cost: %s/hr, of which %s/hr is not requested by any pod (%d of %d nodes priced)",
+			consolidation.FormatCost(totals.HourlyCost), consolidation.FormatCost(totals.HourlyWaste), totals.Priced, totals.Nodes))
+	}
+
+	poolsTitle := "NodePools"
+	if poolHeader == "PROVISIONER" {
+		poolsTitle = "Provisioners"
+	}
+	pools := reportTable{
+		Title:   poolsTitle,
+		Headers: []string{poolHeader, "NODES", "PODS", "BLOCKED", "EMPTY", "CPU-UTIL", "MEM-UTIL"},
+		Empty:   "No nodes.",
+	}
+	if showCost {
+		pools.Headers = append(pools.Headers, "COST/HR", "WASTE/HR")
+	}
+	for _, pool := range report.Pools {
+		row := []string{orNone(pool.Name), strconv.Itoa(pool.Nodes), strconv.Itoa(pool.Pods), strconv.Itoa(pool.Blocked),
+			strconv.Itoa(pool.Empty), consolidation.FormatUtilization(pool.CPUUtilization), consolidation.FormatUtilization(pool.MemoryUtilization)}
+		if showCost {
+			row = append(row, consolidation.FormatCost(pool.HourlyCost), consolidation.FormatCost(pool.HourlyWaste))
+		}
+		pools.Rows = append(pools.Rows, row)
+	}
+
+	blockers := reportTable{Title: "Blockers", Headers: []string{"BLOCKER", "NODES"}, Empty: "No node is blocked."}
+	for _, b := range report.Blockers {
+		blockers.Rows = append(blockers.Rows, []string{string(b.Blocker), strconv.Itoa(b.Nodes)})
+	}
+
+	workloads := reportTable{Title: "Top blocking workloads", Headers: []string{"WORKLOAD", "PODS", "NODES"}, Empty: "No pod blocks consolidation."}
+	for _, w := range report.Workloads {
+		workloads.Rows = append(workloads.Rows, []string{w.Name, strconv.Itoa(w.Pods), strconv.Itoa(w.Nodes)})
+	}
+	s.Tables = []reportTable{pools, blockers, workloads}
+
+	s.Histograms = []reportHistogram{
+		histogram("CPU utilization", report.CPUHistogram),
+		histogram("Memory utilization", report.MemoryHistogram),
+	}
+
+	s.Nodes = reportTable{
+		Title:   "Nodes",
+		Headers: []string{"NAME", poolHeader, "CAPACITY-TYPE", "INSTANCE-TYPE", "PODS", "CPU-UTIL", "MEM-UTIL", "EMPTY-FOR", "HELD-BY", "CONSOLIDATABLE-IN", "CONSOLIDATION-BLOCKER"},
+		Empty:   "No nodes.",
+	}
+	if showCost {
+		s.Nodes.Headers = append(s.Nodes.Headers, "COST/HR", "WASTE/HR")
+	}
+	for _, info := range report.Nodes {
+		row := []string{info.Node.Name, orNone(info.PoolName), orNone(info.CapacityType),
+			orNone(info.Node.Labels[corev1.LabelInstanceTypeStable]), strconv.Itoa(info.Pods),
+			consolidation.FormatUtilization(info.CPUUtilization), consolidation.FormatUtilization(info.MemoryUtilization),
+			formatEmptyFor(info, report.CollectedAt), formatHeldBy(info), info.Countdown.Format(report.CollectedAt), consolidation.FormatBlockers(info.Blockers)}
+		if showCost {
+			cost, waste := "<unknown>", "<unknown>"
+			if info.HasCost {
+				cost, waste = consolidation.FormatCost(info.HourlyCost), consolidation.FormatCost(info.HourlyWaste)
+			}
+			row = append(row, cost, waste)
+		}
+		s.Nodes.Rows = append(s.Nodes.Rows, row)
+	}
+	return s
+}
+
+func histogram(title string, counts [consolidation.HistogramBuckets]int) reportHistogram {
+	largest := 0
+	for _, c := range counts {
+		largest = max(largest, c)
+	}
+	h := reportHistogram{Title: title}
+	step := 100 / consolidation.HistogramBuckets
+	for i, c := range counts {
+		label := fmt.Sprintf("%d-%d%%", i*step, (i+1)*step)
+		width := 0
+		if largest > 0 {
+			width = c * histogramWidth / largest
+		}
+		h.Bars = append(h.Bars, reportBar{Label: label, Count: c, Width: width})
+	}
+	return h
+}
+
+func (p *Printer) printReportMarkdown(s reportSections) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\nCollected %s\n\n", s.Title, s.CollectedAt)
+	for _, line := range s.Summary {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+	for _, t := range s.Tables {
+		writeMarkdownTable(&b, t)
+	}
+	for _, h := range s.Histograms {
+		fmt.Fprintf(&b, "\n## %s\n\n```\n", h.Title)
+		for _, bar := range h.Bars {
+			fmt.Fprintf(&b, "%-8s %-*s %d\n", bar.Label, histogramWidth, strings.Repeat("#", bar.Width), bar.Count)
+		}
+		b.WriteString("```\n")
+	}
+	writeMarkdownTable(&b, s.Nodes)
+
+	_, err := fmt.Fprint(p.out, b.String())
+	return err
+}
+
+func writeMarkdownTable(b *strings.Builder, t reportTable) {
+	fmt.Fprintf(b, "\n## %s\n\n", t.Title)
+	if len(t.Rows) == 0 {
+		fmt.Fprintf(b, "%s\n", t.Empty)
+		return
+	}
+	writeMarkdownRow(b, t.Headers)
+	separators := make([]string, len(t.Headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeMarkdownRow(b, separators)
+	for _, row := range t.Rows {
+		writeMarkdownRow(b, row)
+	}
+}
+
+// markdownEscaper keeps cell text such as <none> from being read as HTML or
+// ending the cell
+var markdownEscaper = strings.NewReplacer("|", `\|`, "<", `\<`)
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = markdownEscaper.Replace(c)
+	}
+	fmt.Fprintf(b, "| %s |\n", strings.Join(escaped, " | "))
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(width int) int { return width * 100 / histogramWidth },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+table { border-collapse: collapse; margin-bottom: 1em; font-size: 0.9em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; white-space: nowrap; }
+th { background: #f6f8fa; }
+.histogram td { border: none; padding: 2px 8px; }
+.bar { background: #0969da; height: 1em; }
+.muted { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">Collected {{.CollectedAt}}</p>
+<ul>
+{{- range .Summary}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- range .Tables}}
+{{template "table" .}}
+{{- end}}
+{{- range .Histograms}}
+<h2>{{.Title}}</h2>
+<table class="histogram">
+{{- range .Bars}}
+<tr><td>{{.Label}}</td><td style="width: 20em"><div class="bar" style="width: {{percent .Width}}%"></div></td><td>{{.Count}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{template "table" .Nodes}}
+</body>
+</html>
+{{define "table"}}
+<h2>{{.Title}}</h2>
+{{- if .Rows}}
+<table>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- else}}
+<p>{{.Empty}}</p>
+{{- end}}
+{{- end}}
+`))
+
+type poolSummaryOutput struct {
+	Name              string   `json:"name" yaml:"name"`
+	Nodes             int      `json:"nodes" yaml:"nodes"`
+	Pods              int      `json:"pods" yaml:"pods"`
+	Blocked           int      `json:"blocked" yaml:"blocked"`
+	Empty             int      `json:"empty" yaml:"empty"`
+	CPUUtilization    string   `json:"cpuUtilization" yaml:"cpuUtilization"`
+	MemoryUtilization string   `json:"memoryUtilization" yaml:"memoryUtilization"`
+	PricedNodes       int      `json:"pricedNodes" yaml:"pricedNodes"`
+	CostPerHour       *float64 `json:"costPerHour,omitempty" yaml:"costPerHour,omitempty"`
+	WastePerHour      *float64 `json:"wastePerHour,omitempty" yaml:"wastePerHour,omitempty"`
+}
+
+type blockerCountOutput struct {
+	Blocker string `json:"blocker" yaml:"blocker"`
+	Nodes   int    `json:"nodes" yaml:"nodes"`
+}
+
+type workloadBlockOutput struct {
+	Workload string `json:"workload" yaml:"workload"`
+	Pods     int    `json:"pods" yaml:"pods"`
+	Nodes    int    `json:"nodes" yaml:"nodes"`
+}
+
+type histogramBucketOutput struct {
+	Range string `json:"range" yaml:"range"`
+	Nodes int    `json:"nodes" yaml:"nodes"`
+}
+
+type reportOutput struct {
+	CollectedAt     time.Time               `json:"collectedAt" yaml:"collectedAt"`
+	Totals          poolSummaryOutput       `json:"totals" yaml:"totals"`
+	Pools           []poolSummaryOutput     `json:"pools" yaml:"pools"`
+	Blockers        []blockerCountOutput    `json:"blockers" yaml:"blockers"`
+	Workloads       []workloadBlockOutput   `json:"workloads" yaml:"workloads"`
+	CPUHistogram    []histogramBucketOutput `json:"cpuHistogram" yaml:"cpuHistogram"`
+	MemoryHistogram []histogramBucketOutput `json:"memoryHistogram" yaml:"memoryHistogram"`
+	Nodes           []nodeOutput            `json:"nodes" yaml:"nodes"`
+}
+
+func poolSummaryToOutput(s consolidation.PoolSummary) poolSummaryOutput {
+	out := poolSummaryOutput{
+		Name:              s.Name,
+		Nodes:             s.Nodes,
+		Pods:              s.Pods,
+		Blocked:           s.Blocked,
+		Empty:             s.Empty,
+		CPUUtilization:    consolidation.FormatUtilization(s.CPUUtilization),
+		MemoryUtilization: consolidation.FormatUtilization(s.MemoryUtilization),
+		PricedNodes:       s.Priced,
+	}
+	if s.Priced > 0 {
+		cost, waste := s.HourlyCost, s.HourlyWaste
+		out.CostPerHour = &cost
+		out.WastePerHour = &waste
+	}
+	return out
+}
+
+func histogramToOutput(h reportHistogram) []histogramBucketOutput {
+	out := make([]histogramBucketOutput, len(h.Bars))
+	for i, bar := range h.Bars {
+		out[i] = histogramBucketOutput{Range: bar.Label, Nodes: bar.Count}
+	}
+	return out
+}
+
+func (p *Printer) reportToOutput(report consolidation.Report) reportOutput {
+	out := reportOutput{
+		CollectedAt:     report.CollectedAt,
+		Totals:          poolSummaryToOutput(report.Totals),
+		Pools:           make([]poolSummaryOutput, len(report.Pools)),
+		Blockers:        make([]blockerCountOutput, len(report.Blockers)),
+		Workloads:       make([]workloadBlockOutput, len(report.Workloads)),
+		CPUHistogram:    histogramToOutput(histogram("", report.CPUHistogram)),
+		MemoryHistogram: histogramToOutput(histogram("", report.MemoryHistogram)),
+		Nodes:           p.nodesToOutput(report.Nodes, report.CollectedAt),
+	}
+	for i, pool := range report.Pools {
+		out.Pools[i] = poolSummaryToOutput(pool)
+	}
+	for i, b := range report.Blockers {
+		out.Blockers[i] = blockerCountOutput{Blocker: string(b.Blocker), Nodes: b.Nodes}
+	}
+	for i, w := range report.Workloads {
+		out.Workloads[i] = workloadBlockOutput{Workload: w.Name, Pods: w.Pods, Nodes: w.Nodes}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testReport summarizes testNodes and a node whose name and labels need escaping
+func testReport() consolidation.Report {
+	nodes := testNodes()
+	nodes = append(nodes, consolidation.NodeInfo{
+		Node: testNode("node-<c>", time.Hour, "10.0.0.3", map[string]string{
+			corev1.LabelInstanceTypeStable: "<script>alert(1)</script>",
+		}),
+		PoolName:       `team|"a"&b`,
+		CapacityType:   "spot",
+		Pods:           2,
+		CPUUtilization: 95,
+		Empty:          true,
+		EmptySince:     testNow.Add(-30 * time.Minute),
+		HeldBy:         []consolidation.Check{{Name: consolidation.CheckNodeClaimInitialized}},
+		HasCost:        true,
+		HourlyCost:     0.04,
+		HourlyWaste:    0.002,
+	})
+	report := consolidation.NewReport(nodes, nil, testNow)
+	report.Workloads = []consolidation.WorkloadBlock{{Name: "web/Deployment/<api>", Pods: 1, Nodes: 1}}
+	return report
+}
+
+// checkGolden compares got with testdata/name, rewriting it with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s (run go test -update if the change is intended):\n%s", path, got)
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	tests := []struct {
+		format  string
+		golden  string
+		escaped []string // Must appear in the output
+		raw     []string // Must not appear in the output
+	}{
+		{
+			format:  "markdown",
+			golden:  "report.md",
+			escaped: []string{`| node-\<c> | team\|"a"&b |`, `\<script>alert(1)\</script>`, `web/Deployment/\<api>`},
+			raw:     []string{"| <", "-<", "/<", "| team|"},
+		},
+		{
+			format:  "html",
+			golden:  "report.html",
+			escaped: []string{"<td>node-&lt;c&gt;</td>", "<td>team|&#34;a&#34;&amp;b</td>", "&lt;script&gt;alert(1)&lt;/script&gt;", "web/Deployment/&lt;api&gt;"},
+			raw:     []string{"<script>", "<c>", "<api>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p, buf := newTestPrinter(tt.format)
+			if err := p.PrintReport(testReport()); err != nil {
+				t.Fatalf("PrintReport() error = %v", err)
+			}
+			got := buf.String()
+			for _, s := range tt.escaped {
+				if !strings.Contains(got, s) {
+					t.Errorf("output does not contain %q", s)
+				}
+			}
+			for _, s := range tt.raw {
+				if strings.Contains(got, s) {
+					t.Errorf("output contains unescaped %q", s)
+				}
+			}
+			checkGolden(t, tt.golden, got)
+		})
+	}
+}
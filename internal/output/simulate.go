@@ -0,0 +1,441 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/scheduling"
+)
+
+// PrintSimulation outputs the per-node results of a scheduling simulation
+func (p *Printer) PrintSimulation(results []scheduling.NodeResult) error {
+	switch p.outputFormat {
+	case "json":
+		return p.printSimulationJSON(results)
+	case "yaml":
+		return p.printSimulationYAML(results)
+	case "":
+		return p.printSimulationTable(results)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml")
+	}
+}
+
+func (p *Printer) printSimulationTable(results []scheduling.NodeResult) error {
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+
+	// The replacement column only appears when a catalog was used
+	showReplacement := false
+	for _, r := range results {
+		if r.Replacement != nil {
+			showReplacement = true
+			break
+		}
+	}
+
+	if !p.noHeaders {
+		headers := []string{"NAME", p.capabilities.DeterminePoolColumnHeader(), "RESULT", "PODS"}
+		if showReplacement {
+			headers = append(headers, "REPLACEMENT")
+		}
+		headers = append(headers, "UNPLACED", "REASON")
+		if _, err := fmt.Fprintln(w, strings.Join(headers, "\t")); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range results {
+		poolName := r.PoolName
+		if poolName == "" {
+			poolName = "<none>"
+		}
+		row := []string{r.Node, poolName, string(r.Outcome), fmt.Sprint(len(r.Placements) + len(r.Unplaced))}
+		if showReplacement {
+			replacement := "<none>"
+			if r.Replacement != nil {
+				replacement = r.Replacement.Summary()
+			}
+			row = append(row, replacement)
+		}
+
+		if len(r.Unplaced) == 0 {
+			row = append(row, "<none>", "")
+			if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+			continue
+		}
+
+		first := r.Unplaced[0]
+		row = append(row, podName(first.Pod), first.Message())
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+		// Continuation lines list each pod that did not fit
+		indent := strings.Repeat("\t", len(row)-2)
+		for _, u := range r.Unplaced[1:] {
+			if _, err := fmt.Fprintf(w, "%s%s\t%s\n", indent, podName(u.Pod), u.Message()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return w.Flush()
+}
+
+func podName(pod *corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
+
+type placementOutput struct {
+	Pod  string `json:"pod" yaml:"pod"`
+	From string `json:"from" yaml:"from"`
+	Node string `json:"node" yaml:"node"`
+}
+
+type unplacedOutput struct {
+	Pod     string         `json:"pod" yaml:"pod"`
+	Message string         `json:"message" yaml:"message"`
+	Reasons map[string]int `json:"reasons" yaml:"reasons"`
+}
+
+type offeringOutput struct {
+	InstanceType string  `json:"instanceType" yaml:"instanceType"`
+	CapacityType string  `json:"capacityType" yaml:"capacityType"`
+	Price        float64 `json:"price" yaml:"price"`
+}
+
+type replacementOutput struct {
+	CurrentType         string          `json:"currentType" yaml:"currentType"`
+	CurrentCapacityType string          `json:"currentCapacityType" yaml:"currentCapacityType"`
+	CurrentPrice        *float64        `json:"currentPrice" yaml:"currentPrice"`
+	Cheapest            *offeringOutput `json:"cheapest" yaml:"cheapest"`
+	Cheaper             bool            `json:"cheaper" yaml:"cheaper"`
+	Summary             string          `json:"summary" yaml:"summary"`
+}
+
+type simulationOutput struct {
+	Name        string             `json:"name" yaml:"name"`
+	PoolName    string             `json:"poolName" yaml:"poolName"`
+	Result      string             `json:"result" yaml:"result"`
+	Placements  []placementOutput  `json:"placements" yaml:"placements"`
+	Unplaced    []unplacedOutput   `json:"unplaced" yaml:"unplaced"`
+	Replacement *replacementOutput `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+}
+
+func replacementToOutput(r *scheduling.Replacement) *replacementOutput {
+	if r == nil {
+		return nil
+	}
+	out := &replacementOutput{
+		CurrentType:         r.CurrentType,
+		CurrentCapacityType: r.CapacityType,
+		Cheaper:             r.Cheaper,
+		Summary:             r.Summary(),
+	}
+	if r.HasCurrentPrice {
+		price := r.CurrentPrice
+		out.CurrentPrice = &price
+	}
+	if r.Cheapest != nil {
+		out.Cheapest = &offeringOutput{
+			InstanceType: r.Cheapest.InstanceType.Name,
+			CapacityType: r.Cheapest.CapacityType,
+			Price:        r.Cheapest.Price,
+		}
+	}
+	return out
+}
+
+func simulationToOutput(results []scheduling.NodeResult) []simulationOutput {
+	out := make([]simulationOutput, len(results))
+	for i, r := range results {
+		out[i] = simulationOutput{
+			Name:        r.Node,
+			PoolName:    r.PoolName,
+			Result:      string(r.Outcome),
+			Placements:  make([]placementOutput, len(r.Placements)),
+			Unplaced:    make([]unplacedOutput, len(r.Unplaced)),
+			Replacement: replacementToOutput(r.Replacement),
+		}
+		for j, pl := range r.Placements {
+			out[i].Placements[j] = placementOutput{Pod: podName(pl.Pod), From: pl.From, Node: pl.Node}
+		}
+		for j, u := range r.Unplaced {
+			out[i].Unplaced[j] = unplacedOutput{Pod: podName(u.Pod), Message: u.Message(), Reasons: u.Reasons}
+		}
+	}
+	return out
+}
+
+func (p *Printer) printSimulationJSON(results []scheduling.NodeResult) error {
+	encoder := json.NewEncoder(p.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(simulationToOutput(results))
+}
+
+func (p *Printer) printSimulationYAML(results []scheduling.NodeResult) error {
+	encoder := yaml.NewEncoder(p.out)
+	encoder.SetIndent(2)
+	return encoder.Encode(simulationToOutput(results))
+}
+
+// PrintMultiNodeSearch outputs the largest removable node set, the pods that
+// would move and the resulting utilization of the surviving nodes
+func (p *Printer) PrintMultiNodeSearch(result scheduling.MultiNodeResult) error {
+	switch p.outputFormat {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(multiNodeToOutput(result))
+	case "yaml":
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		return encoder.Encode(multiNodeToOutput(result))
+	case "":
+		return p.printMultiNodeTable(result)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml")
+	}
+}
+
+func (p *Printer) printMultiNodeTable(result scheduling.MultiNodeResult) error {
+	removed := "<none>"
+	if len(result.Removed) > 0 {
+		removed = strings.Join(result.Removed, ", ")
+	}
+	if _, err := fmt.Fprintf(p.out, "Nodes: %d current, %d after removing %d: %s\n\n",
+		result.NodesBefore, result.NodesAfter(), len(result.Removed), removed); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if len(result.Placements) > 0 {
+		if !p.noHeaders {
+			if _, err := fmt.Fprintln(w, "POD\tFROM\tTO"); err != nil {
+				return err
+			}
+		}
+		for _, pl := range result.Placements {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", podName(pl.Pod), pl.From, pl.Node); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	if !p.noHeaders {
+		headers := []string{"NAME"}
+		for _, name := range p.resources {
+			headers = append(headers, ResourceColumnHeader(name))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(headers, "\t")); err != nil {
+			return err
+		}
+	}
+	for _, s := range result.Survivors {
+		row := []string{s.Node}
+		for _, name := range p.resources {
+			row = append(row, formatUtilizationChange(s, name))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// formatUtilizationChange shows "before->after", or a single value if unchanged
+func formatUtilizationChange(s scheduling.NodeUtilization, name corev1.ResourceName) string {
+	before, ok := s.Before[name]
+	if !ok {
+		return "<none>"
+	}
+	after := s.After[name]
+	if before == after {
+		return consolidation.FormatUtilization(after)
+	}
+	return consolidation.FormatUtilization(before) + "->" + consolidation.FormatUtilization(after)
+}
+
+type survivorOutput struct {
+	Name   string            `json:"name" yaml:"name"`
+	Before map[string]string `json:"before" yaml:"before"`
+	After  map[string]string `json:"after" yaml:"after"`
+}
+
+type multiNodeOutput struct {
+	NodesBefore int               `json:"nodesBefore" yaml:"nodesBefore"`
+	NodesAfter  int               `json:"nodesAfter" yaml:"nodesAfter"`
+	Removed     []string          `json:"removed" yaml:"removed"`
+	Placements  []placementOutput `json:"placements" yaml:"placements"`
+	Survivors   []survivorOutput  `json:"survivors" yaml:"survivors"`
+}
+
+func multiNodeToOutput(result scheduling.MultiNodeResult) multiNodeOutput {
+	out := multiNodeOutput{
+		NodesBefore: result.NodesBefore,
+		NodesAfter:  result.NodesAfter(),
+		Removed:     result.Removed,
+		Placements:  make([]placementOutput, len(result.Placements)),
+		Survivors:   make([]survivorOutput, len(result.Survivors)),
+	}
+	for i, pl := range result.Placements {
+		out.Placements[i] = placementOutput{Pod: podName(pl.Pod), From: pl.From, Node: pl.Node}
+	}
+	for i, s := range result.Survivors {
+		out.Survivors[i] = survivorOutput{
+			Name:   s.Node,
+			Before: formatUtilizationMap(s.Before),
+			After:  formatUtilizationMap(s.After),
+		}
+	}
+	return out
+}
+
+// PrintWhatIf outputs each node's blockers before and after the removals, with
+// a summary of the nodes unblocked and the capacity they would free
+func (p *Printer) PrintWhatIf(removals []consolidation.BlockerRemoval, results []scheduling.WhatIfResult) error {
+	switch p.outputFormat {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(whatIfToOutput(removals, results))
+	case "yaml":
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		return encoder.Encode(whatIfToOutput(removals, results))
+	case "":
+		return p.printWhatIfTable(removals, results)
+	default:
+		return unknownFormat(p.outputFormat, "json", "yaml")
+	}
+}
+
+func (p *Printer) printWhatIfTable(removals []consolidation.BlockerRemoval, results []scheduling.WhatIfResult) error {
+	unblocked, freed := scheduling.TotalFreed(results)
+	if _, err := fmt.Fprintf(p.out, "Removing %s: %d nodes would become consolidatable, freeing cpu %s, memory %s\n\n",
+		formatRemovals(removals), unblocked, formatQuantity(freed, corev1.ResourceCPU), formatQuantity(freed, corev1.ResourceMemory)); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if !p.noHeaders {
+		if _, err := fmt.Fprintf(w, "NAME\t%s\tBLOCKERS\tAFTER\tRESULT\tSTATUS\tFREED-CPU\tFREED-MEM\n", p.capabilities.DeterminePoolColumnHeader()); err != nil {
+			return err
+		}
+	}
+	for _, r := range results {
+		poolName := r.PoolName
+		if poolName == "" {
+			poolName = "<none>"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Node, poolName,
+			consolidation.FormatBlockers(r.Before), consolidation.FormatBlockers(r.After),
+			r.Outcome, whatIfStatus(r),
+			formatQuantity(r.Freed, corev1.ResourceCPU), formatQuantity(r.Freed, corev1.ResourceMemory)); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// whatIfStatus summarizes what the removals change for a node
+func whatIfStatus(r scheduling.WhatIfResult) string {
+	switch {
+	case r.Unblocked():
+		return "unblocked"
+	case r.ConsolidatableBefore():
+		return "already-consolidatable"
+	case len(r.After) > 0:
+		return "still-blocked"
+	case r.Outcome == scheduling.OutcomeReplacementUnknown:
+		return "replacement-unknown"
+	default:
+		return "pods-do-not-fit"
+	}
+}
+
+func formatRemovals(removals []consolidation.BlockerRemoval) string {
+	strs := make([]string, len(removals))
+	for i, r := range removals {
+		strs[i] = r.String()
+	}
+	return strings.Join(strs, ", ")
+}
+
+func formatQuantity(list corev1.ResourceList, name corev1.ResourceName) string {
+	quantity, ok := list[name]
+	if !ok {
+		return "0"
+	}
+	return quantity.String()
+}
+
+func resourceListToOutput(list corev1.ResourceList) map[string]string {
+	out := make(map[string]string, len(list))
+	for name, quantity := range list {
+		out[string(name)] = quantity.String()
+	}
+	return out
+}
+
+type whatIfNodeOutput struct {
+	Name     string            `json:"name" yaml:"name"`
+	PoolName string            `json:"poolName" yaml:"poolName"`
+	Before   []string          `json:"blockersBefore" yaml:"blockersBefore"`
+	After    []string          `json:"blockersAfter" yaml:"blockersAfter"`
+	Result   string            `json:"result" yaml:"result"`
+	Status   string            `json:"status" yaml:"status"`
+	Freed    map[string]string `json:"freed" yaml:"freed"`
+}
+
+type whatIfOutput struct {
+	Removed   []string           `json:"removed" yaml:"removed"`
+	Unblocked int                `json:"unblocked" yaml:"unblocked"`
+	Freed     map[string]string  `json:"freed" yaml:"freed"`
+	Nodes     []whatIfNodeOutput `json:"nodes" yaml:"nodes"`
+}
+
+func blockerStrings(blockers []consolidation.BlockerType) []string {
+	strs := make([]string, len(blockers))
+	for i, b := range blockers {
+		strs[i] = string(b)
+	}
+	return strs
+}
+
+func whatIfToOutput(removals []consolidation.BlockerRemoval, results []scheduling.WhatIfResult) whatIfOutput {
+	unblocked, freed := scheduling.TotalFreed(results)
+	out := whatIfOutput{
+		Removed:   make([]string, len(removals)),
+		Unblocked: unblocked,
+		Freed:     resourceListToOutput(freed),
+		Nodes:     make([]whatIfNodeOutput, len(results)),
+	}
+	for i, r := range removals {
+		out.Removed[i] = r.String()
+	}
+	for i, r := range results {
+		out.Nodes[i] = whatIfNodeOutput{
+			Name:     r.Node,
+			PoolName: r.PoolName,
+			Before:   blockerStrings(r.Before),
+			After:    blockerStrings(r.After),
+			Result:   string(r.Outcome),
+			Status:   whatIfStatus(r),
+			Freed:    resourceListToOutput(r.Freed),
+		}
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// IsJSONPathSort reports whether a sort field is a JSONPath, such as
+// .totalUtilization.cpu or {.zone}, rather than a named field
+func IsJSONPathSort(field string) bool {
+	return strings.HasPrefix(field, ".") || strings.HasPrefix(field, "{")
+}
+
+// SetSort orders the node list and pod blockers by field, either a named
+// field or a JSONPath evaluated against each item of the JSON output
+func (p *Printer) SetSort(field string, descending bool) error {
+	p.sortBy, p.sortDescending, p.sortPath = field, descending, nil
+	if field == "" || !IsJSONPathSort(field) {
+		return nil
+	}
+	path := jsonpath.New("sort-by").AllowMissingKeys(true)
+	if err := path.Parse(relaxedJSONPath(field)); err != nil {
+		return fmt.Errorf("parsing --sort-by %s: %w", field, err)
+	}
+	p.sortPath = path
+	return nil
+}
+
+func (p *Printer) sortNodes(nodes []consolidation.NodeInfo, now time.Time) ([]consolidation.NodeInfo, error) {
+	if p.sortBy == "" {
+		return nodes, nil
+	}
+	nodes = slices.Clone(nodes)
+	if p.sortPath == nil {
+		return nodes, consolidation.SortNodes(nodes, p.sortBy, p.sortDescending)
+	}
+	// Put the nodes in name order so ties are broken by name
+	if err := consolidation.SortNodes(nodes, "name", false); err != nil {
+		return nil, err
+	}
+	durations := func(info consolidation.NodeInfo) map[string]time.Duration {
+		d := map[string]time.Duration{"age": now.Sub(info.Node.CreationTimestamp.Time)}
+		if info.Countdown.Known && !info.Countdown.Never {
+			d["consolidatableIn"] = info.Countdown.At.Sub(now)
+		}
+		if info.Empty {
+			d["emptyFor"] = now.Sub(info.EmptySince)
+		}
+		return d
+	}
+	return sortByPath(nodes, p.nodesToOutput(nodes, now), durations, p.sortPath, p.sortDescending)
+}
+
+func (p *Printer) sortPodBlockers(blockers []consolidation.PodBlocker) ([]consolidation.PodBlocker, error) {
+	if p.sortBy == "" {
+		return blockers, nil
+	}
+	blockers = slices.Clone(blockers)
+	if p.sortPath == nil {
+		return blockers, consolidation.SortPodBlockers(blockers, p.sortBy, p.sortDescending)
+	}
+	if err := consolidation.SortPodBlockers(blockers, "name", false); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	durations := func(b consolidation.PodBlocker) map[string]time.Duration {
+		if b.CreatedAt.IsZero() {
+			return nil
+		}
+		return map[string]time.Duration{"age": now.Sub(b.CreatedAt)}
+	}
+	return sortByPath(blockers, podBlockersToOutput(blockers), durations, p.sortPath, p.sortDescending)
+}
+
+// sortByPath stable-sorts items by the value path finds in the matching
+// element of out. durations gives the exact value behind each item's
+// formatted duration fields, such as age, so they sort by time rather than
+// by their display text. Items where the path finds nothing, or an empty
+// string, come last in either order.
+func sortByPath[T any](items []T, out any, durations func(T) map[string]time.Duration, path *jsonpath.JSONPath, descending bool) ([]T, error) {
+	data, err := toJSONValue(out)
+	if err != nil {
+		return nil, err
+	}
+	elements, _ := data.([]any)
+	if len(elements) != len(items) {
+		return nil, fmt.Errorf("sorting %d items by %d output elements", len(items), len(elements))
+	}
+
+	type keyed struct {
+		item T
+		key  any
+		has  bool
+	}
+	sorted := make([]keyed, len(items))
+	for i, element := range elements {
+		if fields, ok := element.(map[string]any); ok {
+			for name, d := range durations(items[i]) {
+				if _, ok := fields[name]; ok {
+					fields[name] = json.Number(strconv.FormatInt(int64(d), 10))
+				}
+			}
+		}
+		results, err := path.FindResults(element)
+		if err != nil {
+			return nil, err
+		}
+		sorted[i].item = items[i]
+		if len(results) > 0 && len(results[0]) > 0 {
+			key := results[0][0].Interface()
+			sorted[i].key, sorted[i].has = key, key != nil && key != ""
+		}
+	}
+
+	slices.SortStableFunc(sorted, func(a, b keyed) int {
+		if a.has != b.has {
+			if a.has {
+				return -1
+			}
+			return 1
+		}
+		c := compareValues(a.key, b.key)
+		if descending {
+			c = -c
+		}
+		return c
+	})
+	for i := range sorted {
+		items[i] = sorted[i].item
+	}
+	return items, nil
+}
+
+// compareValues orders two JSON values. Numbers, percentages such as 45% and
+// quantities such as 500m or 4Gi compare by size and come before anything
+// else, which compares as text.
+func compareValues(a, b any) int {
+	x, okA := numericValue(a)
+	y, okB := numericValue(b)
+	switch {
+	case okA && okB:
+		return x.Cmp(y)
+	case okA:
+		return -1
+	case okB:
+		return 1
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func numericValue(v any) (resource.Quantity, bool) {
+	var text string
+	switch v := v.(type) {
+	case json.Number:
+		text = v.String()
+	case string:
+		text = strings.TrimSuffix(v, "%")
+	default:
+		return resource.Quantity{}, false
+	}
+	q, err := resource.ParseQuantity(text)
+	return q, err == nil
+}
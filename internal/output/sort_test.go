@@ -0,0 +1,83 @@
+package output
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+func TestCompareValues(t *testing.T) {
+	values := []any{"never", json.Number("3"), "45%", "<unknown>", "500m", "eligible", "4Gi", json.Number("2")}
+	tests := []struct {
+		a, b any
+		want int
+	}{
+		{json.Number("2"), json.Number("3"), -1},
+		{"45%", "5%", 1},
+		{"500m", json.Number("1"), -1},
+		{"4Gi", "512Mi", 1},
+		{json.Number("100"), "abc", -1},
+		{"abc", "5%", 1},
+		{"eligible", "never", -1},
+	}
+	for _, tt := range tests {
+		if got := compareValues(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareValues(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	// Any ordering of mixed values must be transitive
+	for _, a := range values {
+		for _, b := range values {
+			for _, c := range values {
+				if compareValues(a, b) < 0 && compareValues(b, c) < 0 && compareValues(a, c) >= 0 {
+					t.Errorf("%v < %v < %v but compareValues(%v, %v) = %d", a, b, c, a, c, compareValues(a, c))
+				}
+			}
+		}
+	}
+}
+
+func TestSortNodesByPath(t *testing.T) {
+	// 5m, 3h and 2d old, with countdowns of 3h, never, 5m and unknown
+	nodes := func() []consolidation.NodeInfo {
+		return []consolidation.NodeInfo{
+			{Node: testNode("node-c", 2*24*time.Hour, "10.0.0.3", nil), Countdown: consolidation.Countdown{Known: true, At: testNow.Add(3 * time.Hour)}},
+			{Node: testNode("node-a", 3*time.Hour, "10.0.0.1", nil), Countdown: consolidation.Countdown{Known: true, Never: true}},
+			{Node: testNode("node-b", 5*time.Minute, "10.0.0.2", nil), Countdown: consolidation.Countdown{Known: true, At: testNow.Add(5 * time.Minute)}},
+			{Node: testNode("node-d", 20*time.Minute, "10.0.0.4", nil)},
+		}
+	}
+	tests := []struct {
+		sortBy     string
+		descending bool
+		want       []string
+	}{
+		{sortBy: ".age", want: []string{"node-b", "node-d", "node-a", "node-c"}},
+		{sortBy: "{.age}", descending: true, want: []string{"node-c", "node-a", "node-d", "node-b"}},
+		{sortBy: ".consolidatableIn", want: []string{"node-b", "node-c", "node-d", "node-a"}},
+		{sortBy: ".name", want: []string{"node-a", "node-b", "node-c", "node-d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			p, _ := newTestPrinter("")
+			if err := p.SetSort(tt.sortBy, tt.descending); err != nil {
+				t.Fatalf("SetSort() error = %v", err)
+			}
+			sorted, err := p.sortNodes(nodes(), testNow)
+			if err != nil {
+				t.Fatalf("sortNodes() error = %v", err)
+			}
+			var got []string
+			for _, info := range sorted {
+				got = append(got, info.Node.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sorted by %s = %v, want %v", tt.sortBy, got, tt.want)
+			}
+		})
+	}
+}
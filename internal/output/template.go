@@ -0,0 +1,212 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// templateFormats are the kubectl output formats that take a template, either
+// inline after the = or from the file it names
+var templateFormats = []string{
+	"custom-columns", "custom-columns-file",
+	"jsonpath", "jsonpath-file",
+	"go-template", "go-template-file",
+}
+
+// isTemplateFormat reports whether the output format is one of templateFormats
+func isTemplateFormat(format string) bool {
+	kind, _, _ := strings.Cut(format, "=")
+	return slices.Contains(templateFormats, kind)
+}
+
+// printTemplate prints out with a custom-columns, jsonpath or go-template
+// output format, reading the template from the file for *-file formats. The
+// template sees out as its JSON output, so field names match -o json.
+func (p *Printer) printTemplate(out any) error {
+	kind, text, found := strings.Cut(p.outputFormat, "=")
+	if !found {
+		return fmt.Errorf("output format %s needs a template, e.g. %s=...", kind, kind)
+	}
+	if strings.HasSuffix(kind, "-file") {
+		data, err := os.ReadFile(text)
+		if err != nil {
+			return fmt.Errorf("reading %s template: %w", kind, err)
+		}
+		text = string(data)
+	}
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("output format %s has an empty template", kind)
+	}
+
+	data, err := toJSONValue(out)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "custom-columns", "custom-columns-file":
+		parse := parseCustomColumns
+		if kind == "custom-columns-file" {
+			parse = parseCustomColumnsFile
+		}
+		columns, err := parse(text)
+		if err != nil {
+			return err
+		}
+		return p.printCustomColumns(columns, data)
+	case "jsonpath", "jsonpath-file":
+		return p.printJSONPath(text, data)
+	default:
+		return p.printGoTemplate(text, data)
+	}
+}
+
+// toJSONValue round-trips v through JSON so templates address its fields by
+// their JSON names. Numbers are kept as written rather than as floats.
+func toJSONValue(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var data any
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (p *Printer) printJSONPath(text string, data any) error {
+	jp := jsonpath.New("output").AllowMissingKeys(true)
+	if err := jp.Parse(text); err != nil {
+		return fmt.Errorf("parsing jsonpath %s: %w", text, err)
+	}
+	return jp.Execute(p.out, data)
+}
+
+func (p *Printer) printGoTemplate(text string, data any) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing go-template: %w", err)
+	}
+	return tmpl.Execute(p.out, data)
+}
+
+// customColumn is one HEADER:path column of custom-columns output
+type customColumn struct {
+	header string
+	path   string
+}
+
+// parseCustomColumns parses comma-separated HEADER:path pairs
+func parseCustomColumns(text string) ([]customColumn, error) {
+	var columns []customColumn
+	for _, spec := range strings.Split(strings.TrimSpace(text), ",") {
+		header, path, found := strings.Cut(spec, ":")
+		if !found || header == "" || path == "" {
+			return nil, fmt.Errorf("custom-columns %q is not HEADER:path", spec)
+		}
+		columns = append(columns, customColumn{header: header, path: path})
+	}
+	return columns, nil
+}
+
+// parseCustomColumnsFile parses a custom-columns file: a line of headers and
+// a line of paths, both separated by whitespace
+func parseCustomColumnsFile(text string) ([]customColumn, error) {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("custom-columns file must have a line of headers and a line of paths, found %d lines", len(lines))
+	}
+	headers, paths := strings.Fields(lines[0]), strings.Fields(lines[1])
+	if len(headers) != len(paths) {
+		return nil, fmt.Errorf("custom-columns file has %d headers but %d paths", len(headers), len(paths))
+	}
+	columns := make([]customColumn, len(headers))
+	for i := range headers {
+		columns[i] = customColumn{header: headers[i], path: paths[i]}
+	}
+	return columns, nil
+}
+
+// relaxedJSONPath accepts the shorthand kubectl allows in custom-columns:
+// .name, name and {.name} all address the same field
+func relaxedJSONPath(path string) string {
+	if strings.HasPrefix(path, "{") {
+		return path
+	}
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+// printCustomColumns prints one row per item of data, which is a list. A
+// column with no value, or only empty ones, prints <none>; several values are
+// joined by commas.
+func (p *Printer) printCustomColumns(columns []customColumn, data any) error {
+	paths := make([]*jsonpath.JSONPath, len(columns))
+	for i, c := range columns {
+		paths[i] = jsonpath.New(c.header).AllowMissingKeys(true)
+		if err := paths[i].Parse(relaxedJSONPath(c.path)); err != nil {
+			return fmt.Errorf("parsing custom-columns path %s: %w", c.path, err)
+		}
+	}
+	items, ok := data.([]any)
+	if !ok {
+		items = []any{data}
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if !p.noHeaders {
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = c.header
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(headers, "\t")); err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		row := make([]string, len(paths))
+		for i, path := range paths {
+			results, err := path.FindResults(item)
+			if err != nil {
+				return err
+			}
+			var values []string
+			for _, set := range results {
+				for _, v := range set {
+					// A JSON null is missing, like an absent key
+					if !v.IsValid() || v.Interface() == nil {
+						continue
+					}
+					if value := fmt.Sprint(v.Interface()); value != "" {
+						values = append(values, value)
+					}
+				}
+			}
+			row[i] = "<none>"
+			if len(values) > 0 {
+				row[i] = strings.Join(values, ",")
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,212 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+func testPodBlockers() []consolidation.PodBlocker {
+	return []consolidation.PodBlocker{
+		{NodeName: "node-b", Namespace: "web", PodName: "api-0", Age: "3h", Reason: consolidation.BlockerDoNotDisrupt},
+		{NodeName: "node-b", Namespace: "db", PodName: "ledger-0", Age: "1d", Reason: consolidation.BlockerPDBViolation},
+	}
+}
+
+// writeTemplate writes text to a file for the *-file formats and returns its path
+func writeTemplate(t *testing.T, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "template")
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPrintTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		file    string // Written to a file whose path is appended to format
+		pods    bool   // Print pod blockers instead of nodes
+		data    any    // Printed instead of nodes when set
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "custom-columns",
+			format: "custom-columns=NAME:.name,POOL:poolName,RESERVATION:{.reservationId}",
+			want:   "NAME    POOL      RESERVATION\nnode-a  default   <none>\nnode-b  reserved  cr-0123456789abcdef0\n",
+		},
+		{
+			name:   "custom-columns joins list values",
+			format: "custom-columns=NAME:.name,BLOCKERS:.blockers[*]",
+			want:   "NAME    BLOCKERS\nnode-a  <none>\nnode-b  do-not-disrupt,pdb-violation\n",
+		},
+		{
+			name:   "custom-columns null values",
+			format: "custom-columns=NAME:.name,ZONE:.zone,IPS:.ips[*]",
+			data: []any{
+				map[string]any{"name": "node-a", "zone": nil, "ips": []any{nil}},
+				map[string]any{"name": "node-b", "zone": "us-east-1b", "ips": []any{"10.0.0.2", nil}},
+			},
+			want: "NAME    ZONE        IPS\nnode-a  <none>      <none>\nnode-b  us-east-1b  10.0.0.2\n",
+		},
+		{
+			name:   "custom-columns-file",
+			format: "custom-columns-file=",
+			file:   "NAME   GPU\n.name  .utilization.nvidia\\.com/gpu\n",
+			want:   "NAME    GPU\nnode-a  <none>\nnode-b  50%\n",
+		},
+		{
+			name:   "jsonpath",
+			format: `jsonpath={range [*]}{.name}={.capacityType}{"\n"}{end}`,
+			want:   "node-a=on-demand\nnode-b=reserved\n",
+		},
+		{
+			name:   "jsonpath-file",
+			format: "jsonpath-file=",
+			file:   `{range [*]}{.name} {.pods}{"\n"}{end}`,
+			want:   "node-a 5\nnode-b 3\n",
+		},
+		{
+			name:   "go-template",
+			format: `go-template={{range .}}{{.name}} {{.cpuUtilization}}{{"\n"}}{{end}}`,
+			want:   "node-a 40%\nnode-b 10%\n",
+		},
+		{
+			name:   "go-template-file",
+			format: "go-template-file=",
+			file:   `{{range .}}{{.name}} {{len .blockers}}{{"\n"}}{{end}}`,
+			want:   "node-a 0\nnode-b 2\n",
+		},
+		{
+			name:   "pod blockers",
+			format: "custom-columns=POD:.podName,REASON:.reason",
+			pods:   true,
+			want:   "POD       REASON\napi-0     do-not-disrupt\nledger-0  pdb-violation\n",
+		},
+		{
+			name:   "pod blockers jsonpath",
+			format: `jsonpath={[*].namespace}`,
+			pods:   true,
+			want:   "web db",
+		},
+		{
+			name:    "missing template",
+			format:  "jsonpath",
+			wantErr: "output format jsonpath needs a template",
+		},
+		{
+			name:    "empty template",
+			format:  "go-template= ",
+			wantErr: "output format go-template has an empty template",
+		},
+		{
+			name:    "missing file",
+			format:  "go-template-file=" + filepath.Join(os.TempDir(), "does-not-exist"),
+			wantErr: "reading go-template-file template",
+		},
+		{
+			name:    "custom-columns without a path",
+			format:  "custom-columns=NAME",
+			wantErr: `custom-columns "NAME" is not HEADER:path`,
+		},
+		{
+			name:    "custom-columns bad path",
+			format:  "custom-columns=NAME:{.name",
+			wantErr: "parsing custom-columns path {.name",
+		},
+		{
+			name:    "custom-columns-file header and path mismatch",
+			format:  "custom-columns-file=",
+			file:    "NAME POOL\n.name\n",
+			wantErr: "custom-columns file has 2 headers but 1 paths",
+		},
+		{
+			name:    "custom-columns-file one line",
+			format:  "custom-columns-file=",
+			file:    "NAME\n",
+			wantErr: "custom-columns file must have a line of headers and a line of paths, found 1 lines",
+		},
+		{
+			name:    "jsonpath parse error",
+			format:  "jsonpath={.name",
+			wantErr: "parsing jsonpath {.name",
+		},
+		{
+			name:    "jsonpath-file parse error",
+			format:  "jsonpath-file=",
+			file:    "{[*].name",
+			wantErr: "parsing jsonpath {[*].name",
+		},
+		{
+			name:    "go-template parse error",
+			format:  "go-template={{.name",
+			wantErr: "parsing go-template",
+		},
+		{
+			name:    "go-template-file parse error",
+			format:  "go-template-file=",
+			file:    "{{range .}}",
+			wantErr: "parsing go-template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := tt.format
+			if tt.file != "" {
+				format += writeTemplate(t, tt.file)
+			}
+			if !isTemplateFormat(format) {
+				t.Fatalf("isTemplateFormat(%q) = false", format)
+			}
+			p, buf := newTestPrinter(format)
+
+			var err error
+			switch {
+			case tt.data != nil:
+				err = p.printTemplate(tt.data)
+			case tt.pods:
+				err = p.printTemplate(podBlockersToOutput(testPodBlockers()))
+			default:
+				err = p.printTemplate(p.nodesToOutput(testNodes(), testNow))
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("printTemplate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("printTemplate() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("printTemplate() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownOutputFormat(t *testing.T) {
+	for _, format := range []string{"jsn", "table", "jsonpaths={.name}"} {
+		t.Run(format, func(t *testing.T) {
+			p, buf := newTestPrinter(format)
+			want := `unable to match a printer suitable for the output format "` + format + `"`
+			if err := p.PrintNodes(testNodes()); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("PrintNodes() error = %v, want %q", err, want)
+			}
+			if err := p.PrintPodBlockers(testPodBlockers()); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("PrintPodBlockers() error = %v, want %q", err, want)
+			}
+			if buf.Len() > 0 {
+				t.Errorf("printed %q for an unknown format", buf.String())
+			}
+		})
+	}
+}
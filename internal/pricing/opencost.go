@@ -0,0 +1,104 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeCosts holds hourly node costs keyed by node name
+type NodeCosts map[string]float64
+
+// NodePrice returns the node's hourly cost
+func (c NodeCosts) NodePrice(node *corev1.Node) (float64, bool) {
+	price, ok := c[node.Name]
+	return price, ok
+}
+
+// openCostWindow is the window costs are averaged over
+const openCostWindow = "1d"
+
+// openCostAsset is the part of an OpenCost or Kubecost asset FetchOpenCost reads
+type openCostAsset struct {
+	Type       string `json:"type"`
+	Properties struct {
+		Name string `json:"name"`
+	} `json:"properties"`
+	TotalCost float64 `json:"totalCost"`
+	Minutes   float64 `json:"minutes"`
+}
+
+// openCostResponse is an assets API response. OpenCost returns one set of
+// assets keyed by asset ID; Kubecost returns a list of sets, one per step.
+type openCostResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// FetchOpenCost reads hourly node costs from the assets API of an OpenCost
+// or Kubecost server, e.g. http://localhost:9003 for OpenCost or
+// http://localhost:9090/model for Kubecost. A node's hourly cost is its total
+// cost over the last day divided by the hours it ran.
+func FetchOpenCost(ctx context.Context, client *http.Client, baseURL string) (NodeCosts, error) {
+	query := url.Values{"window": {openCostWindow}, "accumulate": {"true"}, "filterTypes": {"Node"}}
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/assets?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	var body openCostResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode assets: %w", err)
+	}
+	if body.Code != 0 && body.Code != http.StatusOK {
+		return nil, fmt.Errorf("assets API error %d: %s", body.Code, body.Message)
+	}
+	return parseOpenCostAssets(body.Data)
+}
+
+// parseOpenCostAssets sums each node's cost and minutes over every asset set
+func parseOpenCostAssets(data json.RawMessage) (NodeCosts, error) {
+	var sets []map[string]openCostAsset
+	if err := json.Unmarshal(data, &sets); err != nil {
+		var set map[string]openCostAsset
+		if err := json.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("unexpected assets data: %w", err)
+		}
+		sets = []map[string]openCostAsset{set}
+	}
+
+	cost := make(map[string]float64)
+	minutes := make(map[string]float64)
+	for _, set := range sets {
+		for _, asset := range set {
+			if asset.Type != "Node" || asset.Properties.Name == "" {
+				continue
+			}
+			cost[asset.Properties.Name] += asset.TotalCost
+			minutes[asset.Properties.Name] += asset.Minutes
+		}
+	}
+
+	costs := make(NodeCosts, len(cost))
+	for name, total := range cost {
+		if minutes[name] > 0 {
+			costs[name] = total / (minutes[name] / 60)
+		}
+	}
+	return costs, nil
+}
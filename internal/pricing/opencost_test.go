@@ -0,0 +1,94 @@
+package pricing
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchOpenCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string // Base URL path the client is given
+		status  int
+		body    string
+		want    NodeCosts
+		wantErr bool
+	}{
+		{
+			name:   "opencost accumulated set",
+			status: http.StatusOK,
+			body: `{"code":200,"data":{
+				"node-1-id":{"type":"Node","properties":{"name":"node-1"},"totalCost":4.8,"minutes":1440},
+				"node-2-id":{"type":"Node","properties":{"name":"node-2"},"totalCost":1.2,"minutes":720},
+				"disk-id":{"type":"Disk","properties":{"name":"disk-1"},"totalCost":9,"minutes":1440}
+			}}`,
+			want: NodeCosts{"node-1": 0.2, "node-2": 0.1},
+		},
+		{
+			name:   "kubecost list of sets",
+			path:   "/model",
+			status: http.StatusOK,
+			body: `{"code":200,"data":[
+				{"a":{"type":"Node","properties":{"name":"node-1"},"totalCost":2,"minutes":720}},
+				{"b":{"type":"Node","properties":{"name":"node-1"},"totalCost":4,"minutes":720}}
+			]}`,
+			want: NodeCosts{"node-1": 0.25},
+		},
+		{
+			name:   "node that never ran is skipped",
+			status: http.StatusOK,
+			body:   `{"data":{"a":{"type":"Node","properties":{"name":"node-1"},"totalCost":0,"minutes":0}}}`,
+			want:   NodeCosts{},
+		},
+		{
+			name:    "http error",
+			status:  http.StatusInternalServerError,
+			body:    `oops`,
+			wantErr: true,
+		},
+		{
+			name:    "api error",
+			status:  http.StatusOK,
+			body:    `{"code":400,"message":"bad window"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unexpected data",
+			status:  http.StatusOK,
+			body:    `{"code":200,"data":"nope"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path+"/assets" {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.path+"/assets")
+				}
+				if got := r.URL.Query().Get("filterTypes"); got != "Node" {
+					t.Errorf("filterTypes = %q, want Node", got)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := FetchOpenCost(context.Background(), server.Client(), server.URL+tt.path+"/")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchOpenCost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("FetchOpenCost() = %v, want %v", got, tt.want)
+			}
+			for name, want := range tt.want {
+				if math.Abs(got[name]-want) > 1e-9 {
+					t.Errorf("cost of %s = %v, want %v", name, got[name], want)
+				}
+			}
+		})
+	}
+}
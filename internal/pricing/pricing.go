@@ -0,0 +1,111 @@
+// Package pricing reads hourly node prices, either from an offline price list
+// keyed by instance type, capacity type and zone or from an OpenCost server.
+package pricing
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// Price is the hourly price of an instance type for one capacity type, in one
+// zone or, when Zone is empty, in every zone without a more specific price
+type Price struct {
+	InstanceType string  `yaml:"instanceType"`
+	CapacityType string  `yaml:"capacityType"`
+	Zone         string  `yaml:"zone"`
+	Hourly       float64 `yaml:"hourly"`
+}
+
+type priceKey struct {
+	instanceType string
+	capacityType string
+	zone         string
+}
+
+// PriceList looks up hourly node prices
+type PriceList struct {
+	prices map[priceKey]float64
+}
+
+// Lookup returns the hourly price for an instance type and capacity type in a
+// zone, falling back to the price for any zone
+func (l *PriceList) Lookup(instanceType, capacityType, zone string) (float64, bool) {
+	if price, ok := l.prices[priceKey{instanceType, capacityType, zone}]; ok {
+		return price, true
+	}
+	price, ok := l.prices[priceKey{instanceType, capacityType, ""}]
+	return price, ok
+}
+
+// NodePrice returns the hourly price of a node from its instance-type,
+// capacity-type and zone labels. Nodes without a capacity-type label are
+// priced as on-demand. Reserved nodes only have a price when the list has a
+// reserved one.
+func (l *PriceList) NodePrice(node *corev1.Node) (float64, bool) {
+	instanceType := node.Labels[corev1.LabelInstanceTypeStable]
+	if instanceType == "" {
+		return 0, false
+	}
+	capacityType := karpenter.GetCapacityType(node)
+	if capacityType == "" {
+		capacityType = karpenter.CapacityTypeOnDemand
+	}
+	zone := node.Labels[corev1.LabelTopologyZone]
+	return l.Lookup(instanceType, capacityType, zone)
+}
+
+// pricingFile is the on-disk format read by Load:
+//
+//	prices:
+//	  - instanceType: m5.large
+//	    capacityType: on-demand
+//	    hourly: 0.096
+//	  - instanceType: m5.large
+//	    capacityType: spot
+//	    zone: us-east-1a
+//	    hourly: 0.035
+type pricingFile struct {
+	Prices []Price `yaml:"prices"`
+}
+
+// Load reads a price list from a YAML or JSON file
+func Load(path string) (*PriceList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	list, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return list, nil
+}
+
+// Parse decodes a price list from YAML or JSON
+func Parse(data []byte) (*PriceList, error) {
+	var file pricingFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing: %w", err)
+	}
+
+	list := &PriceList{prices: make(map[priceKey]float64, len(file.Prices))}
+	for i, p := range file.Prices {
+		if p.InstanceType == "" || p.CapacityType == "" {
+			return nil, fmt.Errorf("price %d: instanceType and capacityType are required", i+1)
+		}
+		if p.Hourly < 0 {
+			return nil, fmt.Errorf("price %d: negative hourly price for %s", i+1, p.InstanceType)
+		}
+		key := priceKey{p.InstanceType, p.CapacityType, p.Zone}
+		if _, ok := list.prices[key]; ok {
+			return nil, fmt.Errorf("price %d: duplicate price for %s %s %s", i+1, p.InstanceType, p.CapacityType, p.Zone)
+		}
+		list.prices[key] = p.Hourly
+	}
+	return list, nil
+}
@@ -0,0 +1,132 @@
+package pricing
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+const testPricing = `
+prices:
+  - instanceType: m5.large
+    capacityType: on-demand
+    hourly: 0.096
+  - instanceType: m5.large
+    capacityType: spot
+    hourly: 0.04
+  - instanceType: m5.large
+    capacityType: spot
+    zone: us-east-1a
+    hourly: 0.035
+  - instanceType: m5.large
+    capacityType: reserved
+    hourly: 0.06
+  - instanceType: m5.xlarge
+    capacityType: on-demand
+    hourly: 0.192
+`
+
+func TestNodePrice(t *testing.T) {
+	list, err := Parse([]byte(testPricing))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+		wantOK bool
+	}{
+		{
+			name:   "zone-specific price",
+			labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.large", karpenter.LabelCapacityType: "spot", corev1.LabelTopologyZone: "us-east-1a"},
+			want:   0.035,
+			wantOK: true,
+		},
+		{
+			name:   "falls back to any zone",
+			labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.large", karpenter.LabelCapacityType: "spot", corev1.LabelTopologyZone: "us-east-1b"},
+			want:   0.04,
+			wantOK: true,
+		},
+		{
+			name:   "reserved",
+			labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.large", karpenter.LabelCapacityType: "reserved"},
+			want:   0.06,
+			wantOK: true,
+		},
+		{
+			name:   "reserved without a reserved price",
+			labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.xlarge", karpenter.LabelCapacityType: "reserved"},
+			wantOK: false,
+		},
+		{
+			name:   "no capacity type is on-demand",
+			labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.large"},
+			want:   0.096,
+			wantOK: true,
+		},
+		{
+			name:   "unknown instance type",
+			labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.2xlarge"},
+		},
+		{
+			name:   "no instance type",
+			labels: map[string]string{karpenter.LabelCapacityType: "spot"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: tt.labels}}
+			got, ok := list.NodePrice(node)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("NodePrice() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "missing capacity type",
+			data:    "prices:\n  - instanceType: m5.large\n    hourly: 0.1\n",
+			wantErr: "capacityType are required",
+		},
+		{
+			name:    "negative price",
+			data:    "prices:\n  - instanceType: m5.large\n    capacityType: spot\n    hourly: -1\n",
+			wantErr: "negative",
+		},
+		{
+			name: "duplicate",
+			data: "prices:\n  - instanceType: m5.large\n    capacityType: spot\n    hourly: 0.1\n" +
+				"  - instanceType: m5.large\n    capacityType: spot\n    hourly: 0.2\n",
+			wantErr: "duplicate",
+		},
+		{
+			name:    "invalid yaml",
+			data:    "prices: [",
+			wantErr: "failed to parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
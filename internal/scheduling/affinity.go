@@ -0,0 +1,259 @@
+package scheduling
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// matchesNodeSelector reports whether the node has every label in the pod's nodeSelector
+func matchesNodeSelector(pod *corev1.Pod, node *corev1.Node) bool {
+	for key, value := range pod.Spec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesRequiredNodeAffinity checks requiredDuringSchedulingIgnoredDuringExecution
+func matchesRequiredNodeAffinity(pod *corev1.Pod, node *corev1.Node) bool {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	return MatchNodeSelectorTerms(node, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+}
+
+// MatchNodeSelectorTerms reports whether the node matches any of the terms.
+// Terms are ORed; the requirements within a term are ANDed.
+func MatchNodeSelectorTerms(node *corev1.Node, terms []corev1.NodeSelectorTerm) bool {
+	for _, term := range terms {
+		// A term without requirements matches nothing
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if karpenter.MatchRequirements(node.Labels, term.MatchExpressions) && matchFields(node, term.MatchFields) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFields supports the only field the scheduler allows, metadata.name
+func matchFields(node *corev1.Node, reqs []corev1.NodeSelectorRequirement) bool {
+	for _, req := range reqs {
+		if req.Key != "metadata.name" {
+			return false
+		}
+		r, err := karpenter.LabelRequirement(req)
+		if err != nil || !r.Matches(labels.Set{req.Key: node.Name}) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPodAffinity enforces required pod affinity and anti-affinity, including
+// the anti-affinity of pods already in the target's topology domain
+func (c *Cluster) checkPodAffinity(pod *corev1.Pod, node *NodeState) string {
+	if affinity := pod.Spec.Affinity; affinity != nil {
+		if affinity.PodAntiAffinity != nil {
+			for i := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				term := &affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i]
+				if c.domainHasMatch(term, pod.Namespace, node) {
+					return ReasonPodAntiAffinity
+				}
+			}
+		}
+		if affinity.PodAffinity != nil {
+			for i := range affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				term := &affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i]
+				if _, ok := node.Node.Labels[term.TopologyKey]; !ok {
+					return ReasonPodAffinity
+				}
+				if c.domainHasMatch(term, pod.Namespace, node) {
+					continue
+				}
+				// The first pod of a group that selects itself may go anywhere
+				if !termMatches(term, pod.Namespace, pod) || c.anyPodMatches(term, pod.Namespace) {
+					return ReasonPodAffinity
+				}
+			}
+		}
+	}
+
+	// Existing pods whose anti-affinity rejects the incoming pod
+	for _, other := range c.nodes {
+		for _, existing := range other.Pods {
+			affinity := existing.Spec.Affinity
+			if affinity == nil || affinity.PodAntiAffinity == nil {
+				continue
+			}
+			for i := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				term := &affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i]
+				if sameDomain(other.Node, node.Node, term.TopologyKey) && termMatches(term, existing.Namespace, pod) {
+					return ReasonPodAntiAffinity
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// domainHasMatch reports whether any pod in the node's topology domain matches the term
+func (c *Cluster) domainHasMatch(term *corev1.PodAffinityTerm, namespace string, node *NodeState) bool {
+	for _, other := range c.nodes {
+		if !sameDomain(other.Node, node.Node, term.TopologyKey) {
+			continue
+		}
+		for _, existing := range other.Pods {
+			if termMatches(term, namespace, existing) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *Cluster) anyPodMatches(term *corev1.PodAffinityTerm, namespace string) bool {
+	for _, other := range c.nodes {
+		for _, existing := range other.Pods {
+			if termMatches(term, namespace, existing) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameDomain reports whether both nodes carry the same value for the topology key
+func sameDomain(a, b *corev1.Node, key string) bool {
+	va, ok := a.Labels[key]
+	if !ok {
+		return false
+	}
+	vb, ok := b.Labels[key]
+	return ok && va == vb
+}
+
+// termMatches reports whether target is selected by a pod affinity term declared
+// by a pod in namespace. Namespace selectors cannot be evaluated without namespace
+// labels, so a non-nil namespaceSelector is treated as matching every namespace.
+func termMatches(term *corev1.PodAffinityTerm, namespace string, target *corev1.Pod) bool {
+	if term.NamespaceSelector == nil {
+		namespaces := term.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{namespace}
+		}
+		found := false
+		for _, ns := range namespaces {
+			if ns == target.Namespace {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return selectorMatches(term.LabelSelector, target.Labels)
+}
+
+// selectorMatches treats a nil selector as matching nothing
+func selectorMatches(selector *metav1.LabelSelector, podLabels map[string]string) bool {
+	if selector == nil {
+		return false
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set(podLabels))
+}
+
+// satisfiesTopologySpread checks every DoNotSchedule topology spread constraint
+func (c *Cluster) satisfiesTopologySpread(pod *corev1.Pod, node *NodeState) bool {
+	for i := range pod.Spec.TopologySpreadConstraints {
+		constraint := &pod.Spec.TopologySpreadConstraints[i]
+		if constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+			continue
+		}
+		domain, ok := node.Node.Labels[constraint.TopologyKey]
+		if !ok {
+			return false
+		}
+
+		selector := spreadSelector(constraint, pod)
+		counts := c.spreadCounts(pod, constraint, selector)
+
+		minCount := -1
+		for _, count := range counts {
+			if minCount < 0 || count < minCount {
+				minCount = count
+			}
+		}
+		if minCount < 0 || (constraint.MinDomains != nil && int32(len(counts)) < *constraint.MinDomains) {
+			minCount = 0
+		}
+
+		selfMatch := 0
+		if selectorMatches(selector, pod.Labels) {
+			selfMatch = 1
+		}
+		if int32(counts[domain]+selfMatch-minCount) > constraint.MaxSkew {
+			return false
+		}
+	}
+	return true
+}
+
+// spreadSelector adds matchLabelKeys from the incoming pod to the constraint's selector
+func spreadSelector(constraint *corev1.TopologySpreadConstraint, pod *corev1.Pod) *metav1.LabelSelector {
+	if constraint.LabelSelector == nil {
+		return nil
+	}
+	selector := constraint.LabelSelector.DeepCopy()
+	for _, key := range constraint.MatchLabelKeys {
+		if value, ok := pod.Labels[key]; ok {
+			if selector.MatchLabels == nil {
+				selector.MatchLabels = make(map[string]string)
+			}
+			selector.MatchLabels[key] = value
+		}
+	}
+	return selector
+}
+
+// spreadCounts counts matching pods per topology domain over the nodes the pod
+// could be scheduled to, honoring nodeAffinityPolicy and nodeTaintsPolicy
+func (c *Cluster) spreadCounts(pod *corev1.Pod, constraint *corev1.TopologySpreadConstraint, selector *metav1.LabelSelector) map[string]int {
+	counts := make(map[string]int)
+	for _, other := range c.nodes {
+		domain, ok := other.Node.Labels[constraint.TopologyKey]
+		if !ok {
+			continue
+		}
+		honorAffinity := constraint.NodeAffinityPolicy == nil || *constraint.NodeAffinityPolicy == corev1.NodeInclusionPolicyHonor
+		if honorAffinity && (!matchesNodeSelector(pod, other.Node) || !matchesRequiredNodeAffinity(pod, other.Node)) {
+			continue
+		}
+		honorTaints := constraint.NodeTaintsPolicy != nil && *constraint.NodeTaintsPolicy == corev1.NodeInclusionPolicyHonor
+		if honorTaints && !toleratesTaints(pod, other.Node) {
+			continue
+		}
+
+		// Domains with no matching pods still count towards the minimum
+		if _, seen := counts[domain]; !seen {
+			counts[domain] = 0
+		}
+		for _, existing := range other.Pods {
+			if existing.Namespace == pod.Namespace && existing.DeletionTimestamp == nil && selectorMatches(selector, existing.Labels) {
+				counts[domain]++
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,232 @@
+package scheduling
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// MinSpotToSpotInstanceTypes is how many instance types Karpenter needs to
+// choose from before it replaces a spot node with a cheaper spot node
+const MinSpotToSpotInstanceTypes = 15
+
+// CapacityTypeUsage is the nodes of one capacity type in a pool
+type CapacityTypeUsage struct {
+	CapacityType string // Empty when nodes carry no capacity-type label
+	Nodes        int
+	Requested    corev1.ResourceList // CPU and memory requested by every pod
+	Allocatable  corev1.ResourceList // CPU and memory allocatable
+	Reservations []string            // Capacity reservation IDs the nodes run in, sorted
+}
+
+// CapacityMix is the capacity-type makeup of one NodePool
+type CapacityMix struct {
+	PoolName string
+	Types    []CapacityTypeUsage // On-demand, spot and reserved first, then the rest by name
+	// ProtectedOnDemand counts on-demand nodes with the on-demand-protection
+	// blocker, which Karpenter has reported it will not consolidate
+	ProtectedOnDemand int
+	// SpotTolerantOnDemand counts the other on-demand nodes whose movable pods
+	// would all still schedule if the node were spot
+	SpotTolerantOnDemand int
+	PoolKnown            bool // The NodePool spec was read; AllowsSpot is only meaningful then
+	AllowsSpot           bool
+	// InstanceTypes is how many instance types the pool allows for spot, when
+	// known from an instance-type requirement or the catalog
+	InstanceTypes      int
+	InstanceTypesKnown bool
+}
+
+// Nodes returns the pool's node count of one capacity type
+func (m CapacityMix) Nodes(capacityType string) int {
+	for _, t := range m.Types {
+		if t.CapacityType == capacityType {
+			return t.Nodes
+		}
+	}
+	return 0
+}
+
+// SpotToSpot reports whether Karpenter can consolidate the pool's spot nodes
+// onto cheaper spot nodes, with the reason when it cannot or it is unclear.
+// Spot-to-spot consolidation also needs Karpenter's SpotToSpotConsolidation
+// feature gate, which is not visible from the cluster.
+func (m CapacityMix) SpotToSpot() (possible bool, reason string) {
+	switch {
+	case !m.PoolKnown:
+		return false, "NodePool not found"
+	case !m.AllowsSpot:
+		return false, "spot not allowed"
+	case !m.InstanceTypesKnown:
+		return true, "instance types not restricted"
+	case m.InstanceTypes < MinSpotToSpotInstanceTypes:
+		return false, fmt.Sprintf("%d instance types, needs %d", m.InstanceTypes, MinSpotToSpotInstanceTypes)
+	default:
+		return true, fmt.Sprintf("%d instance types", m.InstanceTypes)
+	}
+}
+
+// capacityTypeOrder puts the well-known capacity types first
+var capacityTypeOrder = map[string]int{
+	karpenter.CapacityTypeOnDemand: 0,
+	karpenter.CapacityTypeSpot:     1,
+	karpenter.CapacityTypeReserved: 2,
+}
+
+// CapacityMix summarizes each NodePool's nodes by capacity type. pools holds
+// the NodePool specs by name and states the nodes' blocker states; either may
+// be nil. With a catalog, the instance types a pool allows are counted from it;
+// otherwise only an instance-type In requirement gives a count. Nodes not
+// managed by Karpenter are left out.
+func (c *Cluster) CapacityMix(pools map[string]*karpenter.NodePool, states map[string]*consolidation.BlockerState) []CapacityMix {
+	byPool := make(map[string]*CapacityMix)
+	var names []string
+	for _, state := range c.nodes {
+		poolName, _ := karpenter.GetPoolName(state.Node)
+		if poolName == "" {
+			continue
+		}
+		mix, ok := byPool[poolName]
+		if !ok {
+			mix = &CapacityMix{PoolName: poolName}
+			if pool := pools[poolName]; pool != nil {
+				mix.PoolKnown = true
+				mix.AllowsSpot = allowsCapacityType(pool.Requirements, karpenter.CapacityTypeSpot)
+				mix.InstanceTypes, mix.InstanceTypesKnown = c.spotInstanceTypes(pool)
+			}
+			byPool[poolName] = mix
+			names = append(names, poolName)
+		}
+
+		capacityType := karpenter.GetCapacityType(state.Node)
+		usage := mix.usage(capacityType)
+		usage.Nodes++
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			addQuantity(usage.Requested, name, state.Requested())
+			addQuantity(usage.Allocatable, name, state.Node.Status.Allocatable)
+		}
+		if id := karpenter.GetReservationID(state.Node); id != "" && !slices.Contains(usage.Reservations, id) {
+			usage.Reservations = append(usage.Reservations, id)
+			sort.Strings(usage.Reservations)
+		}
+		if capacityType == karpenter.CapacityTypeOnDemand {
+			switch {
+			case onDemandProtected(states[state.Node.Name]):
+				mix.ProtectedOnDemand++
+			case toleratesSpot(state):
+				mix.SpotTolerantOnDemand++
+			}
+		}
+	}
+
+	sort.Strings(names)
+	mixes := make([]CapacityMix, len(names))
+	for i, name := range names {
+		mix := byPool[name]
+		sort.Slice(mix.Types, func(a, b int) bool {
+			oa, okA := capacityTypeOrder[mix.Types[a].CapacityType]
+			ob, okB := capacityTypeOrder[mix.Types[b].CapacityType]
+			if okA != okB {
+				return okA
+			}
+			if oa != ob {
+				return oa < ob
+			}
+			return mix.Types[a].CapacityType < mix.Types[b].CapacityType
+		})
+		mixes[i] = *mix
+	}
+	return mixes
+}
+
+func (m *CapacityMix) usage(capacityType string) *CapacityTypeUsage {
+	for i := range m.Types {
+		if m.Types[i].CapacityType == capacityType {
+			return &m.Types[i]
+		}
+	}
+	m.Types = append(m.Types, CapacityTypeUsage{
+		CapacityType: capacityType,
+		Requested:    corev1.ResourceList{},
+		Allocatable:  corev1.ResourceList{},
+	})
+	return &m.Types[len(m.Types)-1]
+}
+
+func addQuantity(total corev1.ResourceList, name corev1.ResourceName, from corev1.ResourceList) {
+	q, ok := from[name]
+	if !ok {
+		return
+	}
+	sum := total[name]
+	sum.Add(q)
+	total[name] = sum
+}
+
+// allowsCapacityType reports whether a pool's requirements admit a capacity
+// type. Karpenter launches on-demand nodes when a pool does not constrain it,
+// and spot or reserved nodes only when a requirement allows them.
+func allowsCapacityType(reqs []corev1.NodeSelectorRequirement, capacityType string) bool {
+	var constraints []corev1.NodeSelectorRequirement
+	for _, req := range reqs {
+		if req.Key == karpenter.LabelCapacityType {
+			constraints = append(constraints, req)
+		}
+	}
+	if len(constraints) == 0 {
+		return capacityType == karpenter.CapacityTypeOnDemand
+	}
+	return karpenter.MatchRequirements(map[string]string{karpenter.LabelCapacityType: capacityType}, constraints)
+}
+
+// spotInstanceTypes counts the instance types a pool allows for spot: the
+// catalog types with a spot price that satisfy the pool's requirements, or
+// without a catalog the values of an instance-type In requirement
+func (c *Cluster) spotInstanceTypes(pool *karpenter.NodePool) (int, bool) {
+	if c.catalog != nil {
+		seen := make(map[string]bool)
+		for _, offering := range c.catalog.Offerings() {
+			if offering.CapacityType != karpenter.CapacityTypeSpot {
+				continue
+			}
+			labels := offering.InstanceType.NodeLabels()
+			labels[karpenter.LabelCapacityType] = offering.CapacityType
+			if allowedByPool(labels, pool.Requirements) {
+				seen[offering.InstanceType.Name] = true
+			}
+		}
+		return len(seen), true
+	}
+	for _, req := range pool.Requirements {
+		if req.Key == corev1.LabelInstanceTypeStable && req.Operator == corev1.NodeSelectorOpIn {
+			return len(req.Values), true
+		}
+	}
+	return 0, false
+}
+
+// toleratesSpot reports whether every movable pod on the node would still match
+// its nodeSelector and required node affinity if the node were spot. Taints
+// specific to spot nodes are not considered.
+func toleratesSpot(state *NodeState) bool {
+	spot := state.Node.DeepCopy()
+	spot.Labels[karpenter.LabelCapacityType] = karpenter.CapacityTypeSpot
+	movable, _ := splitPods(state.Pods)
+	for _, pod := range movable {
+		if !matchesNodeSelector(pod, spot) || !matchesRequiredNodeAffinity(pod, spot) {
+			return false
+		}
+	}
+	return true
+}
+
+// onDemandProtected reports whether Karpenter has reported the on-demand-protection
+// blocker for a node
+func onDemandProtected(state *consolidation.BlockerState) bool {
+	return state != nil && slices.Contains(state.Blockers(), consolidation.BlockerOnDemandProtection)
+}
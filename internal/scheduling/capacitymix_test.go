@@ -0,0 +1,120 @@
+package scheduling
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/catalog"
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestCapacityMix(t *testing.T) {
+	poolNode := func(name, pool, capacityType string) corev1.Node {
+		node := testNode(name, "z1", "4")
+		if pool != "" {
+			node.Labels[karpenter.LabelNodePool] = pool
+		}
+		node.Labels[karpenter.LabelCapacityType] = capacityType
+		return node
+	}
+	onDemandOnly := func(p *corev1.Pod) {
+		p.Spec.NodeSelector = map[string]string{karpenter.LabelCapacityType: karpenter.CapacityTypeOnDemand}
+	}
+
+	nodes := []corev1.Node{
+		poolNode("a", "default", karpenter.CapacityTypeOnDemand),
+		poolNode("b", "default", karpenter.CapacityTypeOnDemand),
+		poolNode("c", "default", karpenter.CapacityTypeSpot),
+		poolNode("d", "od", karpenter.CapacityTypeOnDemand),
+		poolNode("e", "missing", karpenter.CapacityTypeReserved),
+		poolNode("g", "missing", karpenter.CapacityTypeReserved),
+		poolNode("f", "", karpenter.CapacityTypeOnDemand),
+	}
+	nodes[4].Labels[karpenter.LabelCapacityReservationID] = "cr-1"
+	nodes[5].Labels[karpenter.LabelCapacityReservationID] = "cr-1"
+	podsByNode := map[string][]corev1.Pod{
+		"a": {testPod("a1", "1"), testPod("a2", "500m")},
+		"b": {testPod("b1", "2", onDemandOnly)},
+		"c": {testPod("c1", "1")},
+	}
+	pools := map[string]*karpenter.NodePool{
+		"default": {Name: "default", Requirements: []corev1.NodeSelectorRequirement{
+			{Key: karpenter.LabelCapacityType, Operator: corev1.NodeSelectorOpIn, Values: []string{"spot", "on-demand"}},
+			{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"m5.large", "m5.xlarge", "m6i.large"}},
+		}},
+		"od": {Name: "od"},
+	}
+
+	mixes := NewCluster(nodes, podsByNode, nil).CapacityMix(pools, nil)
+	if len(mixes) != 3 {
+		t.Fatalf("got %d pools, want 3: %+v", len(mixes), mixes)
+	}
+	def, missing, od := mixes[0], mixes[1], mixes[2]
+
+	if def.PoolName != "default" || def.Nodes(karpenter.CapacityTypeOnDemand) != 2 || def.Nodes(karpenter.CapacityTypeSpot) != 1 {
+		t.Errorf("default = %+v, want 2 on-demand and 1 spot", def)
+	}
+	if def.Types[0].CapacityType != karpenter.CapacityTypeOnDemand {
+		t.Errorf("first capacity type = %q, want on-demand", def.Types[0].CapacityType)
+	}
+	if got := def.Types[0].Requested[corev1.ResourceCPU]; got.Cmp(resource.MustParse("3500m")) != 0 {
+		t.Errorf("on-demand requested CPU = %s, want 3500m", got.String())
+	}
+	if got := def.Types[0].Allocatable[corev1.ResourceCPU]; got.Cmp(resource.MustParse("8")) != 0 {
+		t.Errorf("on-demand allocatable CPU = %s, want 8", got.String())
+	}
+	if def.SpotTolerantOnDemand != 1 {
+		t.Errorf("SpotTolerantOnDemand = %d, want 1 (b requires on-demand)", def.SpotTolerantOnDemand)
+	}
+	if def.ProtectedOnDemand != 0 {
+		t.Errorf("ProtectedOnDemand = %d, want 0 without blocker states", def.ProtectedOnDemand)
+	}
+	if ok, reason := def.SpotToSpot(); ok || reason != "3 instance types, needs 15" {
+		t.Errorf("default SpotToSpot() = %v, %q", ok, reason)
+	}
+
+	if ok, reason := od.SpotToSpot(); ok || reason != "spot not allowed" {
+		t.Errorf("od SpotToSpot() = %v, %q", ok, reason)
+	}
+	if missing.Nodes(karpenter.CapacityTypeReserved) != 2 {
+		t.Errorf("missing = %+v, want 2 reserved nodes", missing)
+	}
+	if got := missing.Types[0].Reservations; len(got) != 1 || got[0] != "cr-1" {
+		t.Errorf("Reservations = %v, want [cr-1]", got)
+	}
+	if ok, reason := missing.SpotToSpot(); ok || reason != "NodePool not found" {
+		t.Errorf("missing SpotToSpot() = %v, %q", ok, reason)
+	}
+
+	// A node Karpenter keeps on-demand is protected, not spot-tolerant
+	states := map[string]*consolidation.BlockerState{
+		"a": consolidation.NewBlockerState(&nodes[0], podsByNode["a"], []corev1.Event{
+			{Reason: "DisruptionBlocked", Message: "Cannot disrupt Node: on-demand protection"},
+		}, nil, consolidation.DefaultThresholds()),
+		"b": consolidation.NewBlockerState(&nodes[1], podsByNode["b"], nil, nil, consolidation.DefaultThresholds()),
+	}
+	protected := NewCluster(nodes, podsByNode, nil).CapacityMix(pools, states)[0]
+	if protected.ProtectedOnDemand != 1 || protected.SpotTolerantOnDemand != 0 {
+		t.Errorf("with on-demand protection on a: ProtectedOnDemand = %d, SpotTolerantOnDemand = %d, want 1 and 0",
+			protected.ProtectedOnDemand, protected.SpotTolerantOnDemand)
+	}
+
+	// With a catalog, instance types come from the catalog's spot offerings
+	cat, err := catalog.Parse([]byte(replaceCatalog))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	cluster := NewCluster(nodes, podsByNode, nil)
+	cluster.SetCatalog(cat, pools)
+	pools["default"].Requirements = pools["default"].Requirements[:1]
+	if ok, reason := cluster.CapacityMix(pools, nil)[0].SpotToSpot(); ok || reason != "2 instance types, needs 15" {
+		t.Errorf("catalog SpotToSpot() = %v, %q", ok, reason)
+	}
+	cluster = NewCluster(nodes, podsByNode, nil)
+	if ok, reason := cluster.CapacityMix(pools, nil)[0].SpotToSpot(); !ok || reason != "instance types not restricted" {
+		t.Errorf("unrestricted SpotToSpot() = %v, %q", ok, reason)
+	}
+}
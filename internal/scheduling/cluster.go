@@ -0,0 +1,192 @@
+// Package scheduling simulates placing pods on nodes the way the kube-scheduler
+// and Karpenter do, so consolidation decisions can be reproduced offline.
+package scheduling
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/catalog"
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// NodeState is a node and the pods bound to it at a point in a simulation
+type NodeState struct {
+	Node *corev1.Node
+	Pods []*corev1.Pod
+	// Schedulable is false for cordoned, NotReady and disrupting nodes, which
+	// keep their pods but accept no new ones
+	Schedulable bool
+
+	requested corev1.ResourceList
+	// volumes holds the CSI volume handles attached to the node, by driver
+	volumes map[string]map[string]bool
+}
+
+// Requested returns the summed effective requests of the node's pods
+func (n *NodeState) Requested() corev1.ResourceList {
+	return n.requested
+}
+
+func (n *NodeState) clone() *NodeState {
+	out := &NodeState{
+		Node:        n.Node,
+		Pods:        append([]*corev1.Pod(nil), n.Pods...),
+		Schedulable: n.Schedulable,
+		requested:   n.requested.DeepCopy(),
+		volumes:     make(map[string]map[string]bool, len(n.volumes)),
+	}
+	for driver, handles := range n.volumes {
+		out.volumes[driver] = make(map[string]bool, len(handles))
+		for handle := range handles {
+			out.volumes[driver][handle] = true
+		}
+	}
+	return out
+}
+
+// Cluster is a mutable copy of the cluster's nodes and pod bindings
+type Cluster struct {
+	nodes   []*NodeState
+	byName  map[string]*NodeState
+	volumes *Volumes
+	catalog *catalog.Catalog
+	pools   map[string]*karpenter.NodePool
+}
+
+// NewCluster builds a simulation from the current nodes and pods. Terminal pods
+// are ignored. volumes may be nil, in which case volume checks are skipped.
+func NewCluster(nodes []corev1.Node, podsByNode map[string][]corev1.Pod, volumes *Volumes) *Cluster {
+	c := &Cluster{
+		byName:  make(map[string]*NodeState, len(nodes)),
+		volumes: volumes,
+	}
+	for i := range nodes {
+		node := &nodes[i]
+		pods := podsByNode[node.Name]
+		state := c.AddNode(node, isSchedulable(node))
+		for j := range pods {
+			if pods[j].Status.Phase == corev1.PodSucceeded || pods[j].Status.Phase == corev1.PodFailed {
+				continue
+			}
+			c.bind(state, &pods[j])
+		}
+	}
+	return c
+}
+
+// NewClusterFromSnapshot builds a simulation from a collected snapshot
+func NewClusterFromSnapshot(snap *consolidation.ClusterSnapshot) *Cluster {
+	return NewCluster(snap.Nodes, snap.PodsByNode, NewVolumes(snap.PVCs, snap.PVs, snap.CSINodes))
+}
+
+// isSchedulable reports whether a node can accept new pods
+func isSchedulable(node *corev1.Node) bool {
+	if node.Spec.Unschedulable || consolidation.IsDisrupting(node) {
+		return false
+	}
+	return consolidation.GetNodeStatus(node) == "Ready"
+}
+
+// Clone returns an independent copy that can be modified without affecting c
+func (c *Cluster) Clone() *Cluster {
+	out := &Cluster{
+		nodes:   make([]*NodeState, len(c.nodes)),
+		byName:  make(map[string]*NodeState, len(c.nodes)),
+		volumes: c.volumes,
+		catalog: c.catalog,
+		pools:   c.pools,
+	}
+	for i, n := range c.nodes {
+		out.nodes[i] = n.clone()
+		out.byName[n.Node.Name] = out.nodes[i]
+	}
+	return out
+}
+
+// Nodes returns the nodes in the simulation
+func (c *Cluster) Nodes() []*NodeState {
+	return c.nodes
+}
+
+// Node returns the named node, or nil
+func (c *Cluster) Node(name string) *NodeState {
+	return c.byName[name]
+}
+
+// AddNode adds an empty node to the simulation
+func (c *Cluster) AddNode(node *corev1.Node, schedulable bool) *NodeState {
+	state := &NodeState{
+		Node:        node,
+		Schedulable: schedulable,
+		requested:   corev1.ResourceList{},
+		volumes:     make(map[string]map[string]bool),
+	}
+	c.nodes = append(c.nodes, state)
+	c.byName[node.Name] = state
+	return state
+}
+
+// RemoveNode deletes a node and returns it; its pods are not rescheduled
+func (c *Cluster) RemoveNode(name string) *NodeState {
+	state, ok := c.byName[name]
+	if !ok {
+		return nil
+	}
+	delete(c.byName, name)
+	for i, n := range c.nodes {
+		if n == state {
+			c.nodes = append(c.nodes[:i], c.nodes[i+1:]...)
+			break
+		}
+	}
+	return state
+}
+
+// Bind places a pod on a node without checking whether it fits
+func (c *Cluster) Bind(pod *corev1.Pod, nodeName string) {
+	if state, ok := c.byName[nodeName]; ok {
+		c.bind(state, pod)
+	}
+}
+
+func (c *Cluster) bind(state *NodeState, pod *corev1.Pod) {
+	state.Pods = append(state.Pods, pod)
+	for name, quantity := range consolidation.PodRequests(pod) {
+		total := state.requested[name]
+		total.Add(quantity)
+		state.requested[name] = total
+	}
+	for driver, handles := range c.volumes.attachments(pod) {
+		if state.volumes[driver] == nil {
+			state.volumes[driver] = make(map[string]bool)
+		}
+		for _, handle := range handles {
+			state.volumes[driver][handle] = true
+		}
+	}
+}
+
+// Place binds the pod to the first schedulable node it fits on, trying nodes in
+// order. It returns the chosen node, or "" and the number of nodes rejecting the
+// pod for each reason.
+func (c *Cluster) Place(pod *corev1.Pod) (string, map[string]int) {
+	reasons := make(map[string]int)
+	for _, state := range c.nodes {
+		if reason := c.Fits(pod, state); reason != "" {
+			reasons[reason]++
+			continue
+		}
+		c.bind(state, pod)
+		return state.Node.Name, nil
+	}
+	return "", reasons
+}
+
+// freeCapacity returns allocatable minus requested for one resource
+func freeCapacity(state *NodeState, name corev1.ResourceName) resource.Quantity {
+	free := state.Node.Status.Allocatable[name].DeepCopy()
+	free.Sub(state.requested[name])
+	return free
+}
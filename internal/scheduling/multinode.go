@@ -0,0 +1,144 @@
+package scheduling
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// NodeUtilization is a surviving node's utilization before and after the removals
+type NodeUtilization struct {
+	Node   string
+	Before map[corev1.ResourceName]int
+	After  map[corev1.ResourceName]int
+}
+
+// MultiNodeResult is the largest set of nodes found that can be removed together
+type MultiNodeResult struct {
+	NodesBefore int
+	Removed     []string
+	Placements  []Placement // Final node for every displaced pod
+	Survivors   []NodeUtilization
+}
+
+// NodesAfter is the node count once the removable set is gone
+func (r MultiNodeResult) NodesAfter() int {
+	return r.NodesBefore - len(r.Removed)
+}
+
+// SearchRemovable looks for the largest set of candidates that can be deleted at
+// once with every displaced pod still fitting on the survivors. Candidates are
+// tried greedily, cheapest to move first: a node joins the set when its pods,
+// including any moved onto it earlier in the search, fit on the remaining nodes.
+// The search runs once ordered by pod count and once by requested CPU, and the
+// larger set wins.
+func (c *Cluster) SearchRemovable(candidates []string) MultiNodeResult {
+	known := make([]string, 0, len(candidates))
+	for _, name := range candidates {
+		if c.byName[name] != nil {
+			known = append(known, name)
+		}
+	}
+
+	var best *searchState
+	for _, order := range []func([]string){c.sortByPodCount, c.sortByRequestedCPU} {
+		ordered := append([]string(nil), known...)
+		order(ordered)
+		state := c.searchGreedy(ordered)
+		if best == nil || len(state.removed) > len(best.removed) {
+			best = state
+		}
+	}
+
+	result := MultiNodeResult{
+		NodesBefore: len(c.nodes),
+		Removed:     best.removed,
+	}
+	for _, pod := range best.moved {
+		result.Placements = append(result.Placements, Placement{Pod: pod, From: best.movedOff[pod], Node: best.placedOn[pod]})
+	}
+	for _, state := range best.sim.nodes {
+		result.Survivors = append(result.Survivors, NodeUtilization{
+			Node:   state.Node.Name,
+			Before: c.byName[state.Node.Name].Utilization(),
+			After:  state.Utilization(),
+		})
+	}
+	return result
+}
+
+type searchState struct {
+	sim      *Cluster
+	removed  []string
+	moved    []*corev1.Pod // In order of first move
+	movedOff map[*corev1.Pod]string
+	placedOn map[*corev1.Pod]string
+}
+
+func (c *Cluster) searchGreedy(candidates []string) *searchState {
+	s := &searchState{
+		sim:      c.Clone(),
+		movedOff: make(map[*corev1.Pod]string),
+		placedOn: make(map[*corev1.Pod]string),
+	}
+	for _, name := range candidates {
+		trial := s.sim.Clone()
+		removed := trial.RemoveNode(name)
+		movable, _ := splitPods(removed.Pods)
+		SortPodsBySize(movable)
+		placed := make(map[*corev1.Pod]string, len(movable))
+		fits := true
+		for _, pod := range movable {
+			target, _ := trial.Place(pod)
+			if target == "" {
+				fits = false
+				break
+			}
+			placed[pod] = target
+		}
+		if !fits {
+			continue
+		}
+
+		s.sim = trial
+		s.removed = append(s.removed, name)
+		for _, pod := range movable {
+			if _, seen := s.placedOn[pod]; !seen {
+				s.moved = append(s.moved, pod)
+				s.movedOff[pod] = name
+			}
+			s.placedOn[pod] = placed[pod]
+		}
+	}
+	return s
+}
+
+// sortByPodCount orders nodes by movable pod count, fewest first
+func (c *Cluster) sortByPodCount(names []string) {
+	counts := make(map[string]int, len(names))
+	for _, name := range names {
+		movable, _ := splitPods(c.byName[name].Pods)
+		counts[name] = len(movable)
+	}
+	sort.SliceStable(names, func(i, j int) bool { return counts[names[i]] < counts[names[j]] })
+}
+
+// sortByRequestedCPU orders nodes by requested CPU, least first
+func (c *Cluster) sortByRequestedCPU(names []string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := c.byName[names[i]], c.byName[names[j]]
+		return a.requested.Cpu().Cmp(*b.requested.Cpu()) < 0
+	})
+}
+
+// Utilization returns the node's requested utilization per allocatable resource,
+// counting every bound pod
+func (n *NodeState) Utilization() map[corev1.ResourceName]int {
+	pods := make([]corev1.Pod, len(n.Pods))
+	for i, pod := range n.Pods {
+		pods[i] = *pod
+	}
+	return consolidation.CalculateResourceUtilization(n.Node, pods)
+}
@@ -0,0 +1,61 @@
+package scheduling
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSearchRemovable(t *testing.T) {
+	nodes := []corev1.Node{
+		testNode("a", "z1", "4"),
+		testNode("b", "z1", "4"),
+		testNode("c", "z1", "4"),
+		testNode("d", "z1", "4"),
+	}
+	pods := map[string][]corev1.Pod{
+		"a": {testPod("web-a", "1")},
+		"b": {testPod("web-b", "1")},
+		"c": {testPod("web-c", "2")},
+		"d": {testPod("web-d", "3")},
+	}
+	cluster := NewCluster(nodes, pods, nil)
+
+	result := cluster.SearchRemovable([]string{"a", "b", "c", "d"})
+
+	if result.NodesBefore != 4 || result.NodesAfter() != 2 {
+		t.Fatalf("nodes %d -> %d, want 4 -> 2 (removed %v)", result.NodesBefore, result.NodesAfter(), result.Removed)
+	}
+	if result.Removed[0] != "a" || result.Removed[1] != "b" {
+		t.Errorf("Removed = %v, want [a b]", result.Removed)
+	}
+
+	// web-a first moves to b, then on to c when b is removed too
+	final := make(map[string]Placement)
+	for _, pl := range result.Placements {
+		final[pl.Pod.Name] = pl
+	}
+	if pl := final["web-a"]; pl.From != "a" || pl.Node != "c" {
+		t.Errorf("web-a placement = %s -> %s, want a -> c", pl.From, pl.Node)
+	}
+
+	for _, s := range result.Survivors {
+		switch s.Node {
+		case "c":
+			if s.Before[corev1.ResourceCPU] != 50 || s.After[corev1.ResourceCPU] != 100 {
+				t.Errorf("c cpu %d%% -> %d%%, want 50%% -> 100%%", s.Before[corev1.ResourceCPU], s.After[corev1.ResourceCPU])
+			}
+		case "d":
+			if s.Before[corev1.ResourceCPU] != s.After[corev1.ResourceCPU] {
+				t.Errorf("d cpu changed %d%% -> %d%%", s.Before[corev1.ResourceCPU], s.After[corev1.ResourceCPU])
+			}
+		default:
+			t.Errorf("unexpected survivor %s", s.Node)
+		}
+	}
+
+	// The search must not modify the cluster it ran against
+	if len(cluster.Node("a").Pods) != 1 || len(cluster.Node("c").Pods) != 1 {
+		t.Errorf("SearchRemovable() mutated the input cluster")
+	}
+}
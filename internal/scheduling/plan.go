@@ -0,0 +1,167 @@
+package scheduling
+
+import (
+	"fmt"
+	"sort"
+
+	policyv1 "k8s.io/api/policy/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// PDBUsage is how many pods covered by a PDB a drain evicts
+type PDBUsage struct {
+	PDB  *policyv1.PodDisruptionBudget
+	Pods int
+}
+
+// DrainStep drains and deletes one node
+type DrainStep struct {
+	Wave  int // 1-based; nodes in the same wave share no PDB
+	Node  string
+	Moves []Placement // Where the node's pods end up once every step has run
+	PDBs  []PDBUsage
+}
+
+// SkippedNode is a candidate left out of the plan
+type SkippedNode struct {
+	Node   string
+	Reason string
+}
+
+// DrainPlan is an ordered sequence of node drains. Every node in the plan is
+// cordoned before the first drain so displaced pods only land on nodes that
+// stay; the moves are where they fit in that final state.
+type DrainPlan struct {
+	Steps   []DrainStep
+	Skipped []SkippedNode
+}
+
+// Waves returns the number of waves in the plan
+func (p DrainPlan) Waves() int {
+	waves := 0
+	for _, step := range p.Steps {
+		waves = max(waves, step.Wave)
+	}
+	return waves
+}
+
+// Nodes returns the planned nodes in step order
+func (p DrainPlan) Nodes() []string {
+	names := make([]string, len(p.Steps))
+	for i, step := range p.Steps {
+		names[i] = step.Node
+	}
+	return names
+}
+
+// PlanDrain orders the candidates into drain waves that respect each PDB's
+// current disruptionsAllowed. Nodes that would evict more pods covered by a PDB
+// than it allows at once are skipped, as are nodes whose pods do not fit on the
+// nodes that stay. Nodes whose pods share a PDB are put in different waves, and each wave
+// should only start once the previous wave's PDBs have recovered.
+func (c *Cluster) PlanDrain(candidates []string, pdbs []policyv1.PodDisruptionBudget) DrainPlan {
+	var plan DrainPlan
+	usage := make(map[string][]PDBUsage, len(candidates))
+
+	var drainable []string
+	for _, name := range candidates {
+		state := c.byName[name]
+		if state == nil {
+			plan.Skipped = append(plan.Skipped, SkippedNode{Node: name, Reason: "node not found"})
+			continue
+		}
+		usage[name] = pdbUsage(state, pdbs)
+		if over := overBudget(usage[name]); over != nil {
+			plan.Skipped = append(plan.Skipped, SkippedNode{Node: name, Reason: over.reason()})
+			continue
+		}
+		drainable = append(drainable, name)
+	}
+
+	result := c.SearchRemovable(drainable)
+	removed := make(map[string]bool, len(result.Removed))
+	for _, name := range result.Removed {
+		removed[name] = true
+	}
+	for _, name := range drainable {
+		if !removed[name] {
+			plan.Skipped = append(plan.Skipped, SkippedNode{Node: name, Reason: "pods do not fit on the nodes that stay"})
+		}
+	}
+
+	// First-fit each node into the earliest wave that shares none of its PDBs
+	var wavePDBs []map[*policyv1.PodDisruptionBudget]bool
+	for _, name := range result.Removed {
+		wave := 0
+		for ; wave < len(wavePDBs); wave++ {
+			if !sharesPDB(wavePDBs[wave], usage[name]) {
+				break
+			}
+		}
+		if wave == len(wavePDBs) {
+			wavePDBs = append(wavePDBs, make(map[*policyv1.PodDisruptionBudget]bool))
+		}
+		for _, u := range usage[name] {
+			wavePDBs[wave][u.PDB] = true
+		}
+
+		step := DrainStep{Wave: wave + 1, Node: name, PDBs: usage[name]}
+		for _, pl := range result.Placements {
+			if pl.From == name {
+				step.Moves = append(step.Moves, pl)
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	sort.SliceStable(plan.Steps, func(i, j int) bool { return plan.Steps[i].Wave < plan.Steps[j].Wave })
+	return plan
+}
+
+// pdbUsage counts the node's movable pods covered by each PDB
+func pdbUsage(state *NodeState, pdbs []policyv1.PodDisruptionBudget) []PDBUsage {
+	var usage []PDBUsage
+	index := make(map[*policyv1.PodDisruptionBudget]int)
+	movable, _ := splitPods(state.Pods)
+	for _, pod := range movable {
+		for _, pdb := range consolidation.MatchingPDBs(pod, pdbs) {
+			i, ok := index[pdb]
+			if !ok {
+				i = len(usage)
+				index[pdb] = i
+				usage = append(usage, PDBUsage{PDB: pdb})
+			}
+			usage[i].Pods++
+		}
+	}
+	return usage
+}
+
+// overBudget returns the first PDB the drain would evict more covered pods from
+// than it allows at once. kubectl drain would otherwise retry those evictions
+// until it timed out.
+func overBudget(usage []PDBUsage) *PDBUsage {
+	for i, u := range usage {
+		if u.Pods > int(u.PDB.Status.DisruptionsAllowed) {
+			return &usage[i]
+		}
+	}
+	return nil
+}
+
+func (u PDBUsage) reason() string {
+	if u.PDB.Status.DisruptionsAllowed <= 0 {
+		return fmt.Sprintf("PDB %s/%s allows no disruptions", u.PDB.Namespace, u.PDB.Name)
+	}
+	return fmt.Sprintf("evicts %d pods covered by PDB %s/%s, which allows %d",
+		u.Pods, u.PDB.Namespace, u.PDB.Name, u.PDB.Status.DisruptionsAllowed)
+}
+
+func sharesPDB(wave map[*policyv1.PodDisruptionBudget]bool, usage []PDBUsage) bool {
+	for _, u := range usage {
+		if wave[u.PDB] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package scheduling
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlanDrain(t *testing.T) {
+	app := func(name string) func(*corev1.Pod) {
+		return func(p *corev1.Pod) { p.Labels["app"] = name }
+	}
+	pdb := func(name, app string, allowed int32) policyv1.PodDisruptionBudget {
+		return policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+		}
+	}
+
+	nodes := []corev1.Node{
+		testNode("a", "z1", "4"),
+		testNode("b", "z1", "4"),
+		testNode("c", "z1", "4"),
+		testNode("d", "z1", "4"),
+		testNode("e", "z1", "4"),
+		testNode("big", "z1", "16"),
+	}
+	pods := map[string][]corev1.Pod{
+		"a": {testPod("web-1", "1", app("web"))},
+		"b": {testPod("web-2", "1", app("web"))},
+		"c": {testPod("batch", "1")},
+		"d": {testPod("db-0", "1", app("db"))},
+		"e": {testPod("cache-0", "1", app("cache")), testPod("cache-1", "1", app("cache"))},
+	}
+	pdbs := []policyv1.PodDisruptionBudget{pdb("web", "web", 1), pdb("db", "db", 0), pdb("cache", "cache", 1)}
+	cluster := NewCluster(nodes, pods, nil)
+
+	plan := cluster.PlanDrain([]string{"a", "b", "c", "d", "e", "missing"}, pdbs)
+
+	if len(plan.Steps) != 3 {
+		t.Fatalf("planned %v, want a, b and c", plan.Nodes())
+	}
+	waves := make(map[string]int)
+	for _, step := range plan.Steps {
+		waves[step.Node] = step.Wave
+	}
+	for _, step := range plan.Steps {
+		for _, m := range step.Moves {
+			if _, planned := waves[m.Node]; planned {
+				t.Errorf("%s moves to %s, which is drained too", m.Pod.Name, m.Node)
+			}
+		}
+	}
+	if waves["a"] == waves["b"] {
+		t.Errorf("a and b share the web PDB but are both in wave %d", waves["a"])
+	}
+	if waves["c"] != 1 {
+		t.Errorf("c is in wave %d, want 1", waves["c"])
+	}
+	if plan.Waves() != 2 {
+		t.Errorf("Waves() = %d, want 2", plan.Waves())
+	}
+
+	skipped := make(map[string]string)
+	for _, s := range plan.Skipped {
+		skipped[s.Node] = s.Reason
+	}
+	if skipped["d"] != "PDB default/db allows no disruptions" {
+		t.Errorf("d skipped for %q", skipped["d"])
+	}
+	if skipped["e"] != "evicts 2 pods covered by PDB default/cache, which allows 1" {
+		t.Errorf("e skipped for %q", skipped["e"])
+	}
+	if skipped["missing"] == "" {
+		t.Error("missing node not reported as skipped")
+	}
+}
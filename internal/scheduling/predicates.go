@@ -0,0 +1,161 @@
+package scheduling
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// Reasons a pod does not fit on a node. They name the failed check only, so
+// they can be counted across nodes like the scheduler's "0/N nodes are available".
+const (
+	ReasonUnschedulable      = "node unschedulable"
+	ReasonTaint              = "untolerated taint"
+	ReasonNodeSelector       = "node selector mismatch"
+	ReasonNodeAffinity       = "node affinity mismatch"
+	ReasonTooManyPods        = "too many pods"
+	ReasonHostPort           = "host port conflict"
+	ReasonPodAffinity        = "pod affinity"
+	ReasonPodAntiAffinity    = "pod anti-affinity"
+	ReasonTopologySpread     = "topology spread"
+	ReasonVolumeNodeAffinity = "volume node affinity conflict"
+	ReasonVolumeAttachLimit  = "volume attach limit"
+	reasonInsufficientPrefix = "insufficient "
+)
+
+// Fits checks whether pod can be placed on node given the pods already bound
+// in the simulation. It returns "" if it fits, otherwise the first failed check.
+func (c *Cluster) Fits(pod *corev1.Pod, node *NodeState) string {
+	if !node.Schedulable {
+		return ReasonUnschedulable
+	}
+	if !toleratesTaints(pod, node.Node) {
+		return ReasonTaint
+	}
+	if !matchesNodeSelector(pod, node.Node) {
+		return ReasonNodeSelector
+	}
+	if !matchesRequiredNodeAffinity(pod, node.Node) {
+		return ReasonNodeAffinity
+	}
+	if reason := fitsResources(pod, node); reason != "" {
+		return reason
+	}
+	if hostPortConflict(pod, node) {
+		return ReasonHostPort
+	}
+	if reason := c.checkPodAffinity(pod, node); reason != "" {
+		return reason
+	}
+	if !c.satisfiesTopologySpread(pod, node) {
+		return ReasonTopologySpread
+	}
+	return c.volumes.check(pod, node)
+}
+
+// toleratesTaints reports whether the pod tolerates every NoSchedule and
+// NoExecute taint on the node
+func toleratesTaints(pod *corev1.Pod, node *corev1.Node) bool {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if tolerates(&pod.Spec.Tolerations[j], taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// tolerates matches a toleration against a taint: an empty key with Exists
+// tolerates everything, an empty effect matches every effect
+func tolerates(toleration *corev1.Toleration, taint *corev1.Taint) bool {
+	if toleration.Effect != "" && toleration.Effect != taint.Effect {
+		return false
+	}
+	if toleration.Key != "" && toleration.Key != taint.Key {
+		return false
+	}
+	switch toleration.Operator {
+	case corev1.TolerationOpExists:
+		return true
+	case "", corev1.TolerationOpEqual:
+		return toleration.Key != "" && toleration.Value == taint.Value
+	}
+	return false
+}
+
+// fitsResources checks every requested resource and the node's pod capacity
+func fitsResources(pod *corev1.Pod, node *NodeState) string {
+	if allowed, ok := node.Node.Status.Allocatable[corev1.ResourcePods]; ok && int64(len(node.Pods)+1) > allowed.Value() {
+		return ReasonTooManyPods
+	}
+	for name, quantity := range consolidation.PodRequests(pod) {
+		if quantity.IsZero() {
+			continue
+		}
+		free := freeCapacity(node, name)
+		if free.Cmp(quantity) < 0 {
+			return reasonInsufficientPrefix + string(name)
+		}
+	}
+	return ""
+}
+
+// hostPortConflict reports whether any of the pod's host ports is already in use
+func hostPortConflict(pod *corev1.Pod, node *NodeState) bool {
+	wanted := hostPorts(pod)
+	if len(wanted) == 0 {
+		return false
+	}
+	for _, existing := range node.Pods {
+		for _, used := range hostPorts(existing) {
+			for _, port := range wanted {
+				if portsConflict(port, used) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func hostPorts(pod *corev1.Pod) []corev1.ContainerPort {
+	var ports []corev1.ContainerPort
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			for _, port := range container.Ports {
+				if port.HostPort > 0 {
+					ports = append(ports, port)
+				}
+			}
+		}
+	}
+	return ports
+}
+
+func portsConflict(a, b corev1.ContainerPort) bool {
+	if a.HostPort != b.HostPort || protocol(a) != protocol(b) {
+		return false
+	}
+	return isWildcardIP(a.HostIP) || isWildcardIP(b.HostIP) || a.HostIP == b.HostIP
+}
+
+func protocol(port corev1.ContainerPort) corev1.Protocol {
+	if port.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return port.Protocol
+}
+
+func isWildcardIP(ip string) bool {
+	return ip == "" || ip == "0.0.0.0" || ip == "::"
+}
@@ -0,0 +1,205 @@
+package scheduling
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/catalog"
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// Replacement is the cheapest catalog instance type that could host the pods a
+// node removal leaves without a home, compared with the node's current price
+type Replacement struct {
+	CurrentType     string
+	CapacityType    string
+	CurrentPrice    float64
+	HasCurrentPrice bool
+	Cheapest        *catalog.Offering // nil when no instance type fits
+	Cheaper         bool              // Cheapest costs less than the current node
+}
+
+// Summary describes the replacement in one line
+func (r *Replacement) Summary() string {
+	current := "unknown"
+	if r.HasCurrentPrice {
+		current = formatPrice(r.CurrentPrice)
+	}
+	switch {
+	case r.Cheapest == nil:
+		return "no instance type in the catalog fits"
+	case r.Cheaper:
+		return fmt.Sprintf("%s (%s) %s, was %s", r.Cheapest.InstanceType.Name, r.Cheapest.CapacityType, formatPrice(r.Cheapest.Price), current)
+	case karpenter.IsReserved(r.CapacityType):
+		return fmt.Sprintf("reserved capacity is prepaid: cheapest fit %s (%s) %s",
+			r.Cheapest.InstanceType.Name, r.Cheapest.CapacityType, formatPrice(r.Cheapest.Price))
+	case !r.HasCurrentPrice:
+		return fmt.Sprintf("cheapest fit %s (%s) %s, current %s/%s not in catalog",
+			r.Cheapest.InstanceType.Name, r.Cheapest.CapacityType, formatPrice(r.Cheapest.Price), r.CurrentType, r.CapacityType)
+	default:
+		return fmt.Sprintf("would increase cost: cheapest fit %s (%s) %s >= current %s",
+			r.Cheapest.InstanceType.Name, r.Cheapest.CapacityType, formatPrice(r.Cheapest.Price), current)
+	}
+}
+
+func formatPrice(price float64) string {
+	return fmt.Sprintf("$%.4f/h", price)
+}
+
+// SetCatalog enables replacement simulation against an instance-type catalog,
+// constrained by the requirements of each node's NodePool
+func (c *Cluster) SetCatalog(cat *catalog.Catalog, pools map[string]*karpenter.NodePool) {
+	c.catalog = cat
+	c.pools = pools
+}
+
+// cheapestReplacement tries catalog offerings cheapest first and returns the first
+// whose node, built from the original with the offering's labels and capacity,
+// holds the original's overhead pods plus pods
+func (c *Cluster) cheapestReplacement(original *corev1.Node, overhead, pods []*corev1.Pod) *Replacement {
+	r := &Replacement{
+		CurrentType:  original.Labels[corev1.LabelInstanceTypeStable],
+		CapacityType: karpenter.GetCapacityType(original),
+	}
+	r.CurrentPrice, r.HasCurrentPrice = c.catalog.Price(r.CurrentType, r.CapacityType)
+
+	poolName, _ := karpenter.GetPoolName(original)
+	var reqs []corev1.NodeSelectorRequirement
+	if pool := c.pools[poolName]; pool != nil {
+		reqs = pool.Requirements
+	}
+
+	for _, offering := range c.catalog.Offerings() {
+		node := offeringNode(original, offering)
+		if !allowedByPool(node.Labels, reqs) {
+			continue
+		}
+		if c.fitsOn(node, overhead, pods) {
+			r.Cheapest = &offering
+			break
+		}
+	}
+
+	// Reserved nodes are prepaid, see karpenter.IsReserved
+	r.Cheaper = r.Cheapest != nil && r.HasCurrentPrice && r.Cheapest.Price < r.CurrentPrice && !karpenter.IsReserved(r.CapacityType)
+	return r
+}
+
+// fitsOn reports whether the pods fit together on a new node. The node is added
+// to the simulation for the check and removed again.
+func (c *Cluster) fitsOn(node *corev1.Node, overhead, pods []*corev1.Pod) bool {
+	state := c.AddNode(node, true)
+	defer c.RemoveNode(node.Name)
+
+	for _, pod := range overhead {
+		c.bind(state, pod)
+	}
+	for _, pod := range pods {
+		if c.Fits(pod, state) != "" {
+			return false
+		}
+		c.bind(state, pod)
+	}
+	return true
+}
+
+// instanceLabelPrefixes are labels describing the instance type, which a
+// replacement node gets from the catalog instead of the original node
+var instanceLabelPrefixes = []string{
+	corev1.LabelInstanceTypeStable,
+	corev1.LabelInstanceType,
+	corev1.LabelArchStable,
+	"karpenter.k8s.aws/",
+	"karpenter.azure.com/",
+}
+
+// offeringNode builds the node an offering would produce: the original's labels,
+// taints and zone with the offering's instance labels, capacity type and capacity.
+// It keeps the original's architecture when the catalog does not give one.
+func offeringNode(original *corev1.Node, offering catalog.Offering) *corev1.Node {
+	node := original.DeepCopy()
+	node.Name = original.Name + "-" + offering.InstanceType.Name
+	node.Spec.Unschedulable = false
+
+	labels := make(map[string]string, len(original.Labels))
+	for key, value := range original.Labels {
+		if !hasAnyPrefix(key, instanceLabelPrefixes) {
+			labels[key] = value
+		}
+	}
+	for key, value := range offering.InstanceType.NodeLabels() {
+		labels[key] = value
+	}
+	// A catalog entry without an architecture is taken to match the original
+	if _, ok := labels[corev1.LabelArchStable]; !ok {
+		if arch, ok := original.Labels[corev1.LabelArchStable]; ok {
+			labels[corev1.LabelArchStable] = arch
+		}
+	}
+	labels[karpenter.LabelCapacityType] = offering.CapacityType
+	labels[corev1.LabelHostname] = node.Name
+	node.Labels = labels
+
+	node.Status.Capacity = offering.InstanceType.Capacity.DeepCopy()
+	node.Status.Allocatable = allocatable(offering.InstanceType.Capacity, original)
+	if _, ok := node.Status.Allocatable[corev1.ResourcePods]; !ok {
+		if pods, ok := original.Status.Allocatable[corev1.ResourcePods]; ok {
+			node.Status.Allocatable[corev1.ResourcePods] = pods.DeepCopy()
+		}
+	}
+	return node
+}
+
+// allocatable is an instance type's capacity less what the original node's
+// kubelet holds back from pods (kube-reserved, system-reserved and eviction
+// thresholds), assuming the replacement reserves the same amounts
+func allocatable(capacity corev1.ResourceList, original *corev1.Node) corev1.ResourceList {
+	out := capacity.DeepCopy()
+	for name, quantity := range out {
+		reserved, ok := original.Status.Capacity[name]
+		if !ok {
+			continue
+		}
+		reserved.Sub(original.Status.Allocatable[name])
+		if reserved.Sign() <= 0 {
+			continue
+		}
+		quantity.Sub(reserved)
+		if quantity.Sign() < 0 {
+			quantity.Set(0)
+		}
+		out[name] = quantity
+	}
+	return out
+}
+
+// allowedByPool checks NodePool requirements against a replacement node's labels.
+// Requirements on labels the catalog does not provide cannot be evaluated and are
+// skipped. The capacity type is checked against the pool's own requirements,
+// or Karpenter's on-demand default when it has none.
+func allowedByPool(labels map[string]string, reqs []corev1.NodeSelectorRequirement) bool {
+	if !allowsCapacityType(reqs, labels[karpenter.LabelCapacityType]) {
+		return false
+	}
+	var known []corev1.NodeSelectorRequirement
+	for _, req := range reqs {
+		if req.Key == karpenter.LabelCapacityType {
+			continue
+		}
+		if _, ok := labels[req.Key]; ok || req.Operator == corev1.NodeSelectorOpNotIn || req.Operator == corev1.NodeSelectorOpDoesNotExist {
+			known = append(known, req)
+		}
+	}
+	return karpenter.MatchRequirements(labels, known)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package scheduling
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/catalog"
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+const replaceCatalog = `
+instanceTypes:
+  small:
+    cpu: 2
+    memory: 8Gi
+    prices:
+      on-demand: 0.10
+      spot: 0.04
+  medium:
+    cpu: 4
+    memory: 16Gi
+    prices:
+      on-demand: 0.20
+      spot: 0.08
+  large:
+    cpu: 8
+    memory: 32Gi
+    prices:
+      on-demand: 0.40
+      reserved: 0.40
+`
+
+func TestSimulateReplacement(t *testing.T) {
+	cat, err := catalog.Parse([]byte(replaceCatalog))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	poolNode := func(name, instanceType, capacityType, cpu string) corev1.Node {
+		node := testNode(name, "z1", cpu)
+		node.Labels[karpenter.LabelNodePool] = "default"
+		node.Labels[corev1.LabelInstanceTypeStable] = instanceType
+		node.Labels[karpenter.LabelCapacityType] = capacityType
+		return node
+	}
+	spotAllowed := []corev1.NodeSelectorRequirement{{
+		Key:      karpenter.LabelCapacityType,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{karpenter.CapacityTypeSpot, karpenter.CapacityTypeOnDemand},
+	}}
+
+	tests := []struct {
+		name         string
+		node         corev1.Node
+		pods         []corev1.Pod
+		requirements []corev1.NodeSelectorRequirement
+		expected     Outcome
+		wantType     string
+		wantCapacity string
+		wantCheaper  bool
+	}{
+		{
+			name:         "smaller on-demand type fits",
+			node:         poolNode("a", "large", karpenter.CapacityTypeOnDemand, "8"),
+			pods:         []corev1.Pod{testPod("web", "3")},
+			expected:     OutcomeReplaceable,
+			wantType:     "medium",
+			wantCapacity: karpenter.CapacityTypeOnDemand,
+			wantCheaper:  true,
+		},
+		{
+			name:         "spot allowed by pool",
+			node:         poolNode("a", "large", karpenter.CapacityTypeOnDemand, "8"),
+			pods:         []corev1.Pod{testPod("web", "3")},
+			requirements: spotAllowed,
+			expected:     OutcomeReplaceable,
+			wantType:     "medium",
+			wantCapacity: karpenter.CapacityTypeSpot,
+			wantCheaper:  true,
+		},
+		{
+			name:         "pool excludes cheaper types",
+			node:         poolNode("a", "large", karpenter.CapacityTypeOnDemand, "8"),
+			pods:         []corev1.Pod{testPod("web", "1")},
+			requirements: []corev1.NodeSelectorRequirement{{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"large"}}},
+			expected:     OutcomeNotConsolidatable,
+			wantType:     "large",
+			wantCapacity: karpenter.CapacityTypeOnDemand,
+		},
+		{
+			name:         "cheapest fit costs more",
+			node:         poolNode("a", "medium", karpenter.CapacityTypeSpot, "4"),
+			pods:         []corev1.Pod{testPod("web", "3")},
+			requirements: spotAllowed,
+			expected:     OutcomeNotConsolidatable,
+			wantType:     "medium",
+			wantCapacity: karpenter.CapacityTypeSpot,
+		},
+		{
+			name: "architecture kept when the catalog leaves it out",
+			node: func() corev1.Node {
+				node := poolNode("a", "large", karpenter.CapacityTypeOnDemand, "8")
+				node.Labels[corev1.LabelArchStable] = "arm64"
+				return node
+			}(),
+			pods: []corev1.Pod{testPod("web", "3", func(p *corev1.Pod) {
+				p.Spec.NodeSelector = map[string]string{corev1.LabelArchStable: "arm64"}
+			})},
+			expected:     OutcomeReplaceable,
+			wantType:     "medium",
+			wantCapacity: karpenter.CapacityTypeOnDemand,
+			wantCheaper:  true,
+		},
+		{
+			name:         "reserved node is prepaid",
+			node:         poolNode("a", "large", karpenter.CapacityTypeReserved, "8"),
+			pods:         []corev1.Pod{testPod("web", "3")},
+			expected:     OutcomeNotConsolidatable,
+			wantType:     "medium",
+			wantCapacity: karpenter.CapacityTypeOnDemand,
+		},
+		{
+			name:         "pool allows only spot",
+			node:         poolNode("a", "large", karpenter.CapacityTypeOnDemand, "8"),
+			pods:         []corev1.Pod{testPod("web", "3")},
+			requirements: []corev1.NodeSelectorRequirement{{Key: karpenter.LabelCapacityType, Operator: corev1.NodeSelectorOpIn, Values: []string{karpenter.CapacityTypeSpot}}},
+			expected:     OutcomeReplaceable,
+			wantType:     "medium",
+			wantCapacity: karpenter.CapacityTypeSpot,
+			wantCheaper:  true,
+		},
+		{
+			name:         "pool excludes spot",
+			node:         poolNode("a", "large", karpenter.CapacityTypeOnDemand, "8"),
+			pods:         []corev1.Pod{testPod("web", "3")},
+			requirements: []corev1.NodeSelectorRequirement{{Key: karpenter.LabelCapacityType, Operator: corev1.NodeSelectorOpNotIn, Values: []string{karpenter.CapacityTypeSpot}}},
+			expected:     OutcomeReplaceable,
+			wantType:     "medium",
+			wantCapacity: karpenter.CapacityTypeOnDemand,
+			wantCheaper:  true,
+		},
+		{
+			name:         "pool allows only reserved",
+			node:         poolNode("a", "large", karpenter.CapacityTypeOnDemand, "8"),
+			pods:         []corev1.Pod{testPod("web", "3")},
+			requirements: []corev1.NodeSelectorRequirement{{Key: karpenter.LabelCapacityType, Operator: corev1.NodeSelectorOpIn, Values: []string{karpenter.CapacityTypeReserved}}},
+			expected:     OutcomeNotConsolidatable,
+			wantType:     "large",
+			wantCapacity: karpenter.CapacityTypeReserved,
+		},
+		{
+			name: "kubelet reservations shrink the replacement",
+			node: func() corev1.Node {
+				// 1 CPU of 8 is held back, leaving a medium replacement 3
+				node := poolNode("a", "large", karpenter.CapacityTypeOnDemand, "7")
+				node.Status.Capacity = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")}
+				return node
+			}(),
+			pods:         []corev1.Pod{testPod("web", "3500m")},
+			expected:     OutcomeNotConsolidatable,
+			wantType:     "large",
+			wantCapacity: karpenter.CapacityTypeOnDemand,
+		},
+		{
+			name:     "nothing fits",
+			node:     poolNode("a", "large", karpenter.CapacityTypeOnDemand, "16"),
+			pods:     []corev1.Pod{testPod("web", "12")},
+			expected: OutcomeNotConsolidatable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The other node is too small to take any of the pods
+			nodes := []corev1.Node{tt.node, testNode("b", "z1", "500m")}
+			cluster := NewCluster(nodes, map[string][]corev1.Pod{tt.node.Name: tt.pods}, nil)
+			cluster.SetCatalog(cat, map[string]*karpenter.NodePool{
+				"default": {Name: "default", Requirements: tt.requirements},
+			})
+
+			result := cluster.SimulateRemoval(tt.node.Name)
+			if result.Outcome != tt.expected {
+				t.Errorf("Outcome = %s, want %s", result.Outcome, tt.expected)
+			}
+			r := result.Replacement
+			if r == nil {
+				t.Fatal("Replacement not set")
+			}
+			if tt.wantType == "" {
+				if r.Cheapest != nil {
+					t.Errorf("Cheapest = %s, want none", r.Cheapest.InstanceType.Name)
+				}
+				return
+			}
+			if r.Cheapest == nil {
+				t.Fatalf("Cheapest = nil, want %s", tt.wantType)
+			}
+			if r.Cheapest.InstanceType.Name != tt.wantType || r.Cheapest.CapacityType != tt.wantCapacity {
+				t.Errorf("Cheapest = %s/%s, want %s/%s", r.Cheapest.InstanceType.Name, r.Cheapest.CapacityType, tt.wantType, tt.wantCapacity)
+			}
+			if r.Cheaper != tt.wantCheaper {
+				t.Errorf("Cheaper = %v, want %v (%s)", r.Cheaper, tt.wantCheaper, r.Summary())
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package scheduling
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+// Outcome is what Karpenter could do with a node according to the simulation
+type Outcome string
+
+const (
+	// OutcomeDeletable means every movable pod fits on the remaining nodes
+	OutcomeDeletable Outcome = "deletable"
+	// OutcomeReplaceable means the pods left over fit on a cheaper catalog instance type
+	OutcomeReplaceable Outcome = "replaceable"
+	// OutcomeReplacementUnknown means some pods fit on no remaining node and,
+	// without a catalog of instance types and prices, whether a cheaper
+	// replacement could take them is not known
+	OutcomeReplacementUnknown Outcome = "replacement-unknown"
+	// OutcomeNotConsolidatable means the pods cannot be moved or packed smaller
+	OutcomeNotConsolidatable Outcome = "not-consolidatable"
+)
+
+// Placement records where a displaced pod was scheduled
+type Placement struct {
+	Pod  *corev1.Pod
+	From string
+	Node string
+}
+
+// Unplaced records a pod that fit on no node and why
+type Unplaced struct {
+	Pod     *corev1.Pod
+	Nodes   int            // Nodes considered
+	Reasons map[string]int // Number of nodes rejecting the pod for each reason
+}
+
+// Message formats the reasons like the scheduler does,
+// e.g. "0/5 nodes are available: 3 insufficient cpu, 2 untolerated taint"
+func (u Unplaced) Message() string {
+	return fmt.Sprintf("0/%d nodes are available: %s", u.Nodes, FormatReasons(u.Reasons))
+}
+
+// FormatReasons lists reason counts, most common first
+func FormatReasons(reasons map[string]int) string {
+	names := make([]string, 0, len(reasons))
+	for name := range reasons {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if reasons[names[i]] != reasons[names[j]] {
+			return reasons[names[i]] > reasons[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%d %s", reasons[name], name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NodeResult is the outcome of simulating the removal of one node
+type NodeResult struct {
+	Node       string
+	PoolName   string
+	Outcome    Outcome
+	Placements []Placement
+	Unplaced   []Unplaced
+	// Replacement is set when a catalog is configured and some pods did not fit
+	Replacement *Replacement
+}
+
+// Simulate evaluates each named node independently against the current cluster
+func (c *Cluster) Simulate(nodeNames []string) []NodeResult {
+	results := make([]NodeResult, 0, len(nodeNames))
+	for _, name := range nodeNames {
+		if c.Node(name) == nil {
+			continue
+		}
+		results = append(results, c.SimulateRemoval(name))
+	}
+	return results
+}
+
+// SimulateRemoval removes a node from a copy of the cluster and tries to place
+// its movable pods on the remaining nodes, largest pods first
+func (c *Cluster) SimulateRemoval(nodeName string) NodeResult {
+	sim := c.Clone()
+	removed := sim.RemoveNode(nodeName)
+	result := NodeResult{Node: nodeName}
+	if removed == nil {
+		result.Outcome = OutcomeNotConsolidatable
+		return result
+	}
+	result.PoolName, _ = karpenter.GetPoolName(removed.Node)
+
+	movable, overhead := splitPods(removed.Pods)
+	SortPodsBySize(movable)
+
+	var leftover []*corev1.Pod
+	for _, pod := range movable {
+		target, reasons := sim.Place(pod)
+		if target == "" {
+			result.Unplaced = append(result.Unplaced, Unplaced{Pod: pod, Nodes: len(sim.nodes), Reasons: reasons})
+			leftover = append(leftover, pod)
+			continue
+		}
+		result.Placements = append(result.Placements, Placement{Pod: pod, From: nodeName, Node: target})
+	}
+
+	switch {
+	case len(leftover) == 0:
+		result.Outcome = OutcomeDeletable
+	case sim.catalog == nil:
+		result.Outcome = OutcomeReplacementUnknown
+	default:
+		result.Replacement = sim.cheapestReplacement(removed.Node, overhead, leftover)
+		result.Outcome = OutcomeNotConsolidatable
+		if result.Replacement.Cheaper {
+			result.Outcome = OutcomeReplaceable
+		}
+	}
+	return result
+}
+
+// splitPods separates movable workload from DaemonSet and static pods
+func splitPods(pods []*corev1.Pod) (movable, overhead []*corev1.Pod) {
+	for _, pod := range pods {
+		if consolidation.IsMovable(pod) {
+			movable = append(movable, pod)
+		} else {
+			overhead = append(overhead, pod)
+		}
+	}
+	return movable, overhead
+}
+
+// SortPodsBySize orders pods by CPU then memory requests, largest first, so the
+// hardest pods to place are tried while the most room is left
+func SortPodsBySize(pods []*corev1.Pod) {
+	requests := make(map[*corev1.Pod]corev1.ResourceList, len(pods))
+	for _, pod := range pods {
+		requests[pod] = consolidation.PodRequests(pod)
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		a, b := requests[pods[i]], requests[pods[j]]
+		if cmp := a.Cpu().Cmp(*b.Cpu()); cmp != 0 {
+			return cmp > 0
+		}
+		return a.Memory().Cmp(*b.Memory()) > 0
+	})
+}
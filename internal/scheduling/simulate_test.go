@@ -0,0 +1,332 @@
+package scheduling
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNode(name, zone, cpu string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				corev1.LabelHostname:     name,
+				corev1.LabelTopologyZone: zone,
+			},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func testPod(name, cpu string, mutate ...func(*corev1.Pod)) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": name}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse(cpu),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	for _, m := range mutate {
+		m(&pod)
+	}
+	return pod
+}
+
+func TestSimulateRemoval(t *testing.T) {
+	antiAffinity := func(app string) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Labels["app"] = app
+			p.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+					TopologyKey:   corev1.LabelHostname,
+				}},
+			}}
+		}
+	}
+	hostPort := func(p *corev1.Pod) {
+		p.Spec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: 8080, HostPort: 8080}}
+	}
+	daemon := func(p *corev1.Pod) {
+		p.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}}
+	}
+
+	tests := []struct {
+		name        string
+		nodes       []corev1.Node
+		pods        map[string][]corev1.Pod
+		expected    Outcome
+		wantReasons []string
+	}{
+		{
+			name:  "pods fit elsewhere",
+			nodes: []corev1.Node{testNode("a", "z1", "4"), testNode("b", "z1", "4")},
+			pods: map[string][]corev1.Pod{
+				"a": {testPod("web", "1"), testPod("agent-a", "3", daemon)},
+				"b": {testPod("api", "1")},
+			},
+			expected: OutcomeDeletable,
+		},
+		{
+			name:  "leftover without a catalog",
+			nodes: []corev1.Node{testNode("a", "z1", "4"), testNode("b", "z1", "4")},
+			pods: map[string][]corev1.Pod{
+				"a": {testPod("web", "1")},
+				"b": {testPod("api", "3500m")},
+			},
+			expected:    OutcomeReplacementUnknown,
+			wantReasons: []string{"insufficient cpu"},
+		},
+		{
+			name:  "large pod has nowhere to go",
+			nodes: []corev1.Node{testNode("a", "z1", "4"), testNode("b", "z1", "4")},
+			pods: map[string][]corev1.Pod{
+				"a": {testPod("web", "3")},
+				"b": {testPod("api", "2")},
+			},
+			expected:    OutcomeReplacementUnknown,
+			wantReasons: []string{"insufficient cpu"},
+		},
+		{
+			name: "untolerated taint",
+			nodes: func() []corev1.Node {
+				b := testNode("b", "z1", "4")
+				b.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+				return []corev1.Node{testNode("a", "z1", "4"), b}
+			}(),
+			pods:        map[string][]corev1.Pod{"a": {testPod("web", "3")}},
+			expected:    OutcomeReplacementUnknown,
+			wantReasons: []string{ReasonTaint},
+		},
+		{
+			name:  "pod anti-affinity",
+			nodes: []corev1.Node{testNode("a", "z1", "4"), testNode("b", "z1", "4")},
+			pods: map[string][]corev1.Pod{
+				"a": {testPod("web-1", "3", antiAffinity("web"))},
+				"b": {testPod("web-2", "1", antiAffinity("web"))},
+			},
+			expected:    OutcomeReplacementUnknown,
+			wantReasons: []string{ReasonPodAntiAffinity},
+		},
+		{
+			name:  "host port conflict",
+			nodes: []corev1.Node{testNode("a", "z1", "4"), testNode("b", "z1", "4")},
+			pods: map[string][]corev1.Pod{
+				"a": {testPod("web-1", "3", hostPort)},
+				"b": {testPod("web-2", "1", hostPort)},
+			},
+			expected:    OutcomeReplacementUnknown,
+			wantReasons: []string{ReasonHostPort},
+		},
+		{
+			name:        "cordoned node",
+			nodes:       []corev1.Node{testNode("a", "z1", "4"), func() corev1.Node { n := testNode("b", "z1", "4"); n.Spec.Unschedulable = true; return n }()},
+			pods:        map[string][]corev1.Pod{"a": {testPod("web", "3")}},
+			expected:    OutcomeReplacementUnknown,
+			wantReasons: []string{ReasonUnschedulable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewCluster(tt.nodes, tt.pods, nil).SimulateRemoval("a")
+			if result.Outcome != tt.expected {
+				t.Errorf("Outcome = %v, want %v (unplaced: %+v)", result.Outcome, tt.expected, result.Unplaced)
+			}
+			if len(tt.wantReasons) == 0 && len(result.Unplaced) > 0 {
+				t.Errorf("unexpected unplaced pods: %+v", result.Unplaced)
+			}
+			for _, reason := range tt.wantReasons {
+				if len(result.Unplaced) == 0 || result.Unplaced[0].Reasons[reason] == 0 {
+					t.Errorf("Unplaced reasons = %+v, want %q", result.Unplaced, reason)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateDoesNotMoveDaemonSetPods(t *testing.T) {
+	nodes := []corev1.Node{testNode("a", "z1", "4"), testNode("b", "z1", "4")}
+	pods := map[string][]corev1.Pod{
+		"a": {testPod("agent-a", "3", func(p *corev1.Pod) {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}}
+		})},
+	}
+
+	result := NewCluster(nodes, pods, nil).SimulateRemoval("a")
+	if result.Outcome != OutcomeDeletable || len(result.Placements) != 0 {
+		t.Errorf("SimulateRemoval() = %+v, want deletable with no placements", result)
+	}
+}
+
+func TestTopologySpread(t *testing.T) {
+	spread := func(p *corev1.Pod) {
+		p.Labels["app"] = "web"
+		p.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		}}
+	}
+	nodes := []corev1.Node{testNode("a", "z1", "4"), testNode("b", "z2", "4"), testNode("c", "z3", "4")}
+	pods := map[string][]corev1.Pod{
+		"a": {testPod("web-1", "1", spread)},
+		"b": {testPod("web-2", "1", spread), testPod("web-3", "1", spread)},
+		"c": {testPod("web-4", "1", spread)},
+	}
+	cluster := NewCluster(nodes, pods, nil)
+
+	// Removing a leaves z2=2, z3=1: web-1 may only go to z3
+	result := cluster.SimulateRemoval("a")
+	if result.Outcome != OutcomeDeletable || result.Placements[0].Node != "c" {
+		t.Errorf("SimulateRemoval(a) = %+v, want web-1 placed on c", result)
+	}
+
+	// Placing an extra pod in z2 would make the skew 2 against z1 and z3
+	incoming := testPod("web-5", "1", spread)
+	if reason := cluster.Fits(&incoming, cluster.Node("b")); reason != ReasonTopologySpread {
+		t.Errorf("Fits(b) = %q, want %q", reason, ReasonTopologySpread)
+	}
+	if reason := cluster.Fits(&incoming, cluster.Node("c")); reason != "" {
+		t.Errorf("Fits(c) = %q, want fit", reason)
+	}
+}
+
+func TestVolumeChecks(t *testing.T) {
+	nodes := []corev1.Node{testNode("a", "z1", "4"), testNode("b", "z1", "4"), testNode("c", "z2", "4")}
+	withClaim := func(claim string) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Spec.Volumes = []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+			}}
+		}
+	}
+	pv := func(name string) corev1.PersistentVolume {
+		return corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-" + name}},
+				NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"z1"}}},
+				}}}},
+			},
+		}
+	}
+	pvc := func(name string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: name},
+		}
+	}
+	one := int32(1)
+	csiNodes := []storagev1.CSINode{{
+		ObjectMeta: metav1.ObjectMeta{Name: "b"},
+		Spec: storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{{
+			Name: "ebs.csi.aws.com", Allocatable: &storagev1.VolumeNodeResources{Count: &one},
+		}}},
+	}}
+	pods := map[string][]corev1.Pod{
+		"a": {testPod("db", "1", withClaim("db"))},
+		"b": {testPod("cache", "1", withClaim("cache"))},
+	}
+	volumes := NewVolumes([]corev1.PersistentVolumeClaim{pvc("db"), pvc("cache")}, []corev1.PersistentVolume{pv("db"), pv("cache")}, csiNodes)
+
+	result := NewCluster(nodes, pods, volumes).SimulateRemoval("a")
+	if len(result.Unplaced) != 1 {
+		t.Fatalf("SimulateRemoval() = %+v, want db unplaced", result)
+	}
+	reasons := result.Unplaced[0].Reasons
+	if reasons[ReasonVolumeAttachLimit] != 1 || reasons[ReasonVolumeNodeAffinity] != 1 {
+		t.Errorf("Reasons = %v, want attach limit on b and zone conflict on c", reasons)
+	}
+	if msg := result.Unplaced[0].Message(); !strings.HasPrefix(msg, "0/2 nodes are available: ") {
+		t.Errorf("Message() = %q", msg)
+	}
+}
+
+func TestTolerates(t *testing.T) {
+	taint := &corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+	tests := []struct {
+		name       string
+		toleration corev1.Toleration
+		expected   bool
+	}{
+		{name: "equal", toleration: corev1.Toleration{Key: "dedicated", Value: "gpu"}, expected: true},
+		{name: "wrong value", toleration: corev1.Toleration{Key: "dedicated", Value: "cpu"}, expected: false},
+		{name: "exists", toleration: corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists}, expected: true},
+		{name: "exists everything", toleration: corev1.Toleration{Operator: corev1.TolerationOpExists}, expected: true},
+		{name: "wrong effect", toleration: corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tolerates(&tt.toleration, taint); got != tt.expected {
+				t.Errorf("tolerates() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchNodeSelectorTerms(t *testing.T) {
+	node := testNode("a", "z1", "4")
+	node.Labels["karpenter.sh/capacity-type"] = "spot"
+
+	tests := []struct {
+		name     string
+		terms    []corev1.NodeSelectorTerm
+		expected bool
+	}{
+		{
+			name: "in",
+			terms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"spot"}},
+			}}},
+			expected: true,
+		},
+		{
+			name: "terms are ORed",
+			terms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"z2"}}}},
+				{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+			},
+			expected: true,
+		},
+		{
+			name: "does not exist",
+			terms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpDoesNotExist},
+			}}},
+			expected: false,
+		},
+		{
+			name:     "empty term matches nothing",
+			terms:    []corev1.NodeSelectorTerm{{}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchNodeSelectorTerms(&node, tt.terms); got != tt.expected {
+				t.Errorf("MatchNodeSelectorTerms() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
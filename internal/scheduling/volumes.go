@@ -0,0 +1,102 @@
+package scheduling
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// Volumes resolves pod volumes to CSI attachments and knows each node's attach limits
+type Volumes struct {
+	claims map[string]*corev1.PersistentVolumeClaim // Keyed by namespace/name
+	pvs    map[string]*corev1.PersistentVolume
+	// limits holds the attachable volume count per node and CSI driver
+	limits map[string]map[string]int64
+}
+
+// NewVolumes indexes PVCs, PVs and the CSINode attach limits
+func NewVolumes(pvcs []corev1.PersistentVolumeClaim, pvs []corev1.PersistentVolume, csiNodes []storagev1.CSINode) *Volumes {
+	v := &Volumes{
+		claims: make(map[string]*corev1.PersistentVolumeClaim, len(pvcs)),
+		pvs:    make(map[string]*corev1.PersistentVolume, len(pvs)),
+		limits: make(map[string]map[string]int64, len(csiNodes)),
+	}
+	for i := range pvcs {
+		v.claims[pvcs[i].Namespace+"/"+pvcs[i].Name] = &pvcs[i]
+	}
+	for i := range pvs {
+		v.pvs[pvs[i].Name] = &pvs[i]
+	}
+	for _, csiNode := range csiNodes {
+		for _, driver := range csiNode.Spec.Drivers {
+			if driver.Allocatable == nil || driver.Allocatable.Count == nil {
+				continue
+			}
+			if v.limits[csiNode.Name] == nil {
+				v.limits[csiNode.Name] = make(map[string]int64)
+			}
+			v.limits[csiNode.Name][driver.Name] = int64(*driver.Allocatable.Count)
+		}
+	}
+	return v
+}
+
+// boundVolumes returns the PersistentVolumes bound to the pod's claims
+func (v *Volumes) boundVolumes(pod *corev1.Pod) []*corev1.PersistentVolume {
+	if v == nil {
+		return nil
+	}
+	var out []*corev1.PersistentVolume
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		claim, ok := v.claims[pod.Namespace+"/"+volume.PersistentVolumeClaim.ClaimName]
+		if !ok || claim.Spec.VolumeName == "" {
+			continue
+		}
+		if pv, ok := v.pvs[claim.Spec.VolumeName]; ok {
+			out = append(out, pv)
+		}
+	}
+	return out
+}
+
+// attachments returns the CSI volume handles the pod needs attached, by driver
+func (v *Volumes) attachments(pod *corev1.Pod) map[string][]string {
+	out := make(map[string][]string)
+	for _, pv := range v.boundVolumes(pod) {
+		if pv.Spec.CSI != nil {
+			out[pv.Spec.CSI.Driver] = append(out[pv.Spec.CSI.Driver], pv.Spec.CSI.VolumeHandle)
+		}
+	}
+	return out
+}
+
+// check enforces PersistentVolume node affinity (e.g. a zonal disk) and the
+// node's CSI attach limits
+func (v *Volumes) check(pod *corev1.Pod, node *NodeState) string {
+	for _, pv := range v.boundVolumes(pod) {
+		if affinity := pv.Spec.NodeAffinity; affinity != nil && affinity.Required != nil {
+			if !MatchNodeSelectorTerms(node.Node, affinity.Required.NodeSelectorTerms) {
+				return ReasonVolumeNodeAffinity
+			}
+		}
+	}
+
+	for driver, handles := range v.attachments(pod) {
+		limit, ok := v.limits[node.Node.Name][driver]
+		if !ok {
+			continue
+		}
+		attached := int64(len(node.volumes[driver]))
+		for _, handle := range handles {
+			if !node.volumes[driver][handle] {
+				attached++
+			}
+		}
+		if attached > limit {
+			return ReasonVolumeAttachLimit
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package scheduling
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+)
+
+// WhatIfResult is a node's consolidation state before and after hypothetically
+// removing blockers. The fit simulation does not depend on blockers, so Outcome
+// applies to both.
+type WhatIfResult struct {
+	Node     string
+	PoolName string
+	Before   []consolidation.BlockerType
+	After    []consolidation.BlockerType
+	Outcome  Outcome
+	// Freed is the CPU and memory released by consolidating the node: all of it
+	// when deletable, the difference to the catalog replacement when replaceable,
+	// and none otherwise
+	Freed corev1.ResourceList
+}
+
+// ConsolidatableBefore reports whether the node can be consolidated as things are
+func (r WhatIfResult) ConsolidatableBefore() bool {
+	return len(r.Before) == 0 && r.fits()
+}
+
+// ConsolidatableAfter reports whether the node can be consolidated once the
+// blockers are removed
+func (r WhatIfResult) ConsolidatableAfter() bool {
+	return len(r.After) == 0 && r.fits()
+}
+
+// fits reports whether the simulation found a home for the node's pods. A
+// replacement that could not be checked without a catalog does not count.
+func (r WhatIfResult) fits() bool {
+	return r.Outcome == OutcomeDeletable || r.Outcome == OutcomeReplaceable
+}
+
+// Unblocked reports whether removing the blockers makes the node consolidatable
+func (r WhatIfResult) Unblocked() bool {
+	return !r.ConsolidatableBefore() && r.ConsolidatableAfter()
+}
+
+// WhatIf re-runs blocker detection for each candidate with the removals applied,
+// and the fit simulation to check that its pods can actually move. Nodes the
+// removals unblock come first, those freeing the most CPU leading.
+func (c *Cluster) WhatIf(candidates []string, states map[string]*consolidation.BlockerState, removals []consolidation.BlockerRemoval) []WhatIfResult {
+	results := make([]WhatIfResult, 0, len(candidates))
+	for _, name := range candidates {
+		state := states[name]
+		if state == nil || c.Node(name) == nil {
+			continue
+		}
+		sim := c.SimulateRemoval(name)
+		results = append(results, WhatIfResult{
+			Node:     name,
+			PoolName: sim.PoolName,
+			Before:   state.Blockers(),
+			After:    state.Without(removals).Blockers(),
+			Outcome:  sim.Outcome,
+			Freed:    freedCapacity(state.Node, sim),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if a, b := results[i].Unblocked(), results[j].Unblocked(); a != b {
+			return a
+		}
+		return results[i].Freed.Cpu().Cmp(*results[j].Freed.Cpu()) > 0
+	})
+	return results
+}
+
+// TotalFreed sums the capacity freed by the nodes the removals unblock. Each
+// node is simulated on its own, so the total is an upper bound when several of
+// them would move pods onto the same nodes.
+func TotalFreed(results []WhatIfResult) (unblocked int, freed corev1.ResourceList) {
+	freed = corev1.ResourceList{}
+	for _, r := range results {
+		if !r.Unblocked() {
+			continue
+		}
+		unblocked++
+		for name, quantity := range r.Freed {
+			total := freed[name]
+			total.Add(quantity)
+			freed[name] = total
+		}
+	}
+	return unblocked, freed
+}
+
+// freedCapacity is the CPU and memory released by a consolidation outcome
+func freedCapacity(node *corev1.Node, result NodeResult) corev1.ResourceList {
+	freed := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		allocatable, ok := node.Status.Allocatable[name]
+		if !ok {
+			continue
+		}
+		switch {
+		case result.Outcome == OutcomeDeletable:
+			freed[name] = allocatable.DeepCopy()
+		case result.Outcome == OutcomeReplaceable && result.Replacement != nil && result.Replacement.Cheapest != nil:
+			remaining := allocatable.DeepCopy()
+			remaining.Sub(result.Replacement.Cheapest.InstanceType.Capacity[name])
+			if remaining.Sign() > 0 {
+				freed[name] = remaining
+			}
+		}
+	}
+	return freed
+}
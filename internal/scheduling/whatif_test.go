@@ -0,0 +1,64 @@
+package scheduling
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ssoriche/kubectl-consolidation/internal/consolidation"
+	"github.com/ssoriche/kubectl-consolidation/internal/karpenter"
+)
+
+func TestWhatIf(t *testing.T) {
+	doNotDisrupt := func(p *corev1.Pod) {
+		p.Annotations = map[string]string{karpenter.AnnotationDoNotDisrupt: "true"}
+	}
+	nodes := []corev1.Node{
+		testNode("small", "z1", "2"),
+		testNode("big", "z1", "8"),
+		testNode("full", "z1", "4"),
+	}
+	pods := map[string][]corev1.Pod{
+		"small": {testPod("batch", "500m", doNotDisrupt)},
+		"big":   {testPod("cache", "1")},
+		"full":  {testPod("db", "3500m", doNotDisrupt)},
+	}
+	cluster := NewCluster(nodes, pods, nil)
+
+	states := make(map[string]*consolidation.BlockerState)
+	for i := range nodes {
+		states[nodes[i].Name] = consolidation.NewBlockerState(&nodes[i], pods[nodes[i].Name], nil, nil, consolidation.DefaultThresholds())
+	}
+	removal, err := consolidation.ParseBlockerRemoval("do-not-disrupt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := cluster.WhatIf([]string{"big", "full", "small"}, states, []consolidation.BlockerRemoval{removal})
+
+	status := make(map[string]string)
+	for _, r := range results {
+		switch {
+		case r.Unblocked():
+			status[r.Node] = "unblocked"
+		case r.ConsolidatableBefore():
+			status[r.Node] = "already"
+		default:
+			status[r.Node] = "no"
+		}
+	}
+	want := map[string]string{"small": "unblocked", "big": "already", "full": "no"}
+	for name, s := range want {
+		if status[name] != s {
+			t.Errorf("%s: %s, want %s", name, status[name], s)
+		}
+	}
+	if results[0].Node != "small" {
+		t.Errorf("first result = %s, want the unblocked node", results[0].Node)
+	}
+
+	unblocked, freed := TotalFreed(results)
+	if unblocked != 1 || freed.Cpu().String() != "2" {
+		t.Errorf("TotalFreed() = %d, cpu %s, want 1, cpu 2", unblocked, freed.Cpu().String())
+	}
+}